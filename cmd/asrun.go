@@ -0,0 +1,314 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/muesli/coral"
+	"github.com/spf13/viper"
+)
+
+// asRunCauseWindow is how long after an operator key the events mpv reports
+// are attributed to that key.
+const asRunCauseWindow = 2 * time.Second
+
+// As-run event names.
+const (
+	asRunStart  = "start"
+	asRunStop   = "stop"
+	asRunPause  = "pause"
+	asRunResume = "resume"
+	asRunSeek   = "seek"
+	asRunEnd    = "end"
+)
+
+// asRunEntry is one line of the as-run log. In and Out are media positions in
+// seconds: where a file went to air and where it left for start/stop/end,
+// the jump for a seek, and the same position for pause/resume. Key is the
+// operator key or remote action that caused the event, empty when mpv did it
+// on its own.
+type asRunEntry struct {
+	Time  time.Time `json:"time"`
+	Event string    `json:"event"`
+	File  string    `json:"file"`
+	In    float64   `json:"in"`
+	Out   float64   `json:"out"`
+	Key   string    `json:"key,omitempty"`
+}
+
+var asRunCSVHeader = []string{"time", "event", "file", "in", "out", "key"}
+
+func (e asRunEntry) csvRecord() []string {
+	return []string{
+		e.Time.Format(time.RFC3339Nano),
+		e.Event,
+		e.File,
+		strconv.FormatFloat(e.In, 'f', 3, 64),
+		strconv.FormatFloat(e.Out, 'f', 3, 64),
+		e.Key,
+	}
+}
+
+// asRunLog writes entries as JSON lines and CSV side by side, flushing each
+// entry so the record survives a crash.
+type asRunLog struct {
+	mu    sync.Mutex
+	jsonl *os.File
+	csvf  *os.File
+	csv   *csv.Writer
+}
+
+// openAsRunLog creates asrun-<timestamp>.jsonl and .csv in dir.
+func openAsRunLog(dir string, now time.Time) (*asRunLog, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create as-run directory %s: %w", dir, err)
+	}
+
+	base := filepath.Join(dir, "asrun-"+now.Format("20060102-150405"))
+
+	jsonl, err := os.OpenFile(base+".jsonl", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open as-run log: %w", err)
+	}
+
+	csvf, err := os.OpenFile(base+".csv", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		_ = jsonl.Close()
+
+		return nil, fmt.Errorf("could not open as-run log: %w", err)
+	}
+
+	l := &asRunLog{jsonl: jsonl, csvf: csvf, csv: csv.NewWriter(csvf)}
+	if info, err := csvf.Stat(); err == nil && info.Size() == 0 {
+		_ = l.csv.Write(asRunCSVHeader)
+		l.csv.Flush()
+	}
+
+	return l, nil
+}
+
+// Record appends e to both files.
+func (l *asRunLog) Record(e asRunEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("could not encode as-run entry: %w", err)
+	}
+	if _, err := l.jsonl.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("could not write as-run log: %w", err)
+	}
+
+	if err := l.csv.Write(e.csvRecord()); err != nil {
+		return fmt.Errorf("could not write as-run log: %w", err)
+	}
+	l.csv.Flush()
+
+	return l.csv.Error()
+}
+
+func (l *asRunLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.csv.Flush()
+	jsonErr := l.jsonl.Close()
+	if err := l.csvf.Close(); err != nil {
+		return err
+	}
+
+	return jsonErr
+}
+
+// asRunTracker turns the mpv events a player already observes into as-run
+// entries. It needs the path, pause and time-pos properties and the seek
+// event. A nil tracker records nothing, so callers need not check whether
+// logging is on.
+type asRunTracker struct {
+	log *asRunLog
+	now func() time.Time
+
+	file     string
+	airedAt  float64 // position the current file went to air at
+	played   bool    // playback has moved on from airedAt
+	ended    bool    // an end entry closed the current file
+	position float64
+	paused   bool
+	seeking  bool
+	seekFrom float64
+	seekKey  string
+
+	key   string
+	keyAt time.Time
+}
+
+func newAsRunTracker(log *asRunLog) *asRunTracker {
+	return &asRunTracker{log: log, now: time.Now, paused: true}
+}
+
+// Operator notes the key or remote action behind the events that follow.
+func (t *asRunTracker) Operator(key string) {
+	if t == nil {
+		return
+	}
+
+	t.key = key
+	t.keyAt = t.now()
+}
+
+func (t *asRunTracker) cause() string {
+	if t.key == "" || t.now().Sub(t.keyAt) > asRunCauseWindow {
+		return ""
+	}
+
+	return t.key
+}
+
+func (t *asRunTracker) record(event string, in, out float64, key string) {
+	if t.file == "" {
+		return
+	}
+
+	_ = t.log.Record(asRunEntry{Time: t.now(), Event: event, File: t.file, In: in, Out: out, Key: key})
+}
+
+// Observe folds one mpv event into the log.
+func (t *asRunTracker) Observe(ev mpvEvent) {
+	if t == nil {
+		return
+	}
+
+	if ev.Event == "seek" {
+		if !t.seeking {
+			t.seeking = true
+			t.seekFrom = t.position
+			t.seekKey = t.cause()
+		}
+
+		return
+	}
+
+	if ev.Event != "property-change" {
+		return
+	}
+
+	switch ev.Name {
+	case "path":
+		var path string
+		if json.Unmarshal(ev.Data, &path) != nil || path == t.file {
+			return
+		}
+
+		if !t.ended {
+			t.record(asRunStop, t.airedAt, t.position, t.cause())
+		}
+		t.file = path
+		t.position = 0
+		t.airedAt = 0
+		t.played = false
+		t.ended = false
+		t.seeking = false
+		t.record(asRunStart, 0, 0, t.cause())
+	case "pause":
+		var paused bool
+		if json.Unmarshal(ev.Data, &paused) != nil || paused == t.paused {
+			return
+		}
+
+		t.paused = paused
+		event := asRunResume
+		if paused {
+			event = asRunPause
+		}
+		t.record(event, t.position, t.position, t.cause())
+	case "time-pos":
+		var pos float64
+		if json.Unmarshal(ev.Data, &pos) != nil {
+			return
+		}
+
+		t.position = pos
+		if !t.seeking {
+			t.played = t.played || pos != t.airedAt
+
+			return
+		}
+
+		t.seeking = false
+		if !t.played {
+			// nothing has played yet, e.g. a clip's lead-in being skipped
+			t.airedAt = pos
+		}
+		t.record(asRunSeek, t.seekFrom, pos, t.seekKey)
+	case "eof-reached":
+		var eof bool
+		if json.Unmarshal(ev.Data, &eof) != nil || eof == t.ended {
+			return
+		}
+
+		// seeking back from the end puts the file on air again
+		t.ended = eof
+		if eof {
+			t.record(asRunEnd, t.airedAt, t.position, "")
+		}
+	}
+}
+
+// Close records the file on air as stopped and closes the log.
+func (t *asRunTracker) Close() error {
+	if t == nil {
+		return nil
+	}
+
+	if !t.ended {
+		t.record(asRunStop, t.airedAt, t.position, t.cause())
+	}
+
+	return t.log.Close()
+}
+
+// openAsRunTracker starts an as-run log when a directory is configured with
+// --asrun-dir or the asrun.dir config key, and returns nil otherwise.
+func openAsRunTracker(cmd *coral.Command) (*asRunTracker, error) {
+	dir := viper.GetString("asrun.dir")
+	if f := cmd.Flags().Lookup("asrun-dir"); f != nil && f.Changed {
+		dir = f.Value.String()
+	}
+
+	if dir == "" {
+		return nil, nil
+	}
+
+	log, err := openAsRunLog(resolveInputPath(dir), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return newAsRunTracker(log), nil
+}
+
+// addAsRunFlag adds --asrun-dir to a playback command.
+func addAsRunFlag(cmd *coral.Command) {
+	cmd.Flags().String("asrun-dir", "", "Directory for the as-run log (JSON lines and CSV); overrides asrun.dir")
+}
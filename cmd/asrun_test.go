@@ -0,0 +1,212 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testAsRun returns a tracker with a controllable clock, logging into a
+// temporary directory, and the base path of its files.
+func testAsRun(t *testing.T) (*asRunTracker, *time.Time, string) {
+	t.Helper()
+
+	start := time.Date(2026, 10, 18, 19, 30, 0, 0, time.UTC)
+	dir := t.TempDir()
+	log, err := openAsRunLog(dir, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := start
+	tracker := newAsRunTracker(log)
+	tracker.now = func() time.Time { return clock }
+
+	return tracker, &clock, filepath.Join(dir, "asrun-20261018-193000")
+}
+
+func propertyEvent(name string, value interface{}) mpvEvent {
+	data, _ := json.Marshal(value)
+
+	return mpvEvent{Event: "property-change", Name: name, Data: data}
+}
+
+func readAsRun(t *testing.T, base string) []asRunEntry {
+	t.Helper()
+
+	f, err := os.Open(base + ".jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []asRunEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e asRunEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("bad as-run line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+func summarize(entries []asRunEntry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, fmt.Sprintf("%s %s %g-%g %s", e.Event, filepath.Base(e.File), e.In, e.Out, e.Key))
+	}
+
+	return out
+}
+
+func TestAsRunTracker(t *testing.T) {
+	tracker, clock, base := testAsRun(t)
+
+	tracker.Observe(propertyEvent("pause", true))
+	tracker.Observe(propertyEvent("path", "/shows/a.mp4"))
+	tracker.Operator(" ")
+	tracker.Observe(propertyEvent("pause", false))
+	tracker.Observe(propertyEvent("time-pos", 12.5))
+
+	*clock = clock.Add(time.Minute)
+	tracker.Observe(mpvEvent{Event: "seek"})
+	tracker.Observe(propertyEvent("time-pos", 30.0))
+
+	tracker.Operator("n")
+	tracker.Observe(propertyEvent("path", "/shows/b.mp4"))
+	tracker.Observe(propertyEvent("time-pos", 4.0))
+	if err := tracker.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"start a.mp4 0-0 ",
+		"resume a.mp4 0-0  ",
+		"seek a.mp4 12.5-30 ",
+		"stop a.mp4 0-30 n",
+		"start b.mp4 0-0 n",
+		"stop b.mp4 0-4 n",
+	}
+	got := summarize(readAsRun(t, base))
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("entries:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestAsRunTracker_LeadInSetsAiredAt(t *testing.T) {
+	tracker, _, base := testAsRun(t)
+
+	tracker.Observe(propertyEvent("path", "/shows/cut.mp4"))
+	tracker.Observe(mpvEvent{Event: "seek"})
+	tracker.Observe(propertyEvent("time-pos", 0.5))
+	tracker.Observe(propertyEvent("time-pos", 9.5))
+	tracker.Observe(propertyEvent("eof-reached", true))
+	_ = tracker.Close()
+
+	entries := readAsRun(t, base)
+	end := entries[2]
+	if end.Event != asRunEnd || end.In != 0.5 || end.Out != 9.5 {
+		t.Errorf("end entry = %+v, want in 0.5 out 9.5", end)
+	}
+}
+
+func TestAsRunTracker_EndClosesFile(t *testing.T) {
+	tracker, _, base := testAsRun(t)
+
+	tracker.Observe(propertyEvent("path", "/shows/a.mp4"))
+	tracker.Observe(propertyEvent("time-pos", 10.0))
+	tracker.Observe(propertyEvent("eof-reached", true))
+	tracker.Observe(propertyEvent("eof-reached", true))
+	tracker.Observe(propertyEvent("path", "/shows/b.mp4"))
+	tracker.Observe(propertyEvent("time-pos", 3.0))
+	tracker.Observe(propertyEvent("eof-reached", true))
+	// seeking back from the end puts b back on air
+	tracker.Observe(propertyEvent("eof-reached", false))
+	tracker.Observe(propertyEvent("time-pos", 1.0))
+	_ = tracker.Close()
+
+	want := []string{
+		"start a.mp4 0-0 ",
+		"end a.mp4 0-10 ",
+		"start b.mp4 0-0 ",
+		"end b.mp4 0-3 ",
+		"stop b.mp4 0-1 ",
+	}
+	got := summarize(readAsRun(t, base))
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("entries:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestAsRunLog_CSV(t *testing.T) {
+	tracker, _, base := testAsRun(t)
+
+	tracker.Operator("space")
+	tracker.Observe(propertyEvent("path", "/shows/a, the \"first\".mp4"))
+	_ = tracker.Close()
+
+	f, err := os.Open(base + ".csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if len(records) != 3 || fmt.Sprint(records[0]) != fmt.Sprint(asRunCSVHeader) {
+		t.Fatalf("records = %q", records)
+	}
+	if records[1][2] != "/shows/a, the \"first\".mp4" || records[1][5] != "space" {
+		t.Errorf("start record = %q", records[1])
+	}
+	if records[1][0] != "2026-10-18T19:30:00Z" {
+		t.Errorf("time = %q", records[1][0])
+	}
+}
+
+func TestAsRunTracker_CauseExpires(t *testing.T) {
+	tracker, clock, base := testAsRun(t)
+
+	tracker.Observe(propertyEvent("path", "/shows/a.mp4"))
+	tracker.Operator(" ")
+	*clock = clock.Add(asRunCauseWindow + time.Second)
+	tracker.Observe(propertyEvent("pause", false))
+	_ = tracker.Close()
+
+	if entries := readAsRun(t, base); entries[1].Key != "" {
+		t.Errorf("late event should not be attributed to the key, got %+v", entries[1])
+	}
+}
+
+func TestAsRunTracker_Nil(t *testing.T) {
+	var tracker *asRunTracker
+	tracker.Operator("q")
+	tracker.Observe(propertyEvent("path", "/shows/a.mp4"))
+	if err := tracker.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kindlyops/vbs/migrations"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/rs/zerolog/log"
+)
+
+// pressesPath lists the audit log, as JSON or with ?format=csv.
+const pressesPath = "/api/presses"
+
+const (
+	pressesDefaultLimit = 100
+	pressesMaxLimit     = 10000
+)
+
+// Results of a button press.
+const (
+	pressSent    = "sent"
+	pressFailed  = "failed"
+	pressUnknown = "unknown"
+	pressInvalid = "invalid"
+)
+
+// buttonPress is one press of a lighting or switcher button.
+type buttonPress struct {
+	Time    time.Time `json:"time"`
+	Button  string    `json:"button"`
+	User    string    `json:"user"`
+	Device  string    `json:"device"`
+	Address string    `json:"address"`
+	Route   string    `json:"route"`
+	OSC     string    `json:"osc"`
+	Result  string    `json:"result"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// pressAudit, when set, is given every button press. vbs serve keeps them
+// in PocketBase; the standalone bridge only logs them.
+var pressAudit func(buttonPress)
+
+// auditPress reports a press of button from r.
+func auditPress(r *http.Request, button, address, result string, err error) {
+	newButtonPress(r, button, address).record(result, err)
+}
+
+// newButtonPress describes a press of button from r, for recording once its
+// result is known.
+func newButtonPress(r *http.Request, button, address string) buttonPress {
+	remote, _, splitErr := net.SplitHostPort(r.RemoteAddr)
+	if splitErr != nil {
+		remote = r.RemoteAddr
+	}
+
+	return buttonPress{
+		Time:    time.Now().UTC(),
+		Button:  button,
+		User:    lightingUserFrom(r),
+		Device:  r.UserAgent(),
+		Address: remote,
+		Route:   r.URL.Path,
+		OSC:     address,
+	}
+}
+
+// record logs the press with its result and hands it to pressAudit.
+func (p buttonPress) record(result string, err error) {
+	p.Result = result
+	if err != nil {
+		p.Error = err.Error()
+	}
+
+	log.Info().Str("user", p.User).Str("button", p.Button).Str("result", result).Msg("Button pressed")
+
+	if pressAudit != nil {
+		pressAudit(p)
+	}
+}
+
+// pressStore keeps the audit log in the button_presses collection.
+type pressStore struct {
+	dao func() *daos.Dao
+}
+
+// Record saves a press, logging rather than failing the press on error.
+func (s *pressStore) Record(p buttonPress) {
+	collection, err := s.dao().FindCollectionByNameOrId(migrations.ButtonPresses)
+	if err != nil {
+		log.Error().Err(err).Msg("Could not find the button press log")
+
+		return
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("button", p.Button)
+	record.Set("user", p.User)
+	record.Set("device", p.Device)
+	record.Set("address", p.Address)
+	record.Set("route", p.Route)
+	record.Set("osc", p.OSC)
+	record.Set("result", p.Result)
+	record.Set("error", p.Error)
+
+	if err := s.dao().SaveRecord(record); err != nil {
+		log.Error().Err(err).Msgf("Could not log a press of %s", p.Button)
+	}
+}
+
+// pressFilter narrows Find; empty fields match everything.
+type pressFilter struct {
+	Button string
+	User   string
+	Result string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// Find returns matching presses, newest first.
+func (s *pressStore) Find(f pressFilter) ([]buttonPress, error) {
+	collection, err := s.dao().FindCollectionByNameOrId(migrations.ButtonPresses)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the button press log: %w", err)
+	}
+
+	query := s.dao().RecordQuery(collection).OrderBy("created DESC").Limit(int64(f.Limit))
+	for field, value := range map[string]string{"button": f.Button, "user": f.User, "result": f.Result} {
+		if value != "" {
+			query = query.AndWhere(dbx.HashExp{field: value})
+		}
+	}
+	if !f.Since.IsZero() {
+		since, _ := types.ParseDateTime(f.Since)
+		query = query.AndWhere(dbx.NewExp("created >= {:since}", dbx.Params{"since": since.String()}))
+	}
+	if !f.Until.IsZero() {
+		until, _ := types.ParseDateTime(f.Until)
+		query = query.AndWhere(dbx.NewExp("created < {:until}", dbx.Params{"until": until.String()}))
+	}
+
+	var rows []dbx.NullStringMap
+	if err := query.All(&rows); err != nil {
+		return nil, fmt.Errorf("could not read the button press log: %w", err)
+	}
+
+	records := models.NewRecordsFromNullStringMaps(collection, rows)
+	presses := make([]buttonPress, 0, len(records))
+	for _, r := range records {
+		presses = append(presses, buttonPress{
+			Time:    r.Created.Time().UTC(),
+			Button:  r.GetString("button"),
+			User:    r.GetString("user"),
+			Device:  r.GetString("device"),
+			Address: r.GetString("address"),
+			Route:   r.GetString("route"),
+			OSC:     r.GetString("osc"),
+			Result:  r.GetString("result"),
+			Error:   r.GetString("error"),
+		})
+	}
+
+	return presses, nil
+}
+
+// parsePressFilter reads button, user, result, since, until and limit from
+// the query string. Times are RFC 3339.
+func parsePressFilter(r *http.Request) (pressFilter, error) {
+	q := r.URL.Query()
+	f := pressFilter{Button: q.Get("button"), User: q.Get("user"), Result: q.Get("result"), Limit: pressesDefaultLimit}
+
+	for key, dst := range map[string]*time.Time{"since": &f.Since, "until": &f.Until} {
+		if v := q.Get(key); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return f, fmt.Errorf("%s must be an RFC 3339 time like 2026-10-18T19:00:00Z", key)
+			}
+			*dst = t
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > pressesMaxLimit {
+			return f, fmt.Errorf("limit must be 1 to %d", pressesMaxLimit)
+		}
+		f.Limit = n
+	}
+
+	return f, nil
+}
+
+// ServeHTTP lists the audit log as {"presses": [...]}, or as a CSV download
+// with ?format=csv.
+func (s *pressStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f, err := parsePressFilter(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+
+		return
+	}
+
+	presses, err := s.Find(f)
+	if err != nil {
+		log.Error().Err(err).Msg("Could not list button presses")
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "could not read the button press log"})
+
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, map[string]interface{}{"presses": presses})
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="button_presses.csv"`)
+		writePressesCSV(w, presses)
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("unknown format %q, use json or csv", format)})
+	}
+}
+
+func writePressesCSV(w http.ResponseWriter, presses []buttonPress) {
+	out := csv.NewWriter(w)
+	_ = out.Write([]string{"time", "button", "user", "device", "address", "route", "osc", "result", "error"})
+	for _, p := range presses {
+		_ = out.Write([]string{p.Time.Format(time.RFC3339), p.Button, p.User, p.Device, p.Address, p.Route, p.OSC, p.Result, p.Error})
+	}
+	out.Flush()
+}
@@ -0,0 +1,23 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build goexperiment.jsonv2
+
+package cmd
+
+func init() {
+	// schema.SchemaField.UnmarshalJSON in PocketBase 0.16 calls itself
+	// forever when encoding/json is backed by json/v2.
+	pocketbaseSkip = "PocketBase 0.16 cannot load collections with encoding/json v2; build with GOEXPERIMENT=nojsonv2 or the go.mod Go version"
+}
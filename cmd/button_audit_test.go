@@ -0,0 +1,170 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/migrate"
+	"github.com/spf13/viper"
+)
+
+// pocketbaseSkip, when set, is why PocketBase cannot run with this Go.
+var pocketbaseSkip string
+
+// testPressStore runs the migrations, including ours, on a new app.
+func testPressStore(t *testing.T) *pressStore {
+	t.Helper()
+
+	if pocketbaseSkip != "" {
+		t.Skip(pocketbaseSkip)
+	}
+
+	app := core.NewBaseApp(&core.BaseAppConfig{DataDir: t.TempDir()})
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = app.ResetBootstrapState() })
+
+	runner, err := migrate.NewRunner(app.DB(), m.AppMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &pressStore{dao: app.Dao}
+}
+
+func TestPressStore(t *testing.T) {
+	s := testPressStore(t)
+
+	s.Record(buttonPress{Button: "ftb", User: "alice", Device: "Safari", Address: "10.0.0.2", Route: "/api/buttons/ftb", OSC: "/location/20/0/3/press", Result: pressSent})
+	s.Record(buttonPress{Button: "green", User: "bob", Result: pressFailed, Error: "connection refused"})
+	s.Record(buttonPress{Button: "purple", User: "bob", Result: pressUnknown})
+
+	all, err := s.Find(pressFilter{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("found %d presses, want 3", len(all))
+	}
+
+	bobs, err := s.Find(pressFilter{User: "bob", Result: pressFailed, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bobs) != 1 || bobs[0].Button != "green" || bobs[0].Error != "connection refused" {
+		t.Errorf("bob's failed presses = %+v", bobs)
+	}
+
+	ftb, err := s.Find(pressFilter{Button: "ftb", Since: time.Now().Add(-time.Minute), Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ftb) != 1 || ftb[0].User != "alice" || ftb[0].OSC != "/location/20/0/3/press" || time.Since(ftb[0].Time) > time.Minute {
+		t.Errorf("who faded to black = %+v", ftb)
+	}
+
+	future, err := s.Find(pressFilter{Since: time.Now().Add(time.Hour), Limit: 10})
+	if err != nil || len(future) != 0 {
+		t.Errorf("presses after now = %+v, %v", future, err)
+	}
+
+	limited, err := s.Find(pressFilter{Limit: 2})
+	if err != nil || len(limited) != 2 {
+		t.Errorf("limit 2 found %d, %v", len(limited), err)
+	}
+}
+
+func TestPressStore_ServeHTTP(t *testing.T) {
+	s := testPressStore(t)
+	s.Record(buttonPress{Button: "ftb", User: "alice", Result: pressSent})
+	s.Record(buttonPress{Button: "dsk", User: "bob", Device: "Firefox, on a phone", Result: pressSent})
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, pressesPath+"?user=alice", nil))
+
+	var resp struct {
+		Presses []buttonPress `json:"presses"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || len(resp.Presses) != 1 || resp.Presses[0].Button != "ftb" {
+		t.Errorf("status %d, presses %+v", w.Code, resp.Presses)
+	}
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, pressesPath+"?format=csv", nil))
+	if w.Header().Get("Content-Type") != "text/csv" || !strings.Contains(w.Header().Get("Content-Disposition"), "attachment") {
+		t.Errorf("headers = %v", w.Header())
+	}
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0][1] != "button" || rows[1][1] != "dsk" || rows[1][3] != "Firefox, on a phone" {
+		t.Errorf("csv = %q", rows)
+	}
+
+	for _, query := range []string{"?since=yesterday", "?limit=0", "?limit=many", "?format=xml"} {
+		w = httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, pressesPath+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s answered %d", query, w.Code)
+		}
+	}
+}
+
+func TestHandleOSC_Audit(t *testing.T) {
+	var presses []buttonPress
+	pressAudit = func(p buttonPress) { presses = append(presses, p) }
+	t.Cleanup(func() { pressAudit = nil })
+
+	viper.Set("companion", "127.0.0.1")
+	viper.Set("companion_buttons", map[string]string{"ftb": "20/0/3", "broken": "20/0"})
+	t.Cleanup(func() { viper.Set("companion_buttons", nil) })
+	buttons := viper.GetStringMapString("companion_buttons")
+
+	for _, name := range []string{"ftb", "broken", "purple"} {
+		req := httptest.NewRequest(http.MethodPost, buttonsPath+"/"+name, nil)
+		req.Header.Set("User-Agent", "Safari")
+		handleOSC(httptest.NewRecorder(), withLightingUser(req, "alice"), buttonsPath+"/", buttons)
+	}
+
+	if len(presses) != 3 {
+		t.Fatalf("audited %d presses, want 3", len(presses))
+	}
+	if p := presses[0]; p.Button != "ftb" || p.User != "alice" || p.Device != "Safari" || p.OSC != "/location/20/0/3/press" || p.Result != pressSent {
+		t.Errorf("ftb = %+v", p)
+	}
+	if p := presses[1]; p.Result != pressInvalid || p.Error == "" {
+		t.Errorf("broken = %+v", p)
+	}
+	if p := presses[2]; p.Result != pressUnknown || p.Route != "/api/buttons/purple" {
+		t.Errorf("purple = %+v", p)
+	}
+}
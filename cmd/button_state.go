@@ -0,0 +1,312 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+const (
+	// buttonLivePath is the WebSocket that pushes button state to pages.
+	buttonLivePath = buttonsPath + "/live"
+	// Companion triggers send button feedback and variables here, naming
+	// the button or variable in the first argument.
+	buttonFeedbackAddress   = "/vbs/button"
+	variableFeedbackAddress = "/vbs/variable"
+)
+
+// buttonState is what the page shows on a button. Active is unknown until
+// Companion sends feedback for the button.
+type buttonState struct {
+	Active    *bool      `json:"active,omitempty"`
+	PressedBy string     `json:"pressed_by,omitempty"`
+	PressedAt *time.Time `json:"pressed_at,omitempty"`
+	// feedback counts Companion feedback for the button, changed or not
+	feedback uint64
+}
+
+// buttonStateMessage is sent to pages, first with every button, variable
+// and macro and then with each change.
+type buttonStateMessage struct {
+	Buttons   map[string]buttonState   `json:"buttons,omitempty"`
+	Variables map[string]string        `json:"variables,omitempty"`
+	Macros    map[string]macroProgress `json:"macros,omitempty"`
+}
+
+// buttonStates tracks the buttons for every connected page.
+type buttonStates struct {
+	mu        sync.Mutex
+	buttons   map[string]buttonState
+	variables map[string]string
+	macros    map[string]macroProgress
+	// changed is closed and replaced whenever feedback arrives for a button
+	changed chan struct{}
+	hub     *wsHub
+	now     func() time.Time
+}
+
+func newButtonStates() *buttonStates {
+	s := &buttonStates{
+		buttons:   map[string]buttonState{},
+		variables: map[string]string{},
+		macros:    map[string]macroProgress{},
+		changed:   make(chan struct{}),
+		hub:       newWSHub(),
+		now:       time.Now,
+	}
+	s.hub.welcome = s.snapshot
+
+	return s
+}
+
+// liveButtons is shared by the lighting bridge and vbs serve handlers, as
+// both drive the same Companion.
+var liveButtons = newButtonStates()
+
+// Pressed records who last pressed a button.
+func (s *buttonStates) Pressed(name, user string) {
+	s.mu.Lock()
+	state := s.buttons[name]
+	at := s.now().UTC()
+	state.PressedAt = &at
+	state.PressedBy = user
+	s.buttons[name] = state
+	s.mu.Unlock()
+
+	s.broadcast(buttonStateMessage{Buttons: map[string]buttonState{name: state}})
+}
+
+// SetActive records Companion feedback for a button.
+func (s *buttonStates) SetActive(name string, active bool) {
+	s.mu.Lock()
+	state := s.buttons[name]
+	state.feedback++
+	unchanged := state.Active != nil && *state.Active == active
+	state.Active = &active
+	s.buttons[name] = state
+	close(s.changed)
+	s.changed = make(chan struct{})
+	s.mu.Unlock()
+
+	if unchanged {
+		return
+	}
+
+	s.broadcast(buttonStateMessage{Buttons: map[string]buttonState{name: state}})
+}
+
+// SetVariable records a Companion variable.
+func (s *buttonStates) SetVariable(name, value string) {
+	s.mu.Lock()
+	if old, ok := s.variables[name]; ok && old == value {
+		s.mu.Unlock()
+
+		return
+	}
+	s.variables[name] = value
+	s.mu.Unlock()
+
+	s.broadcast(buttonStateMessage{Variables: map[string]string{name: value}})
+}
+
+// Feedback returns a mark for the feedback seen so far for a button, to
+// pass to WaitActive.
+func (s *buttonStates) Feedback(name string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.buttons[name].feedback
+}
+
+// WaitActive blocks until Companion sends feedback after the mark from
+// Feedback reporting the button on, or off, or ctx is done.
+func (s *buttonStates) WaitActive(ctx context.Context, name string, active bool, since uint64) error {
+	for {
+		s.mu.Lock()
+		state, changed := s.buttons[name], s.changed
+		s.mu.Unlock()
+
+		if state.feedback > since && state.Active != nil && *state.Active == active {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-changed:
+		}
+	}
+}
+
+// SetMacro records a macro's progress.
+func (s *buttonStates) SetMacro(name string, progress macroProgress) {
+	s.mu.Lock()
+	s.macros[name] = progress
+	s.mu.Unlock()
+
+	s.broadcast(buttonStateMessage{Macros: map[string]macroProgress{name: progress}})
+}
+
+// State returns every button, variable and macro.
+func (s *buttonStates) State() buttonStateMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := buttonStateMessage{Buttons: map[string]buttonState{}, Variables: map[string]string{}, Macros: map[string]macroProgress{}}
+	for name, state := range s.buttons {
+		msg.Buttons[name] = state
+	}
+	for name, value := range s.variables {
+		msg.Variables[name] = value
+	}
+	for name, progress := range s.macros {
+		msg.Macros[name] = progress
+	}
+
+	return msg
+}
+
+func (s *buttonStates) snapshot() []byte {
+	data, err := json.Marshal(s.State())
+	if err != nil {
+		log.Error().Err(err).Msg("Could not encode button state")
+	}
+
+	return data
+}
+
+func (s *buttonStates) broadcast(msg buttonStateMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Error().Err(err).Msg("Could not encode button state")
+
+		return
+	}
+
+	s.hub.Broadcast(data)
+}
+
+// ServeHTTP connects a page to the live state; it is sent every button and
+// variable, then each change as it happens.
+func (s *buttonStates) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.hub.ServeHTTP(w, r)
+}
+
+// feedbackActive reads a Companion feedback argument; no argument means on.
+func feedbackActive(msg *osc.Message) bool {
+	if len(msg.Arguments) < 2 { //nolint:gomnd // name, then value
+		return true
+	}
+
+	switch v := msg.Arguments[1].(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "", "0", "false", "off", "no":
+			return false
+		}
+
+		return true
+	default:
+		f, ok := oscFloatArg(msg, 1)
+
+		return ok && f != 0
+	}
+}
+
+// oscArgString formats an OSC argument as a variable value.
+func oscArgString(msg *osc.Message, i int) string {
+	if i >= len(msg.Arguments) {
+		return ""
+	}
+
+	return fmt.Sprint(msg.Arguments[i])
+}
+
+// HandleFeedback registers the Companion feedback addresses on server:
+//
+//	/vbs/button <name> [on]     a button is on, or off for 0, false or "off"
+//	/vbs/variable <name> <value>
+func (s *buttonStates) HandleFeedback(server *oscServer) {
+	server.Handle(buttonFeedbackAddress, func(msg *osc.Message, _ oscReplyFunc) {
+		name, ok := oscStringArg(msg, 0)
+		if !ok || name == "" {
+			log.Debug().Msgf("%s needs a button name, got %v", buttonFeedbackAddress, msg.Arguments)
+
+			return
+		}
+
+		s.SetActive(strings.ToLower(name), feedbackActive(msg))
+	})
+
+	server.Handle(variableFeedbackAddress, func(msg *osc.Message, _ oscReplyFunc) {
+		name, ok := oscStringArg(msg, 0)
+		if !ok || name == "" {
+			log.Debug().Msgf("%s needs a variable name, got %v", variableFeedbackAddress, msg.Arguments)
+
+			return
+		}
+
+		s.SetVariable(name, oscArgString(msg, 1))
+	})
+}
+
+// listenButtonFeedback starts receiving Companion feedback on the
+// companion_feedback_port config key, if it is set.
+func listenButtonFeedback() (*oscServer, error) {
+	port := viper.GetInt("companion_feedback_port")
+	if port == 0 {
+		return nil, nil
+	}
+
+	server := newOSCServer(fmt.Sprintf(":%d", port))
+	liveButtons.HandleFeedback(server)
+	if err := server.Listen(); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if err := server.Serve(); err != nil {
+			log.Debug().Err(err).Msg("Companion feedback listener stopped")
+		}
+	}()
+	log.Info().Msgf("Listening for Companion feedback on %s", server.LocalAddr())
+
+	return server, nil
+}
+
+type lightingUserKey struct{}
+
+// withLightingUser records who a request came from, for Pressed.
+func withLightingUser(r *http.Request, name string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), lightingUserKey{}, name))
+}
+
+func lightingUserFrom(r *http.Request) string {
+	name, _ := r.Context().Value(lightingUserKey{}).(string)
+
+	return name
+}
@@ -0,0 +1,133 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/spf13/viper"
+)
+
+func readButtonState(t *testing.T, conn net.Conn, r *bufio.Reader) buttonStateMessage {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	op, payload, err := readWSFrame(r)
+	if err != nil || op != wsOpText {
+		t.Fatalf("frame %x: %v", op, err)
+	}
+
+	var msg buttonStateMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatal(err)
+	}
+
+	return msg
+}
+
+func TestButtonStates_Live(t *testing.T) {
+	s := newButtonStates()
+	at := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return at }
+	s.SetVariable("program", "Camera 1")
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	conn, r := dialWS(t, strings.TrimPrefix(server.URL, "http://"), buttonLivePath)
+
+	first := readButtonState(t, conn, r)
+	if first.Variables["program"] != "Camera 1" || len(first.Buttons) != 0 {
+		t.Errorf("a new page should get the current state, got %+v", first)
+	}
+
+	s.Pressed("keylighton", "alice")
+	pressed := readButtonState(t, conn, r)
+	if b := pressed.Buttons["keylighton"]; b.PressedBy != "alice" || b.PressedAt == nil || !b.PressedAt.Equal(at) || b.Active != nil {
+		t.Errorf("press = %+v", b)
+	}
+
+	s.SetActive("keylighton", true)
+	s.SetActive("keylighton", true)
+	s.SetActive("keylighton", false)
+	if b := readButtonState(t, conn, r).Buttons["keylighton"]; b.Active == nil || !*b.Active || b.PressedBy != "alice" {
+		t.Errorf("feedback should keep who pressed, got %+v", b)
+	}
+	if b := readButtonState(t, conn, r).Buttons["keylighton"]; b.Active == nil || *b.Active {
+		t.Errorf("repeated feedback should not be sent again, got %+v", b)
+	}
+}
+
+func TestButtonStates_Feedback(t *testing.T) {
+	s := newButtonStates()
+	server := newOSCServer("127.0.0.1:0")
+	s.HandleFeedback(server)
+	startOSCServer(t, server)
+
+	conn := oscConn(t, server.LocalAddr().(*net.UDPAddr))
+	for _, msg := range []*osc.Message{
+		osc.NewMessage(buttonFeedbackAddress, "KeyLightOn"),
+		osc.NewMessage(buttonFeedbackAddress, "green", int32(0)),
+		osc.NewMessage(buttonFeedbackAddress, "blue", "off"),
+		osc.NewMessage(buttonFeedbackAddress, "red", float32(1)),
+		osc.NewMessage(buttonFeedbackAddress, "dsk", true),
+		osc.NewMessage(buttonFeedbackAddress),
+		osc.NewMessage(variableFeedbackAddress, "program", "Camera 2"),
+		osc.NewMessage(variableFeedbackAddress, "tally", int32(3)),
+	} {
+		sendOSCMessage(t, conn, msg)
+	}
+
+	want := map[string]bool{"keylighton": true, "green": false, "blue": false, "red": true, "dsk": true}
+	deadline := time.Now().Add(2 * time.Second)
+	var state buttonStateMessage
+	for time.Now().Before(deadline) {
+		state = s.State()
+		if len(state.Buttons) == len(want) && len(state.Variables) == 2 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	for name, active := range want {
+		if b := state.Buttons[name]; b.Active == nil || *b.Active != active {
+			t.Errorf("%s = %+v, want active %v", name, b, active)
+		}
+	}
+	if state.Variables["program"] != "Camera 2" || state.Variables["tally"] != "3" {
+		t.Errorf("variables = %v", state.Variables)
+	}
+}
+
+func TestHandleOSC_RecordsPress(t *testing.T) {
+	viper.Set("companion", "127.0.0.1")
+	viper.Set("companion_buttons", map[string]string{"green": "20/1/1"})
+	t.Cleanup(func() { viper.Set("companion_buttons", nil) })
+
+	req := withLightingUser(httptest.NewRequest(http.MethodPost, "/api/buttons/green", nil), "bob")
+	handleOSC(httptest.NewRecorder(), req, buttonsPath+"/", viper.GetStringMapString("companion_buttons"))
+
+	if b := liveButtons.State().Buttons["green"]; b.PressedBy != "bob" || b.PressedAt == nil {
+		t.Errorf("green = %+v", b)
+	}
+}
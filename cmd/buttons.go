@@ -0,0 +1,245 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// buttonsPath serves the layout, and buttonsPath/<name> presses a button.
+const buttonsPath = "/api/buttons"
+
+// otherButtonsGroup holds buttons in companion_buttons that the layout
+// does not place, so a new button shows up without editing the layout.
+const otherButtonsGroup = "Other"
+
+// cssColor accepts hex, rgb(), hsl() and named CSS colors.
+var cssColor = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|[a-zA-Z]+|(rgb|rgba|hsl|hsla)\([0-9., %/]+\))$`)
+
+// layoutButton is a button on the web page.
+type layoutButton struct {
+	Button      string `mapstructure:"button" json:"button"`
+	Label       string `mapstructure:"label" json:"label"`
+	Description string `mapstructure:"description" json:"description,omitempty"`
+	Color       string `mapstructure:"color" json:"color,omitempty"`
+	// Variable names a Companion variable to show on the button.
+	Variable string `mapstructure:"variable" json:"variable,omitempty"`
+	Action   string `mapstructure:"-" json:"action"`
+}
+
+// buttonGroup is a titled row of buttons on the web page.
+type buttonGroup struct {
+	Name    string         `mapstructure:"name" json:"name"`
+	Buttons []layoutButton `mapstructure:"buttons" json:"buttons"`
+}
+
+// buttonLayout checks groups against the companion_buttons names and fills
+// in labels and actions. Buttons not in any group are added, by name, to a
+// last group. Unless strict, buttons missing from companion_buttons are
+// skipped, so the default layout still works after buttons are removed.
+func buttonLayout(groups []buttonGroup, specs map[string]string, strict bool) ([]buttonGroup, error) {
+	var errs []error
+	placed := map[string]bool{}
+
+	layout := make([]buttonGroup, 0, len(groups)+1)
+	for i, g := range groups {
+		if g.Name == "" {
+			errs = append(errs, fmt.Errorf("companion_layout group %d has no name", i+1))
+		}
+
+		group := buttonGroup{Name: g.Name, Buttons: make([]layoutButton, 0, len(g.Buttons))}
+		for _, b := range g.Buttons {
+			// viper lowercases companion_buttons names
+			b.Button = strings.ToLower(b.Button)
+
+			switch {
+			case b.Button == "":
+				errs = append(errs, fmt.Errorf("companion_layout group %q has a button with no button name", g.Name))
+
+				continue
+			case specs[b.Button] == "" && !strict:
+				continue
+			case specs[b.Button] == "":
+				errs = append(errs, fmt.Errorf("companion_layout button %q is not in companion_buttons or companion_macros", b.Button))
+
+				continue
+			case placed[b.Button]:
+				errs = append(errs, fmt.Errorf("companion_layout button %q is listed twice", b.Button))
+
+				continue
+			case b.Color != "" && !cssColor.MatchString(b.Color):
+				errs = append(errs, fmt.Errorf("companion_layout button %q has color %q, want #hex, rgb(), hsl() or a name", b.Button, b.Color))
+			}
+			placed[b.Button] = true
+
+			group.Buttons = append(group.Buttons, b.withDefaults())
+		}
+		if len(group.Buttons) > 0 {
+			layout = append(layout, group)
+		}
+	}
+
+	var rest []string
+	for name := range specs {
+		if !placed[name] {
+			rest = append(rest, name)
+		}
+	}
+	if len(rest) > 0 {
+		sort.Strings(rest)
+
+		other := buttonGroup{Name: otherButtonsGroup}
+		for _, name := range rest {
+			other.Buttons = append(other.Buttons, layoutButton{Button: name}.withDefaults())
+		}
+		layout = append(layout, other)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return layout, nil
+}
+
+func (b layoutButton) withDefaults() layoutButton {
+	if b.Label == "" {
+		b.Label = b.Button
+	}
+	b.Action = buttonsPath + "/" + b.Button
+
+	return b
+}
+
+// loadButtonLayout reads the companion_layout config key, strictly when it
+// comes from the config file.
+func loadButtonLayout() ([]buttonGroup, error) {
+	var groups []buttonGroup
+	if err := viper.UnmarshalKey("companion_layout", &groups); err != nil {
+		return nil, fmt.Errorf("could not read companion_layout: %w", err)
+	}
+
+	// macros are pressed like buttons; copy, as viper returns its own map
+	names := map[string]string{}
+	for name, spec := range viper.GetStringMapString("companion_buttons") {
+		names[name] = spec
+	}
+	for name := range viper.GetStringMap("companion_macros") {
+		names[name] = "macro"
+	}
+	if viper.GetString("dmx_protocol") != "" {
+		for name := range viper.GetStringMap("dmx_scenes") {
+			names[name] = "scene"
+		}
+	}
+
+	return buttonLayout(groups, names, viper.InConfig("companion_layout"))
+}
+
+// checkButtonConfig validates companion_buttons, companion_macros,
+// dmx_scenes and companion_layout, for servers to call as they start.
+func checkButtonConfig() error {
+	buttons, buttonsErr := loadCompanionButtons()
+	_, layoutErr := loadButtonLayout()
+
+	// macros refer to buttons, so only check them against valid buttons
+	var macros map[string][]macroAction
+	var macrosErr error
+	if buttonsErr == nil {
+		macros, macrosErr = loadCompanionMacros(buttons)
+	}
+
+	// scenes are pressed like buttons too, so names must not clash
+	scenes, scenesErr := loadDMXScenes()
+	names := make([]string, 0, len(scenes))
+	for name := range scenes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var clashes []error
+	for _, name := range names {
+		_, button := buttons[name]
+		_, macro := macros[name]
+		if button || macro {
+			clashes = append(clashes, fmt.Errorf("dmx_scenes.%s: a button or macro has the same name", name))
+		}
+	}
+
+	return errors.Join(buttonsErr, macrosErr, scenesErr, errors.Join(clashes...), layoutErr)
+}
+
+// Buttons serves the button layout, and presses a button on POST.
+type Buttons struct{}
+
+func (b *Buttons) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		handleOSC(w, r, buttonsPath+"/", viper.GetStringMapString("companion_buttons"))
+
+		return
+	}
+
+	layout, err := loadButtonLayout()
+	if err != nil {
+		log.Error().Err(err).Msg("Could not build button layout")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"groups": layout})
+}
+
+func init() {
+	// Written to the config file by save-config, alongside companion_buttons.
+	viper.SetDefault("companion_layout", []map[string]interface{}{
+		{
+			"name": "Lights",
+			"buttons": []map[string]interface{}{
+				{"button": "green", "label": "On 💡", "description": "Green light.", "color": "hsl(130, 100%, 50%)"},
+				{"button": "blue", "label": "On 💡", "description": "Blue light.", "color": "hsl(210, 100%, 50%)"},
+				{"button": "yellow", "label": "On 💡", "description": "Yellow light.", "color": "hsl(60, 100%, 50%)"},
+				{"button": "red", "label": "On 💡", "description": "Red light.", "color": "hsl(10, 100%, 50%)"},
+				{"button": "off", "label": "Off 🔌", "description": "Light off."},
+			},
+		},
+		{
+			"name": "Switcher",
+			"buttons": []map[string]interface{}{
+				{"button": "ftb", "label": "FTB ❤", "description": "Fade to black."},
+				{"button": "dsk", "label": "DSK 🦆", "description": "Toggle DSK."},
+			},
+		},
+		{
+			"name": "Key light",
+			"buttons": []map[string]interface{}{
+				{"button": "keylighton", "label": "On 💡", "description": "Key light on."},
+				{"button": "keylightoff", "label": "Off 🔌", "description": "Key light off."},
+			},
+		},
+	})
+}
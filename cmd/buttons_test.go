@@ -0,0 +1,148 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestButtonLayout(t *testing.T) {
+	specs := map[string]string{
+		"green": "20/1/1",
+		"ftb":   "20/0/3",
+		"zoom":  "20/3/0",
+		"brb":   "20/3/1",
+	}
+	groups := []buttonGroup{
+		{Name: "Switcher", Buttons: []layoutButton{
+			{Button: "FTB", Label: "FTB", Description: "Fade to black."},
+		}},
+		{Name: "Lights", Buttons: []layoutButton{
+			{Button: "green", Label: "On", Color: "hsl(130, 100%, 50%)"},
+			{Button: "gone"},
+		}},
+	}
+
+	got, err := buttonLayout(groups, specs, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []buttonGroup{
+		{Name: "Switcher", Buttons: []layoutButton{
+			{Button: "ftb", Label: "FTB", Description: "Fade to black.", Action: "/api/buttons/ftb"},
+		}},
+		{Name: "Lights", Buttons: []layoutButton{
+			{Button: "green", Label: "On", Color: "hsl(130, 100%, 50%)", Action: "/api/buttons/green"},
+		}},
+		{Name: otherButtonsGroup, Buttons: []layoutButton{
+			{Button: "brb", Label: "brb", Action: "/api/buttons/brb"},
+			{Button: "zoom", Label: "zoom", Action: "/api/buttons/zoom"},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	if _, err := buttonLayout(groups, specs, true); err == nil || !strings.Contains(err.Error(), `"gone" is not in companion_buttons`) {
+		t.Errorf("a layout from the config file should name unknown buttons, got %v", err)
+	}
+}
+
+func TestButtonLayout_Errors(t *testing.T) {
+	specs := map[string]string{"green": "20/1/1"}
+
+	cases := []struct {
+		name    string
+		groups  []buttonGroup
+		wantErr string
+	}{
+		{"no group name", []buttonGroup{{Buttons: []layoutButton{{Button: "green"}}}}, "group 1 has no name"},
+		{"no button name", []buttonGroup{{Name: "Lights", Buttons: []layoutButton{{Label: "On"}}}}, "no button name"},
+		{"twice", []buttonGroup{
+			{Name: "A", Buttons: []layoutButton{{Button: "green"}}},
+			{Name: "B", Buttons: []layoutButton{{Button: "green"}}},
+		}, "listed twice"},
+		{"bad color", []buttonGroup{{Name: "Lights", Buttons: []layoutButton{{Button: "green", Color: "red; display: none"}}}}, "has color"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := buttonLayout(tc.groups, specs, true); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	for _, color := range []string{"#0f0", "#00ff0080", "rebeccapurple", "rgb(0, 255, 0)", "hsl(130 100% 50% / 0.5)"} {
+		if !cssColor.MatchString(color) {
+			t.Errorf("color %q should be accepted", color)
+		}
+	}
+}
+
+func TestButtons_ServeHTTP(t *testing.T) {
+	viper.Set("companion", "127.0.0.1")
+	viper.Set("companion_buttons", nil)
+	viper.Set("companion_layout", nil)
+
+	if err := checkButtonConfig(); err != nil {
+		t.Fatalf("default buttons and layout should be valid: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	(&Buttons{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/buttons", nil))
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status %d, content type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	var resp struct {
+		Groups []buttonGroup `json:"groups"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, g := range resp.Groups {
+		names = append(names, g.Name)
+	}
+	if want := []string{"Lights", "Switcher", "Key light"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("groups = %v, want %v", names, want)
+	}
+	if first := resp.Groups[0].Buttons[0]; first.Button != "green" || first.Action != "/api/buttons/green" || first.Color == "" {
+		t.Errorf("first button = %+v", first)
+	}
+
+	w = httptest.NewRecorder()
+	(&Buttons{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/buttons/green", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("pressing a button answered %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	(&Buttons{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/buttons/purple", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("pressing an unknown button answered %d", w.Code)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -179,6 +180,25 @@ func getChapters(target string) (ffmprobeResponse, error) {
 	return response, nil
 }
 
+// probeDuration returns a media file's container duration in seconds.
+func probeDuration(target string) (float64, error) {
+	output, err := exec.Command("ffprobe",
+		"-loglevel", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		"-i", target).Output()
+	if err != nil {
+		return 0, fmt.Errorf("could not probe duration of %s: %w", target, err)
+	}
+
+	duration, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected ffprobe duration %q for %s: %w", output, target, err)
+	}
+
+	return duration, nil
+}
+
 func chapterList(cmd *coral.Command, args []string) {
 	_, err := exec.LookPath("ffprobe")
 
@@ -0,0 +1,179 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/spf13/viper"
+)
+
+const (
+	// companionDefaultPort is the OSC port Companion listens on out of the box.
+	companionDefaultPort = 12321
+	// companionDefaultVersion picks location based button addressing.
+	companionDefaultVersion = 3
+	// companionLegacyColumns is the width of a page in Companion 2, which
+	// numbers buttons 1-32 across 8 columns.
+	companionLegacyColumns = 8
+	companionLegacyButtons = 32
+	companionMaxPage       = 99
+)
+
+// companionActions are what a button spec can do to a button. Companion 2
+// only understands press.
+var companionActions = map[string]bool{
+	"press":        true,
+	"down":         true,
+	"up":           true,
+	"step":         true,
+	"rotate-left":  true,
+	"rotate-right": true,
+}
+
+// companionButton is the OSC message that triggers a button.
+type companionButton struct {
+	address string
+	args    []interface{}
+}
+
+func (b companionButton) message() *osc.Message {
+	return osc.NewMessage(b.address, b.args...)
+}
+
+// parseCompanionButton reads a companion_buttons value. It is either an OSC
+// address sent as is, or a button location "<page>/<row>/<col>" optionally
+// followed by an action: press (the default), down, up, rotate-left,
+// rotate-right or "step <n>". version 3 sends
+// /location/<page>/<row>/<col>/<action>, version 2 /press/bank/<page>/<bank>.
+func parseCompanionButton(spec string, version int) (companionButton, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return companionButton{}, errors.New("no button given")
+	}
+
+	if strings.HasPrefix(fields[0], "/") {
+		if len(fields) > 1 {
+			return companionButton{}, fmt.Errorf("OSC address %q has a space in it", spec)
+		}
+
+		return companionButton{address: fields[0]}, nil
+	}
+
+	parts := strings.Split(fields[0], "/")
+	if len(parts) != 3 { //nolint:gomnd // page, row and column
+		return companionButton{}, fmt.Errorf("%q is not an OSC address or a <page>/<row>/<col> location", fields[0])
+	}
+
+	var loc [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return companionButton{}, fmt.Errorf("location %q should be three whole numbers", fields[0])
+		}
+		loc[i] = n
+	}
+	page, row, col := loc[0], loc[1], loc[2]
+
+	if page < 1 || page > companionMaxPage {
+		return companionButton{}, fmt.Errorf("page %d is not between 1 and %d", page, companionMaxPage)
+	}
+
+	action := "press"
+	if len(fields) > 1 {
+		action = fields[1]
+	}
+	if !companionActions[action] {
+		return companionButton{}, fmt.Errorf("unknown action %q; use press, down, up, step <n>, rotate-left or rotate-right", action)
+	}
+
+	var args []interface{}
+	switch {
+	case action == "step" && len(fields) == 3: //nolint:gomnd // step and its number
+		step, err := strconv.Atoi(fields[2])
+		if err != nil || step < 1 {
+			return companionButton{}, fmt.Errorf("step %q should be a step number from 1", fields[2])
+		}
+		args = append(args, int32(step))
+	case action == "step":
+		return companionButton{}, errors.New("step needs a step number, as in \"step 2\"")
+	case len(fields) > 2: //nolint:gomnd // location and action
+		return companionButton{}, fmt.Errorf("unexpected %q after %s", strings.Join(fields[2:], " "), action)
+	}
+
+	switch version {
+	case 3: //nolint:gomnd // Companion 3
+		return companionButton{address: fmt.Sprintf("/location/%d/%d/%d/%s", page, row, col, action), args: args}, nil
+	case 2: //nolint:gomnd // Companion 2
+		if action != "press" {
+			return companionButton{}, fmt.Errorf("only press works with Companion 2, not %s; set companion_version to 3", action)
+		}
+		if col >= companionLegacyColumns || row*companionLegacyColumns+col >= companionLegacyButtons {
+			return companionButton{}, fmt.Errorf("row %d column %d is outside the %d buttons of a Companion 2 page", row, col, companionLegacyButtons)
+		}
+
+		return companionButton{address: fmt.Sprintf("/press/bank/%d/%d", page, row*companionLegacyColumns+col+1)}, nil
+	default:
+		return companionButton{}, fmt.Errorf("unsupported companion_version %d, use 2 or 3", version)
+	}
+}
+
+// loadCompanionButtons parses every companion_buttons entry, so a typo is
+// reported at startup rather than when someone taps the button.
+func loadCompanionButtons() (map[string]companionButton, error) {
+	specs := viper.GetStringMapString("companion_buttons")
+	version := viper.GetInt("companion_version")
+
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buttons := make(map[string]companionButton, len(specs))
+	var errs []error
+	for _, name := range names {
+		button, err := parseCompanionButton(specs[name], version)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("companion_buttons.%s: %w", name, err))
+
+			continue
+		}
+		buttons[name] = button
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return buttons, nil
+}
+
+// sendCompanion sends msg to Companion at the companion address and port.
+func sendCompanion(msg *osc.Message) error {
+	client := osc.NewClient(viper.GetString("companion"), viper.GetInt("companion_port"))
+
+	return client.Send(msg)
+}
+
+func init() {
+	viper.SetDefault("companion_port", companionDefaultPort)
+	viper.SetDefault("companion_version", companionDefaultVersion)
+}
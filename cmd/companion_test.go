@@ -0,0 +1,144 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestParseCompanionButton(t *testing.T) {
+	cases := []struct {
+		spec    string
+		version int
+		want    companionButton
+		wantErr string
+	}{
+		{"20/1/1", 3, companionButton{address: "/location/20/1/1/press"}, ""},
+		{"1/0/0 down", 3, companionButton{address: "/location/1/0/0/down"}, ""},
+		{"1/2/3 rotate-left", 3, companionButton{address: "/location/1/2/3/rotate-left"}, ""},
+		{"1/2/3 step 2", 3, companionButton{address: "/location/1/2/3/step", args: []interface{}{int32(2)}}, ""},
+		{"20/1/1", 2, companionButton{address: "/press/bank/20/10"}, ""},
+		{"20/3/7 press", 2, companionButton{address: "/press/bank/20/32"}, ""},
+		{"/press/bank/20/10", 3, companionButton{address: "/press/bank/20/10"}, ""},
+		{"/custom/trigger", 2, companionButton{address: "/custom/trigger"}, ""},
+		{"", 3, companionButton{}, "no button"},
+		{"/press bank", 3, companionButton{}, "has a space"},
+		{"20/1", 3, companionButton{}, "not an OSC address"},
+		{"20/one/1", 3, companionButton{}, "three whole numbers"},
+		{"0/1/1", 3, companionButton{}, "page 0"},
+		{"1/1/1 smash", 3, companionButton{}, "unknown action"},
+		{"1/1/1 step", 3, companionButton{}, "step number"},
+		{"1/1/1 step two", 3, companionButton{}, "step number"},
+		{"1/1/1 press now", 3, companionButton{}, "unexpected"},
+		{"1/1/1 step 2", 2, companionButton{}, "only press"},
+		{"1/4/0", 2, companionButton{}, "outside"},
+		{"1/0/8", 2, companionButton{}, "outside"},
+		{"1/1/1", 4, companionButton{}, "companion_version 4"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.spec, func(t *testing.T) {
+			got, err := parseCompanionButton(tc.spec, tc.version)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("err = %v, want %q", err, tc.wantErr)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadCompanionButtons(t *testing.T) {
+	t.Cleanup(func() {
+		viper.Set("companion_buttons", nil)
+		viper.Set("companion_version", nil)
+	})
+
+	viper.Set("companion_version", 3)
+	viper.Set("companion_buttons", map[string]string{
+		"green": "20/1/1",
+		"ftb":   "/press/bank/20/4",
+	})
+	buttons, err := loadCompanionButtons()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buttons["green"].address != "/location/20/1/1/press" || buttons["ftb"].address != "/press/bank/20/4" {
+		t.Errorf("buttons = %+v", buttons)
+	}
+
+	viper.Set("companion_buttons", map[string]string{
+		"green": "20/1/1",
+		"red":   "20/1",
+		"blue":  "20/1/2 smash",
+	})
+	_, err = loadCompanionButtons()
+	if err == nil {
+		t.Fatal("typos should be reported")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "companion_buttons.blue: unknown action") || !strings.Contains(msg, "companion_buttons.red:") {
+		t.Errorf("every bad button should be named, got:\n%s", msg)
+	}
+	if strings.Index(msg, "blue") > strings.Index(msg, "red") {
+		t.Errorf("errors should be in name order, got:\n%s", msg)
+	}
+}
+
+func TestLoadCompanionButtons_Defaults(t *testing.T) {
+	viper.Set("companion_buttons", nil)
+	for _, version := range []int{2, 3} {
+		viper.Set("companion_version", version)
+		if _, err := loadCompanionButtons(); err != nil {
+			t.Errorf("default buttons should be valid for Companion %d: %v", version, err)
+		}
+	}
+	viper.Set("companion_version", nil)
+}
+
+func TestSendCompanion_Port(t *testing.T) {
+	listener := oscConn(t, nil)
+	addr := listener.LocalAddr().(*net.UDPAddr)
+
+	viper.Set("companion", "127.0.0.1")
+	viper.Set("companion_port", addr.Port)
+	t.Cleanup(func() { viper.Set("companion_port", nil) })
+
+	button, err := parseCompanionButton("1/2/3 step 2", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sendOSC(button); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readOSCMessage(t, listener)
+	if got.Address != "/location/1/2/3/step" || !reflect.DeepEqual(got.Arguments, []interface{}{int32(2)}) {
+		t.Errorf("Companion got %s", got)
+	}
+}
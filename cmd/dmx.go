@@ -0,0 +1,509 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec // names a source, not used for security
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+const (
+	// dmxSlots is the number of channels in a universe.
+	dmxSlots = 512
+	// dmxDefaultFPS matches the DMX refresh rate most consoles send at.
+	dmxDefaultFPS = 40
+	dmxMaxFPS     = 44
+	// dmxKeepAlive resends unchanged universes, so receivers that lose a
+	// packet or start late still get the current levels.
+	dmxKeepAlive = time.Second
+
+	sacnPort        = 5568
+	sacnMaxUniverse = 63999
+	// sacnDefaultPriority is the E1.31 default; receivers take the highest
+	// priority source.
+	sacnDefaultPriority = 100
+
+	artnetPort        = 6454
+	artnetMaxUniverse = 32767
+	artnetVersion     = 14
+	artnetOpDmx       = 0x5000
+)
+
+// dmxProtocol encodes DMX data for one of the DMX-over-IP protocols.
+type dmxProtocol interface {
+	// packet encodes a universe's levels.
+	packet(universe uint16, seq byte, data *[dmxSlots]byte) []byte
+	// addr is where a universe is sent.
+	addr(universe uint16) (*net.UDPAddr, error)
+	// universes is the lowest and highest universe number.
+	universes() (uint16, uint16)
+	String() string
+}
+
+// resolveDMXTarget resolves host or host:port, defaulting the port.
+func resolveDMXTarget(target string, port int) (*net.UDPAddr, error) {
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		target = net.JoinHostPort(target, strconv.Itoa(port))
+	}
+
+	addr, err := net.ResolveUDPAddr("udp4", target)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve dmx_target %q: %w", target, err)
+	}
+
+	return addr, nil
+}
+
+// sacnProtocol sends ANSI E1.31 (sACN) data packets, to each universe's
+// multicast group unless a target is set.
+type sacnProtocol struct {
+	cid      [16]byte
+	source   string
+	priority byte
+	target   string
+}
+
+func newSACNProtocol(source string, priority byte, target string) *sacnProtocol {
+	p := &sacnProtocol{source: source, priority: priority, target: target}
+
+	// E1.31 wants a CID that stays the same for a source across restarts
+	host, _ := os.Hostname()
+	sum := sha1.Sum([]byte("vbs sACN " + host + " " + source)) //nolint:gosec // see import
+	copy(p.cid[:], sum[:])
+	p.cid[6] = p.cid[6]&0x0f | 0x50 // name based UUID
+	p.cid[8] = p.cid[8]&0x3f | 0x80
+
+	return p
+}
+
+func (p *sacnProtocol) String() string { return "sACN" }
+
+func (p *sacnProtocol) universes() (uint16, uint16) { return 1, sacnMaxUniverse }
+
+func (p *sacnProtocol) addr(universe uint16) (*net.UDPAddr, error) {
+	if p.target != "" {
+		return resolveDMXTarget(p.target, sacnPort)
+	}
+
+	return &net.UDPAddr{IP: net.IPv4(239, 255, byte(universe>>8), byte(universe)), Port: sacnPort}, nil //nolint:gomnd // E1.31 multicast
+}
+
+// packet lays out the root, framing and DMP layers of an E1.31 data packet.
+func (p *sacnProtocol) packet(universe uint16, seq byte, data *[dmxSlots]byte) []byte {
+	const (
+		rootStart    = 16
+		framingStart = 38
+		dmpStart     = 115
+		length       = 126 + dmxSlots
+	)
+	flagsLength := func(start int) uint16 { return 0x7000 | uint16(length-start) }
+
+	b := make([]byte, length)
+	binary.BigEndian.PutUint16(b[0:], 0x0010) // preamble size
+	copy(b[4:], "ASC-E1.17\x00\x00\x00")
+	binary.BigEndian.PutUint16(b[rootStart:], flagsLength(rootStart))
+	binary.BigEndian.PutUint32(b[18:], 0x00000004) // VECTOR_ROOT_E131_DATA
+	copy(b[22:], p.cid[:])
+
+	binary.BigEndian.PutUint16(b[framingStart:], flagsLength(framingStart))
+	binary.BigEndian.PutUint32(b[40:], 0x00000002) // VECTOR_E131_DATA_PACKET
+	copy(b[44:107], p.source)                      // null terminated in 64 bytes
+	b[108] = p.priority
+	b[111] = seq
+	binary.BigEndian.PutUint16(b[113:], universe)
+
+	binary.BigEndian.PutUint16(b[dmpStart:], flagsLength(dmpStart))
+	b[117] = 0x02                                   // VECTOR_DMP_SET_PROPERTY
+	b[118] = 0xa1                                   // address and data type
+	binary.BigEndian.PutUint16(b[121:], 1)          // address increment
+	binary.BigEndian.PutUint16(b[123:], 1+dmxSlots) // start code and slots
+	copy(b[126:], data[:])
+
+	return b
+}
+
+// artnetProtocol sends ArtDmx packets to a node.
+type artnetProtocol struct {
+	target string
+}
+
+func (p *artnetProtocol) String() string { return "Art-Net" }
+
+func (p *artnetProtocol) universes() (uint16, uint16) { return 0, artnetMaxUniverse }
+
+func (p *artnetProtocol) addr(_ uint16) (*net.UDPAddr, error) {
+	return resolveDMXTarget(p.target, artnetPort)
+}
+
+// packet encodes an ArtDmx packet; universe is the 15 bit port address.
+func (p *artnetProtocol) packet(universe uint16, seq byte, data *[dmxSlots]byte) []byte {
+	b := make([]byte, 18+dmxSlots)
+	copy(b, "Art-Net\x00")
+	binary.LittleEndian.PutUint16(b[8:], artnetOpDmx)
+	b[11] = artnetVersion
+	// 0 turns sequencing off, so count 1-255
+	b[12] = seq%255 + 1
+	b[14] = byte(universe)      // sub-net and universe
+	b[15] = byte(universe >> 8) // net
+	binary.BigEndian.PutUint16(b[16:], dmxSlots)
+	copy(b[18:], data[:])
+
+	return b
+}
+
+// dmxScene is a look to fade to: levels by universe and channel (1-512).
+type dmxScene struct {
+	fade   time.Duration
+	levels map[uint16]map[int]byte
+}
+
+// dmxSceneConfig is a dmx_scenes entry as configured.
+type dmxSceneConfig struct {
+	Fade   time.Duration       `mapstructure:"fade"`
+	Levels map[int]map[int]int `mapstructure:"levels"`
+}
+
+// parseDMXScene checks a scene's universes, channels and levels.
+func parseDMXScene(c dmxSceneConfig, minUniverse, maxUniverse uint16) (dmxScene, error) {
+	if len(c.Levels) == 0 {
+		return dmxScene{}, errors.New("has no levels")
+	}
+	if c.Fade < 0 {
+		return dmxScene{}, fmt.Errorf("fade %s cannot be negative", c.Fade)
+	}
+
+	scene := dmxScene{fade: c.Fade, levels: map[uint16]map[int]byte{}}
+	for universe, channels := range c.Levels {
+		if universe < int(minUniverse) || universe > int(maxUniverse) {
+			return dmxScene{}, fmt.Errorf("universe %d is not between %d and %d", universe, minUniverse, maxUniverse)
+		}
+
+		levels := map[int]byte{}
+		for channel, level := range channels {
+			if channel < 1 || channel > dmxSlots {
+				return dmxScene{}, fmt.Errorf("universe %d channel %d is not between 1 and %d", universe, channel, dmxSlots)
+			}
+			if level < 0 || level > math.MaxUint8 {
+				return dmxScene{}, fmt.Errorf("universe %d channel %d level %d is not between 0 and 255", universe, channel, level)
+			}
+			levels[channel] = byte(level)
+		}
+		scene.levels[uint16(universe)] = levels
+	}
+
+	return scene, nil
+}
+
+// newDMXProtocol reads dmx_protocol and its settings; nil when DMX output
+// is off.
+func newDMXProtocol() (dmxProtocol, error) {
+	target := viper.GetString("dmx_target")
+
+	switch protocol := strings.ToLower(viper.GetString("dmx_protocol")); protocol {
+	case "":
+		return nil, nil
+	case "sacn", "e1.31":
+		priority := viper.GetInt("dmx_priority")
+		if priority < 0 || priority > 200 { //nolint:gomnd // E1.31 priority range
+			return nil, fmt.Errorf("dmx_priority %d is not between 0 and 200", priority)
+		}
+
+		return newSACNProtocol(viper.GetString("dmx_source"), byte(priority), target), nil
+	case "artnet", "art-net":
+		if target == "" {
+			return nil, errors.New("Art-Net needs dmx_target, the address of the node")
+		}
+
+		return &artnetProtocol{target: target}, nil
+	default:
+		return nil, fmt.Errorf("unknown dmx_protocol %q, use sacn or artnet", protocol)
+	}
+}
+
+// loadDMXScenes parses every dmx_scenes entry for the configured protocol.
+func loadDMXScenes() (map[string]dmxScene, error) {
+	protocol, err := newDMXProtocol()
+	if err != nil || protocol == nil {
+		return nil, err
+	}
+	minUniverse, maxUniverse := protocol.universes()
+
+	var raw map[string]dmxSceneConfig
+	strict := func(c *mapstructure.DecoderConfig) { c.ErrorUnused = true }
+	if err := viper.UnmarshalKey("dmx_scenes", &raw, strict); err != nil {
+		return nil, fmt.Errorf("could not read dmx_scenes: %w", err)
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	scenes := make(map[string]dmxScene, len(raw))
+	var errs []error
+	for _, name := range names {
+		scene, err := parseDMXScene(raw[name], minUniverse, maxUniverse)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("dmx_scenes.%s: %w", name, err))
+
+			continue
+		}
+		scenes[name] = scene
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return scenes, nil
+}
+
+// dmxFade moves one channel from one level to another.
+type dmxFade struct {
+	from, to float64
+	start    time.Time
+	length   time.Duration
+}
+
+type dmxUniverse struct {
+	addr   *net.UDPAddr
+	levels [dmxSlots]float64
+	fades  map[int]dmxFade
+	seq    byte
+	sent   time.Time
+	dirty  bool
+}
+
+// dmxOutput crossfades scenes and sends every universe it has levels for.
+type dmxOutput struct {
+	mu        sync.Mutex
+	protocol  dmxProtocol
+	conn      net.PacketConn
+	fps       int
+	universes map[uint16]*dmxUniverse
+	now       func() time.Time
+}
+
+func newDMXOutput(protocol dmxProtocol, fps int) (*dmxOutput, error) {
+	if fps < 1 || fps > dmxMaxFPS {
+		return nil, fmt.Errorf("dmx_fps %d is not between 1 and %d", fps, dmxMaxFPS)
+	}
+
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, fmt.Errorf("could not open a socket for %s: %w", protocol, err)
+	}
+
+	return &dmxOutput{protocol: protocol, conn: conn, fps: fps, universes: map[uint16]*dmxUniverse{}, now: time.Now}, nil
+}
+
+// Fade starts fading to scene from the current levels. Channels the scene
+// leaves out keep their level, and fades it overrides stop where they are.
+func (o *dmxOutput) Fade(scene dmxScene) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.now()
+	for universe, channels := range scene.levels {
+		u, found := o.universes[universe]
+		if !found {
+			addr, err := o.protocol.addr(universe)
+			if err != nil {
+				return err
+			}
+			u = &dmxUniverse{addr: addr, fades: map[int]dmxFade{}}
+			o.universes[universe] = u
+		}
+
+		for channel, level := range channels {
+			i := channel - 1
+			if scene.fade == 0 {
+				delete(u.fades, i)
+				u.levels[i] = float64(level)
+				u.dirty = true
+
+				continue
+			}
+			u.fades[i] = dmxFade{from: u.levels[i], to: float64(level), start: now, length: scene.fade}
+		}
+	}
+
+	return nil
+}
+
+// Levels returns a universe's current levels.
+func (o *dmxOutput) Levels(universe uint16) [dmxSlots]byte {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var data [dmxSlots]byte
+	if u, found := o.universes[universe]; found {
+		data = u.bytes()
+	}
+
+	return data
+}
+
+func (u *dmxUniverse) bytes() [dmxSlots]byte {
+	var data [dmxSlots]byte
+	for i, level := range u.levels {
+		data[i] = byte(math.Round(level))
+	}
+
+	return data
+}
+
+// tick advances the fades and sends universes that changed, or that are
+// due a keepalive.
+func (o *dmxOutput) tick() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.now()
+	var errs []error
+	for universe, u := range o.universes {
+		for i, f := range u.fades {
+			done := float64(now.Sub(f.start)) / float64(f.length)
+			if done >= 1 {
+				done = 1
+				delete(u.fades, i)
+			}
+
+			if level := f.from + (f.to-f.from)*done; level != u.levels[i] {
+				u.levels[i] = level
+				u.dirty = true
+			}
+		}
+
+		if !u.dirty && now.Sub(u.sent) < dmxKeepAlive {
+			continue
+		}
+
+		data := u.bytes()
+		if _, err := o.conn.WriteTo(o.protocol.packet(universe, u.seq, &data), u.addr); err != nil {
+			errs = append(errs, fmt.Errorf("could not send %s universe %d: %w", o.protocol, universe, err))
+		}
+		u.seq++
+		u.sent, u.dirty = now, false
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run sends frames until ctx is done.
+func (o *dmxOutput) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second / time.Duration(o.fps))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := o.tick(); err != nil {
+				log.Debug().Err(err).Msg("DMX output")
+			}
+		}
+	}
+}
+
+func (o *dmxOutput) Close() error {
+	return o.conn.Close()
+}
+
+// liveDMX is the DMX output, when dmx_protocol is set.
+var liveDMX *dmxOutput
+
+// startDMX opens the configured DMX output and starts sending frames.
+func startDMX() error {
+	protocol, err := newDMXProtocol()
+	if err != nil || protocol == nil {
+		return err
+	}
+
+	out, err := newDMXOutput(protocol, viper.GetInt("dmx_fps"))
+	if err != nil {
+		return err
+	}
+	go out.Run(context.Background())
+	liveDMX = out
+
+	log.Info().Msgf("Sending %s at %d frames a second", protocol, out.fps)
+
+	return nil
+}
+
+// dmxSceneNamed looks up a scene when DMX output is running.
+func dmxSceneNamed(name string) (dmxScene, bool) {
+	if liveDMX == nil {
+		return dmxScene{}, false
+	}
+
+	scenes, err := loadDMXScenes()
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid dmx_scenes")
+
+		return dmxScene{}, false
+	}
+
+	scene, found := scenes[name]
+
+	return scene, found
+}
+
+// handleScene fades to a scene for a request.
+func handleScene(w http.ResponseWriter, r *http.Request, name string, scene dmxScene) {
+	universes := make([]string, 0, len(scene.levels))
+	for universe := range scene.levels {
+		universes = append(universes, strconv.Itoa(int(universe)))
+	}
+	sort.Strings(universes)
+	address := fmt.Sprintf("%s universe %s", liveDMX.protocol, strings.Join(universes, ","))
+
+	if err := liveDMX.Fade(scene); err != nil {
+		log.Error().Err(err).Msgf("Could not fade to %s", name)
+		auditPress(r, name, address, pressFailed, err)
+		sendFailureResponse(w, r)
+
+		return
+	}
+
+	liveButtons.Pressed(name, lightingUserFrom(r))
+	auditPress(r, name, address, pressSent, nil)
+	sendOKResponse(w, r)
+}
+
+func init() {
+	viper.SetDefault("dmx_fps", dmxDefaultFPS)
+	viper.SetDefault("dmx_source", "vbs")
+	viper.SetDefault("dmx_priority", sacnDefaultPriority)
+}
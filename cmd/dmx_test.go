@@ -0,0 +1,257 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// dmxPacket is a packet decoded by a test receiver.
+type dmxPacket struct {
+	universe uint16
+	seq      byte
+	data     []byte
+}
+
+// readDMXPacket decodes the next sACN or Art-Net packet from conn.
+func readDMXPacket(t *testing.T, conn net.PacketConn) dmxPacket {
+	t.Helper()
+
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := buf[:n]
+
+	switch {
+	case n == 638 && bytes.Equal(b[4:16], []byte("ASC-E1.17\x00\x00\x00")):
+		if b[125] != 0 || binary.BigEndian.Uint16(b[123:]) != 513 {
+			t.Fatalf("sACN DMP layer % x", b[115:126])
+		}
+
+		return dmxPacket{universe: binary.BigEndian.Uint16(b[113:]), seq: b[111], data: b[126:]}
+	case n == 530 && bytes.Equal(b[:8], []byte("Art-Net\x00")):
+		if binary.LittleEndian.Uint16(b[8:]) != artnetOpDmx || b[11] != artnetVersion {
+			t.Fatalf("ArtDmx header % x", b[:18])
+		}
+
+		return dmxPacket{universe: uint16(b[15])<<8 | uint16(b[14]), seq: b[12], data: b[18:]}
+	default:
+		t.Fatalf("not a DMX packet: %d bytes % x", n, b[:16])
+
+		return dmxPacket{}
+	}
+}
+
+func TestDMXPackets(t *testing.T) {
+	var data [dmxSlots]byte
+	data[0], data[511] = 255, 7
+
+	sacn := newSACNProtocol("vbs", 120, "")
+	b := sacn.packet(258, 9, &data)
+	if len(b) != 638 || binary.BigEndian.Uint16(b[16:]) != 0x7000|622 || binary.BigEndian.Uint16(b[38:]) != 0x7000|600 || binary.BigEndian.Uint16(b[115:]) != 0x7000|523 {
+		t.Errorf("sACN lengths are wrong: % x", b[:40])
+	}
+	if string(b[44:47]) != "vbs" || b[47] != 0 || b[108] != 120 || b[111] != 9 || b[126] != 255 || b[637] != 7 {
+		t.Errorf("sACN fields are wrong")
+	}
+	if again := newSACNProtocol("vbs", 120, ""); again.cid != sacn.cid {
+		t.Error("the CID should not change between runs")
+	}
+	if addr, _ := sacn.addr(258); addr.String() != "239.255.1.2:5568" {
+		t.Errorf("universe 258 goes to %s", addr)
+	}
+
+	artnet := &artnetProtocol{target: "10.0.0.9"}
+	b = artnet.packet(0x1234, 255, &data)
+	if len(b) != 530 || b[12] != 1 || b[14] != 0x34 || b[15] != 0x12 || b[16] != 2 || b[17] != 0 || b[18] != 255 {
+		t.Errorf("ArtDmx header % x", b[:19])
+	}
+	if addr, _ := artnet.addr(1); addr.String() != "10.0.0.9:6454" {
+		t.Errorf("Art-Net goes to %s", addr)
+	}
+}
+
+func TestLoadDMXScenes(t *testing.T) {
+	t.Cleanup(func() {
+		viper.Set("dmx_protocol", nil)
+		viper.Set("dmx_target", nil)
+		viper.Set("dmx_scenes", nil)
+	})
+
+	viper.Set("dmx_protocol", "sacn")
+	viper.Set("dmx_scenes", map[string]interface{}{
+		"warm": map[string]interface{}{
+			"fade":   "2s",
+			"levels": map[string]interface{}{"1": map[string]interface{}{"1": 255, "2": 128}},
+		},
+	})
+	scenes, err := loadDMXScenes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if warm := scenes["warm"]; warm.fade != 2*time.Second || warm.levels[1][1] != 255 || warm.levels[1][2] != 128 {
+		t.Errorf("warm = %+v", warm)
+	}
+
+	cases := []struct {
+		name     string
+		protocol string
+		scene    map[string]interface{}
+		wantErr  string
+	}{
+		{"no levels", "sacn", map[string]interface{}{"fade": "1s"}, "has no levels"},
+		{"universe 0", "sacn", map[string]interface{}{"levels": map[string]interface{}{"0": map[string]interface{}{"1": 1}}}, "universe 0 is not between 1 and 63999"},
+		{"channel", "sacn", map[string]interface{}{"levels": map[string]interface{}{"1": map[string]interface{}{"513": 1}}}, "channel 513"},
+		{"level", "sacn", map[string]interface{}{"levels": map[string]interface{}{"1": map[string]interface{}{"1": 256}}}, "level 256"},
+		{"negative fade", "sacn", map[string]interface{}{"fade": "-1s", "levels": map[string]interface{}{"1": map[string]interface{}{"1": 1}}}, "cannot be negative"},
+		{"typo", "sacn", map[string]interface{}{"fdae": "1s", "levels": map[string]interface{}{"1": map[string]interface{}{"1": 1}}}, "fdae"},
+		{"art-net without target", "artnet", map[string]interface{}{"levels": map[string]interface{}{"0": map[string]interface{}{"1": 1}}}, "needs dmx_target"},
+		{"unknown protocol", "dmx512", map[string]interface{}{"levels": map[string]interface{}{"1": map[string]interface{}{"1": 1}}}, "use sacn or artnet"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			viper.Set("dmx_protocol", tc.protocol)
+			viper.Set("dmx_scenes", map[string]interface{}{"look": tc.scene})
+			if _, err := loadDMXScenes(); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// testDMXOutput sends to a local listener on a clock the test moves.
+func testDMXOutput(t *testing.T, protocol func(target string) dmxProtocol) (*dmxOutput, net.PacketConn, *time.Time) {
+	t.Helper()
+
+	receiver := oscConn(t, nil)
+	out, err := newDMXOutput(protocol(receiver.LocalAddr().String()), dmxDefaultFPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { out.Close() })
+
+	now := time.Date(2026, 10, 18, 19, 0, 0, 0, time.UTC)
+	out.now = func() time.Time { return now }
+
+	return out, receiver, &now
+}
+
+func TestDMXOutput_Fade(t *testing.T) {
+	out, receiver, now := testDMXOutput(t, func(target string) dmxProtocol {
+		return newSACNProtocol("vbs", sacnDefaultPriority, target)
+	})
+
+	if err := out.Fade(dmxScene{levels: map[uint16]map[int]byte{1: {1: 200, 2: 100}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.tick(); err != nil {
+		t.Fatal(err)
+	}
+	if p := readDMXPacket(t, receiver); p.universe != 1 || p.seq != 0 || p.data[0] != 200 || p.data[1] != 100 {
+		t.Errorf("a scene without a fade should snap, got universe %d levels %v", p.universe, p.data[:3])
+	}
+
+	if err := out.Fade(dmxScene{fade: time.Second, levels: map[uint16]map[int]byte{1: {1: 0}}}); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(500 * time.Millisecond)
+	if err := out.tick(); err != nil {
+		t.Fatal(err)
+	}
+	if p := readDMXPacket(t, receiver); p.seq != 1 || p.data[0] != 100 || p.data[1] != 100 {
+		t.Errorf("halfway through the fade, levels %v", p.data[:3])
+	}
+
+	*now = now.Add(time.Second)
+	if err := out.tick(); err != nil {
+		t.Fatal(err)
+	}
+	if p := readDMXPacket(t, receiver); p.data[0] != 0 || p.data[1] != 100 {
+		t.Errorf("after the fade, levels %v", p.data[:3])
+	}
+	if levels := out.Levels(1); levels[0] != 0 || levels[1] != 100 {
+		t.Errorf("Levels = %v", levels[:3])
+	}
+
+	// nothing changed, so nothing is sent until the keepalive is due
+	*now = now.Add(100 * time.Millisecond)
+	if err := out.tick(); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(dmxKeepAlive)
+	if err := out.tick(); err != nil {
+		t.Fatal(err)
+	}
+	if p := readDMXPacket(t, receiver); p.seq != 3 {
+		t.Errorf("keepalive has sequence %d, want 3", p.seq)
+	}
+}
+
+func TestHandleOSC_Scene(t *testing.T) {
+	out, receiver, _ := testDMXOutput(t, func(target string) dmxProtocol {
+		return &artnetProtocol{target: target}
+	})
+	liveDMX = out
+	viper.Set("dmx_protocol", "artnet")
+	viper.Set("dmx_target", receiver.LocalAddr().String())
+	viper.Set("dmx_scenes", map[string]interface{}{
+		"warm": map[string]interface{}{"levels": map[string]interface{}{"3": map[string]interface{}{"10": 255}}},
+	})
+	var presses []buttonPress
+	pressAudit = func(p buttonPress) { presses = append(presses, p) }
+	t.Cleanup(func() {
+		liveDMX, pressAudit = nil, nil
+		viper.Set("dmx_protocol", nil)
+		viper.Set("dmx_target", nil)
+		viper.Set("dmx_scenes", nil)
+	})
+
+	if err := checkButtonConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handleOSC(w, httptest.NewRequest(http.MethodPost, "/api/light/warm", nil), "/api/light/", map[string]string{})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d", w.Code)
+	}
+	if err := out.tick(); err != nil {
+		t.Fatal(err)
+	}
+	if p := readDMXPacket(t, receiver); p.universe != 3 || p.data[9] != 255 {
+		t.Errorf("universe %d channel 10 at %d", p.universe, p.data[9])
+	}
+	if len(presses) != 1 || presses[0].Button != "warm" || presses[0].OSC != "Art-Net universe 3" || presses[0].Result != pressSent {
+		t.Errorf("presses = %+v", presses)
+	}
+
+	viper.Set("companion_buttons", map[string]string{"warm": "20/1/1"})
+	t.Cleanup(func() { viper.Set("companion_buttons", nil) })
+	if err := checkButtonConfig(); err == nil || !strings.Contains(err.Error(), "dmx_scenes.warm") {
+		t.Errorf("a scene named like a button should be reported, got %v", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 
 	"github.com/kindlyops/vbs/embeddy"
+	_ "github.com/kindlyops/vbs/migrations" // registers the vbs collections
 	"github.com/labstack/echo/v5"
 	"github.com/muesli/coral"
 	"github.com/pocketbase/pocketbase"
@@ -28,8 +29,13 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/plugins/migratecmd"
 	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
 )
 
+// flyProxyRange is fly.io's private network, which its edge proxy forwards
+// requests from.
+const flyProxyRange = "fdaa::/16"
+
 var flyServerCmd = &coral.Command{
 	Use:   "serve",
 	Short: "Run pocketbase http server",
@@ -51,6 +57,29 @@ func flyServer(cmd *coral.Command, args []string) {
 		log.Fatal().Stack().Err(err).Msg("Couldn't create config dir")
 	}
 
+	if err := checkButtonConfig(); err != nil {
+		log.Fatal().Err(err).Msg("Invalid button configuration")
+	}
+
+	auth, err := openLightingAuth()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid lighting auth configuration")
+	}
+
+	if !viper.IsSet("lighting_trusted_proxies") {
+		// fly's proxy reaches the app over its private network, so trust
+		// it to report the client address for PIN lockouts
+		_ = auth.trustProxies([]string{flyProxyRange})
+	}
+
+	if _, err := listenButtonFeedback(); err != nil {
+		log.Fatal().Err(err).Msg("Could not listen for Companion feedback")
+	}
+
+	if err := startDMX(); err != nil {
+		log.Fatal().Err(err).Msg("Could not start DMX output")
+	}
+
 	log.Debug().Msgf("running pocketbase with data dir %s\n", configDir)
 	app := pocketbase.NewWithConfig(&pocketbase.Config{
 		DefaultDataDir: configDir,
@@ -65,6 +94,9 @@ func flyServer(cmd *coral.Command, args []string) {
 		Automigrate:  true,
 	})
 
+	presses := &pressStore{dao: app.Dao}
+	pressAudit = presses.Record
+
 	app.OnBeforeServe().Add(func(e *core.ServeEvent) error {
 		public, err := fs.Sub(embeddy.GetNextFS(), "public")
 		if err != nil {
@@ -86,6 +118,7 @@ func flyServer(cmd *coral.Command, args []string) {
 			Path:   "/api/switcher/*",
 			Middlewares: []echo.MiddlewareFunc{
 				apis.ActivityLogger(app),
+				auth.Middleware,
 			},
 			Handler: echo.WrapHandler(&Switcher{}),
 		})
@@ -95,10 +128,54 @@ func flyServer(cmd *coral.Command, args []string) {
 			Path:   "/api/light/*",
 			Middlewares: []echo.MiddlewareFunc{
 				apis.ActivityLogger(app),
+				auth.Middleware,
 			},
 			Handler: echo.WrapHandler(&Lighting{}),
 		})
 
+		e.Router.AddRoute(echo.Route{
+			Method:      http.MethodGet,
+			Path:        buttonsPath,
+			Middlewares: []echo.MiddlewareFunc{auth.Middleware},
+			Handler:     echo.WrapHandler(&Buttons{}),
+		})
+
+		e.Router.AddRoute(echo.Route{
+			Method:      http.MethodGet,
+			Path:        buttonLivePath,
+			Middlewares: []echo.MiddlewareFunc{auth.Middleware},
+			Handler:     echo.WrapHandler(liveButtons),
+		})
+
+		e.Router.AddRoute(echo.Route{
+			Method: http.MethodPost,
+			Path:   buttonsPath + "/*",
+			Middlewares: []echo.MiddlewareFunc{
+				apis.ActivityLogger(app),
+				auth.Middleware,
+			},
+			Handler: echo.WrapHandler(&Buttons{}),
+		})
+
+		e.Router.AddRoute(echo.Route{
+			Method:      http.MethodGet,
+			Path:        pressesPath,
+			Middlewares: []echo.MiddlewareFunc{auth.Middleware},
+			Handler:     echo.WrapHandler(presses),
+		})
+
+		for _, route := range []struct{ method, path string }{
+			{http.MethodGet, "/pair"},
+			{http.MethodGet, "/api/auth"},
+			{http.MethodPost, "/api/auth/login"},
+		} {
+			e.Router.AddRoute(echo.Route{
+				Method:  route.method,
+				Path:    route.path,
+				Handler: echo.WrapHandler(auth),
+			})
+		}
+
 		return nil
 	})
 
@@ -0,0 +1,200 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kennygrant/sanitize"
+	"github.com/muesli/coral"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+var grabCmd = &coral.Command{
+	Use:   "grab <videofile.mp4> [timecode...]",
+	Short: "Export still images from a video file.",
+	Long: `Use ffmpeg to export full-resolution stills from a video file at the
+given timecodes (h:mm:ss.f, m:ss.f or seconds), or at the start of every
+chapter with --chapters. Stills are named after the source file and timecode.`,
+	Example: `vbs grab sermon.mp4 0:12:30 1:02:03.5
+vbs grab --chapters --format jpeg --out stills sermon.mp4`,
+	Run:  grab,
+	Args: coral.MinimumNArgs(1),
+}
+
+var (
+	grabChapters bool
+	grabFormat   string
+	grabOut      string
+)
+
+// grabExt maps --format to a file extension.
+var grabExt = map[string]string{"png": ".png", "jpeg": ".jpg", "jpg": ".jpg"}
+
+// grabFileName names a still from source at seconds, e.g.
+// sermon_12-30.0.png. Colons are avoided so the name works everywhere.
+func grabFileName(source string, seconds float64, ext string) string {
+	base := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	timecode := strings.ReplaceAll(formatTimecode(seconds), ":", "-")
+
+	return sanitize.Name(base) + "_" + timecode + ext
+}
+
+// grabArgs builds the ffmpeg command line for one still. Seeking before the
+// input is frame accurate when re-encoding and much faster on long files.
+func grabArgs(source string, seconds float64, outfile string) []string {
+	args := []string{
+		"-loglevel", "error",
+		"-ss", strconv.FormatFloat(seconds, 'f', 3, 64),
+		"-i", source,
+		"-frames:v", "1",
+		"-y", // overwrite output files
+	}
+	if filepath.Ext(outfile) == ".jpg" {
+		args = append(args, "-q:v", "2")
+	}
+
+	return append(args, outfile)
+}
+
+// grabTimes collects the times to grab from timecode arguments and, when
+// withChapters is set, the chapter starts of source.
+func grabTimes(source string, timecodes []string, withChapters bool) ([]float64, error) {
+	var times []float64
+
+	for _, tc := range timecodes {
+		seconds, err := parseTimecode(tc)
+		if err != nil {
+			return nil, err
+		}
+		times = append(times, seconds)
+	}
+
+	if withChapters {
+		data, err := getChapters(source)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range toPlayChapters(data) {
+			times = append(times, c.start)
+		}
+	}
+
+	if len(times) == 0 {
+		return nil, fmt.Errorf("no timecodes given and no chapters found; pass timecodes or --chapters")
+	}
+
+	return times, nil
+}
+
+func grab(cmd *coral.Command, args []string) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		log.Fatal().Err(err).Msg("Could not find ffmpeg. Please install ffmpeg.")
+	}
+
+	ext, ok := grabExt[strings.ToLower(grabFormat)]
+	if !ok {
+		log.Fatal().Msgf("Unknown still format %q, use png or jpeg", grabFormat)
+	}
+
+	source, err := filepath.Abs(resolveInputPath(args[0]))
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Could not resolve path %s", args[0])
+	}
+
+	if _, err := os.Stat(source); err != nil {
+		log.Fatal().Err(err).Msgf("Could not access video %s", source)
+	}
+
+	times, err := grabTimes(source, args[1:], grabChapters)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not work out what to grab")
+	}
+
+	outdir := resolveInputPath(grabOut)
+	if err := os.MkdirAll(outdir, 0o755); err != nil {
+		log.Fatal().Err(err).Msg("Could not create output directory")
+	}
+
+	failed := false
+	for _, seconds := range times {
+		outfile := filepath.Join(outdir, grabFileName(source, seconds, ext))
+
+		output, err := exec.Command("ffmpeg", grabArgs(source, seconds, outfile)...).CombinedOutput()
+		if err != nil {
+			log.Error().Err(err).Msgf("%s: %s", outfile, output)
+			failed = true
+
+			continue
+		}
+
+		fmt.Println(outfile)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// stillGrabbedMsg reports a frame grab written by mpv.
+type stillGrabbedMsg struct {
+	path string
+}
+
+// cmdGrabStillMpv has mpv write the frame on screen, without OSD or
+// subtitles, to the configured grab directory.
+func cmdGrabStillMpv(m model) tea.Cmd {
+	dir := resolveInputPath(viper.GetString("play.grab_dir"))
+	if dir == "" {
+		dir = "."
+	}
+	outfile, err := filepath.Abs(filepath.Join(dir, grabFileName(m.currentItem, m.position, ".png")))
+	if err != nil {
+		return func() tea.Msg { return mpvErrMsg{err} }
+	}
+
+	client := m.mpv
+
+	return func() tea.Msg {
+		if err := os.MkdirAll(filepath.Dir(outfile), 0o755); err != nil {
+			return mpvErrMsg{fmt.Errorf("could not create grab directory: %w", err)}
+		}
+
+		if client == nil {
+			return mpvErrMsg{fmt.Errorf("mpv is not connected, could not grab a still")}
+		}
+
+		if _, err := client.Command("screenshot-to-file", outfile, "video"); err != nil {
+			return mpvErrMsg{err}
+		}
+
+		return stillGrabbedMsg{path: outfile}
+	}
+}
+
+func init() {
+	grabCmd.Flags().BoolVar(&grabChapters, "chapters", false, "Also grab the first frame of every chapter")
+	grabCmd.Flags().StringVar(&grabFormat, "format", "png", "Still image format: png or jpeg")
+	grabCmd.Flags().StringVarP(&grabOut, "out", "o", ".", "Directory to write stills to")
+
+	rootCmd.AddCommand(grabCmd)
+}
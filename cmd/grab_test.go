@@ -0,0 +1,68 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGrabFileName(t *testing.T) {
+	cases := []struct {
+		source  string
+		seconds float64
+		ext     string
+		want    string
+	}{
+		{"/media/sermon.mp4", 750, ".png", "sermon_12-30.0.png"},
+		{"/media/Sunday Service.mov", 3723.5, ".jpg", "sunday-service_1-02-03.5.jpg"},
+	}
+
+	for _, tc := range cases {
+		if got := grabFileName(tc.source, tc.seconds, tc.ext); got != tc.want {
+			t.Errorf("grabFileName(%q, %v) = %q, want %q", tc.source, tc.seconds, got, tc.want)
+		}
+	}
+}
+
+func TestGrabArgs(t *testing.T) {
+	png := strings.Join(grabArgs("/media/a.mp4", 12.5, "/out/a.png"), " ")
+	if png != "-loglevel error -ss 12.500 -i /media/a.mp4 -frames:v 1 -y /out/a.png" {
+		t.Errorf("png args = %s", png)
+	}
+
+	jpeg := grabArgs("/media/a.mp4", 1, "/out/a.jpg")
+	if fmt.Sprint(jpeg[len(jpeg)-3:]) != "[-q:v 2 /out/a.jpg]" {
+		t.Errorf("jpeg should set quality, got %v", jpeg)
+	}
+}
+
+func TestGrabTimes(t *testing.T) {
+	times, err := grabTimes("/media/a.mp4", []string{"0:12:30", "90.5"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(times) != "[750 90.5]" {
+		t.Errorf("times = %v", times)
+	}
+
+	if _, err := grabTimes("/media/a.mp4", []string{"soon"}, false); err == nil {
+		t.Error("expected a timecode error")
+	}
+	if _, err := grabTimes("/media/a.mp4", nil, false); err == nil {
+		t.Error("expected an error with nothing to grab")
+	}
+}
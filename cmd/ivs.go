@@ -15,96 +15,163 @@
 package cmd
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/ivs"
-	"github.com/hypebeast/go-osc/osc"
 	"github.com/muesli/coral"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 )
 
 var ivsOscBridgeCmd = &coral.Command{
-	Use:   "ivs-bridge <ivs-stream-arn>",
+	Use:   "ivs-bridge [ivs-stream-arn]",
 	Short: "Connect OSC commands to IVS PutMetadata.",
-	Long:  `Use OSC to send messages to IVS using PutMetadata API.`,
-	Run:   ivsOscBridge,
-	Args:  coral.ExactArgs(1),
+	Long: `Use OSC to send messages to IVS using PutMetadata API.
+
+Messages to /vbs/ivsbridge are sent to the channel ARN given as an argument,
+or to the channel saved as --channel <name> by ivs-channel.
+More OSC addresses can be routed to one or more channels, with a payload
+template, using the ivs_routes config key.
+
+Each message is answered once per channel with /vbs/ivsbridge/ack or
+/vbs/ivsbridge/error, sent back to the sender or to --feedback host:port.
+The bridge answers /vbs/ping with /vbs/pong and sends /vbs/heartbeat every
+--heartbeat interval, so controllers can show whether it is running.
+
+Metadata goes to IVS unless --sink (or the ivs_sink config key) names other
+sinks, separated by commas: stdout, file:<path> for JSON lines, an http(s)
+webhook URL, or websocket:<host:port> to broadcast on ws://host:port/metadata.
+With --journal every payload sent is also appended to a file, with its offset
+in the live stream, for ivs-export-metadata to attach to the recording.`,
+	Example: `vbs ivs-bridge arn:aws:ivs:us-west-2:123456789012:channel/abcd
+vbs ivs-bridge --sink stdout,websocket:127.0.0.1:8080 arn:aws:ivs:us-west-2:123456789012:channel/abcd`,
+	Run: ivsOscBridge,
+	Args: func(cmd *coral.Command, args []string) error {
+		if name, _ := cmd.Flags().GetString("channel"); name != "" {
+			return coral.NoArgs(cmd, args)
+		}
+
+		return coral.MaximumNArgs(1)(cmd, args)
+	},
 }
 
 var ivsPutMetadataCmd = &coral.Command{
-	Use:   "ivs-put <ivs-stream-arn> <data payload>",
+	Use:   "ivs-put <ivs-stream-arn | --channel name> <data payload>",
 	Short: "Send payload to IVS PutMetadata.",
-	Long:  `Send messages to IVS using PutMetadata API.`,
-	Run:   ivsPutMetadata,
-	Args:  coral.ExactArgs(2), //nolint:gomnd // this is an appropriate magic number
+	Long: `Send messages to IVS using PutMetadata API, or to the sinks named by
+--sink, and journal them with --journal, as for ivs-bridge.`,
+	Run:  ivsPutMetadata,
+	Args: channelArgs(2), //nolint:gomnd // this is an appropriate magic number
 }
 
 func ivsOscBridge(cmd *coral.Command, args []string) {
-	arn := args[0]
+	arn, _, err := channelARN(cmd, args)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not find channel")
+	}
+
+	routes, err := loadIVSRoutes(arn)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not configure bridge")
+	}
+
 	addr := "127.0.0.1:" + viper.GetString("ivs_port")
 
 	log.Debug().Msgf("Listening on port: '%s'\n", addr)
 
-	s := session.Must(session.NewSession())
-	svc := ivs.New(s)
+	sink, err := openMetadataSink(cmd)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not configure metadata sink")
+	}
+	defer sink.Close()
 
-	d := osc.NewStandardDispatcher()
-	_ = d.AddMsgHandler("/vbs/ivsbridge", func(msg *osc.Message) {
-		log.Debug().Msg(msg.String())
-		if len(msg.Arguments) == 0 {
-			log.Error().Msg("Received OSC message with no arguments")
-			return
-		}
-		data := fmt.Sprintf("%v", msg.Arguments[0])
-		input := &ivs.PutMetadataInput{
-			ChannelArn: aws.String(arn),
-			Metadata:   aws.String(data),
-		}
+	queue := newMetadataQueue(sink.Put, viper.GetFloat64("ivs_rate"), viper.GetInt("ivs_queue"))
 
-		_, err := svc.PutMetadata(input)
-		if err != nil {
-			log.Debug().Err(err).Msg("error from ivs.PutMetadata")
-		}
-	})
+	server := newOSCServer(addr)
 
-	server := &osc.Server{
-		Addr:       addr,
-		Dispatcher: d,
+	feedback, err := newOSCFeedback(server, "ivs-bridge", viper.GetString("ivs_feedback"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not configure OSC feedback")
+	}
+
+	for _, route := range routes {
+		log.Debug().Msgf("Routing %s to %v", route.Address, route.Channels)
+		server.Handle(route.Address, feedback.Wrap(ivsRouteHandler(route, queue.SendNotify)))
 	}
 
-	if err := server.ListenAndServe(); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go feedback.Run(ctx, viper.GetDuration("ivs_heartbeat"))
+
+	// stop listening on ctrl-c, then give queued metadata a chance to go out
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupt
+		_ = server.Close()
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, net.ErrClosed) {
 		log.Error().Err(err).Msg("error from server.ListenAndServe")
 	}
+
+	queue.Close(ivsDrainTimeout)
 }
 
 func ivsPutMetadata(cmd *coral.Command, args []string) {
-	arn := args[0]
-	data := args[1]
+	arn, rest, err := channelARN(cmd, args)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not find channel")
+	}
+	data := rest[0]
 
 	log.Debug().Msgf("got data: '%s'\n", data)
 
-	s := session.Must(session.NewSession())
-	svc := ivs.New(s)
-	input := &ivs.PutMetadataInput{
-		ChannelArn: aws.String(arn),
-		Metadata:   aws.String(data),
+	if err := validateIVSPayload(data); err != nil {
+		log.Fatal().Err(err).Msg("Invalid metadata")
+	}
+
+	sink, err := openMetadataSink(cmd)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not configure metadata sink")
 	}
 
-	_, err := svc.PutMetadata(input)
+	retries, err := putWithRetry(context.Background(), sink.Put, arn, data)
+	_ = sink.Close()
+
 	if err != nil {
-		log.Fatal().Err(err).Msg("error from ivs.PutMetadata")
+		log.Fatal().Err(err).Msgf("Could not send metadata after %d attempts", retries+1)
 	}
 }
 
 // Port to listen for OSC messages.
 var Port string
 
+// ivsDrainTimeout is how long the bridge waits for queued metadata on exit.
+const ivsDrainTimeout = 5 * time.Second
+
 func init() {
 	ivsOscBridgeCmd.Flags().StringVarP(&Port, "port", "p", "4427", "Port to listen for OSC")
 	viper.BindPFlag("ivs_port", ivsOscBridgeCmd.Flags().Lookup("port"))
+	ivsOscBridgeCmd.Flags().Float64("rate", ivsDefaultRate, "PutMetadata calls per second per channel")
+	viper.BindPFlag("ivs_rate", ivsOscBridgeCmd.Flags().Lookup("rate"))
+	ivsOscBridgeCmd.Flags().Int("queue", ivsDefaultQueue, "Payloads to hold per channel before dropping")
+	viper.BindPFlag("ivs_queue", ivsOscBridgeCmd.Flags().Lookup("queue"))
+	ivsOscBridgeCmd.Flags().String("feedback", "", "host:port to send acks, errors and heartbeats to instead of the sender")
+	viper.BindPFlag("ivs_feedback", ivsOscBridgeCmd.Flags().Lookup("feedback"))
+	ivsOscBridgeCmd.Flags().Duration("heartbeat", 5*time.Second, "Interval between /vbs/heartbeat messages, 0 to disable") //nolint:gomnd // default
+	viper.BindPFlag("ivs_heartbeat", ivsOscBridgeCmd.Flags().Lookup("heartbeat"))
+	addSinkFlag(ivsOscBridgeCmd)
+	addSinkFlag(ivsPutMetadataCmd)
+	addJournalFlag(ivsOscBridgeCmd)
+	addJournalFlag(ivsPutMetadataCmd)
+	addChannelFlag(ivsOscBridgeCmd)
+	addChannelFlag(ivsPutMetadataCmd)
 	rootCmd.AddCommand(ivsOscBridgeCmd)
 	rootCmd.AddCommand(ivsPutMetadataCmd)
 }
@@ -0,0 +1,526 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ivs"
+	"github.com/muesli/coral"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var ivsChannelCmd = &coral.Command{
+	Use:   "ivs-channel <command>",
+	Short: "Create and manage IVS channels.",
+	Long: `Create, list, delete and rotate the stream key of IVS channels. Each
+channel's ARN, ingest endpoint, stream key and playback URL are saved in the
+vbs config file under ivs_channels.<name>, so the other IVS commands can take
+--channel <name> instead of an ARN.`,
+	Example: `  vbs ivs-channel create sunday --latency low
+  vbs ivs-put --channel sunday '{"song":3}'
+  vbs ivs-channel rotate-key sunday`,
+}
+
+var ivsChannelCreateCmd = &coral.Command{
+	Use:   "create <name>",
+	Short: "Create a channel and save it as a profile.",
+	Long: `Create an IVS channel named <name> with a stream key, and save its ingest
+and playback details in the config file under ivs_channels.<name>.`,
+	Example: "  vbs ivs-channel create sunday --latency low --type STANDARD",
+	Run:     ivsChannelCreate,
+	Args:    coral.ExactArgs(1),
+}
+
+var ivsChannelListCmd = &coral.Command{
+	Use:   "list",
+	Short: "List channels in the account and their saved profiles.",
+	Run:   ivsChannelList,
+	Args:  coral.NoArgs,
+}
+
+var ivsChannelDeleteCmd = &coral.Command{
+	Use:   "delete <name>",
+	Short: "Delete a channel and its saved profile.",
+	Long: `Delete the IVS channel saved as <name> and remove the profile. This cannot
+be undone, so --yes is required.`,
+	Example: "  vbs ivs-channel delete sunday --yes",
+	Run:     ivsChannelDelete,
+	Args:    coral.ExactArgs(1),
+}
+
+var ivsChannelRotateKeyCmd = &coral.Command{
+	Use:   "rotate-key <name>",
+	Short: "Replace a channel's stream key.",
+	Long: `Delete the stream key of the channel saved as <name>, create a new one and
+save it. Encoders using the old key are disconnected.`,
+	Example: "  vbs ivs-channel rotate-key sunday",
+	Run:     ivsChannelRotateKey,
+	Args:    coral.ExactArgs(1),
+}
+
+// ivsChannelAPI is the part of the IVS API used to manage channels.
+type ivsChannelAPI interface {
+	CreateChannel(*ivs.CreateChannelInput) (*ivs.CreateChannelOutput, error)
+	ListChannels(*ivs.ListChannelsInput) (*ivs.ListChannelsOutput, error)
+	DeleteChannel(*ivs.DeleteChannelInput) (*ivs.DeleteChannelOutput, error)
+	ListStreamKeys(*ivs.ListStreamKeysInput) (*ivs.ListStreamKeysOutput, error)
+	CreateStreamKey(*ivs.CreateStreamKeyInput) (*ivs.CreateStreamKeyOutput, error)
+	DeleteStreamKey(*ivs.DeleteStreamKeyInput) (*ivs.DeleteStreamKeyOutput, error)
+}
+
+// ivsChannelsKey holds the saved channel profiles in the config file.
+const ivsChannelsKey = "ivs_channels"
+
+// ivsChannelProfile is a channel saved in the config file.
+type ivsChannelProfile struct {
+	ARN            string `mapstructure:"arn" yaml:"arn"`
+	IngestEndpoint string `mapstructure:"ingest_endpoint" yaml:"ingest_endpoint"`
+	StreamKey      string `mapstructure:"stream_key" yaml:"stream_key"`
+	PlaybackURL    string `mapstructure:"playback_url" yaml:"playback_url"`
+}
+
+// ingestURL is the RTMPS server address to give an encoder.
+func (p ivsChannelProfile) ingestURL() string {
+	if p.IngestEndpoint == "" {
+		return ""
+	}
+
+	return "rtmps://" + p.IngestEndpoint + ":443/app/"
+}
+
+// profileNamePattern keeps names usable as config keys, which are case
+// insensitive and split on dots.
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func checkProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("channel name %q must be lower case letters, digits, - and _", name)
+	}
+
+	return nil
+}
+
+// ivsChannelProfiles reads the saved profiles.
+func ivsChannelProfiles() (map[string]ivsChannelProfile, error) {
+	profiles := map[string]ivsChannelProfile{}
+	if err := viper.UnmarshalKey(ivsChannelsKey, &profiles); err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", ivsChannelsKey, err)
+	}
+
+	return profiles, nil
+}
+
+// loadIVSChannel finds the profile saved as name.
+func loadIVSChannel(name string) (ivsChannelProfile, error) {
+	profiles, err := ivsChannelProfiles()
+	if err != nil {
+		return ivsChannelProfile{}, err
+	}
+
+	profile, ok := profiles[strings.ToLower(name)]
+	if !ok || profile.ARN == "" {
+		return ivsChannelProfile{}, fmt.Errorf("no channel saved as %q; see vbs ivs-channel list", name)
+	}
+
+	return profile, nil
+}
+
+// addChannelFlag lets cmd take --channel <name> in place of an ARN argument.
+func addChannelFlag(cmd *coral.Command) {
+	cmd.Flags().String("channel", "", "Saved channel to use instead of an ARN argument (see ivs-channel)")
+}
+
+// channelArgs accepts n arguments, the first being a channel ARN, or n-1 when
+// --channel names a saved channel.
+func channelArgs(n int) coral.PositionalArgs {
+	return func(cmd *coral.Command, args []string) error {
+		if name, _ := cmd.Flags().GetString("channel"); name != "" {
+			return coral.ExactArgs(n-1)(cmd, args)
+		}
+
+		return coral.ExactArgs(n)(cmd, args)
+	}
+}
+
+// channelARN returns the ARN of the --channel profile, or else the first
+// argument, along with the remaining arguments.
+func channelARN(cmd *coral.Command, args []string) (string, []string, error) {
+	name, _ := cmd.Flags().GetString("channel")
+	if name == "" {
+		if len(args) == 0 {
+			return "", args, nil
+		}
+
+		return args[0], args[1:], nil
+	}
+
+	profile, err := loadIVSChannel(name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return profile.ARN, args, nil
+}
+
+// ivsConfigFile is the config file profiles are saved to: the one in use, or
+// the default location when there is none yet.
+func ivsConfigFile() (string, error) {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not locate config dir: %w", err)
+	}
+
+	return filepath.Join(configDir, "vbs", "config.yaml"), nil
+}
+
+// saveIVSChannel writes profile under ivs_channels.<name> in the config file
+// at path, or removes it when profile is nil. The rest of the file, comments
+// included, is left as it was. The file holds stream keys, so it is written
+// readable only by its owner.
+func saveIVSChannel(path, name string, profile *ivsChannelProfile) error {
+	var doc yaml.Node
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("could not read config: %w", err)
+	default:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("could not parse config %s: %w", path, err)
+		}
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config %s is not a mapping", path)
+	}
+
+	channels := yamlMapValue(root, ivsChannelsKey)
+	if channels == nil {
+		if profile == nil {
+			return nil
+		}
+		channels = &yaml.Node{Kind: yaml.MappingNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: ivsChannelsKey}, channels)
+	}
+
+	yamlMapDelete(channels, name)
+	if profile != nil {
+		var value yaml.Node
+		if err := value.Encode(profile); err != nil {
+			return fmt.Errorf("could not encode channel: %w", err)
+		}
+		channels.Content = append(channels.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, &value)
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("could not encode config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("could not create config dir: %w", err)
+	}
+
+	if err := os.WriteFile(path, out, 0o600); err != nil {
+		return fmt.Errorf("could not write config: %w", err)
+	}
+
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(path, 0o600); err != nil {
+		return fmt.Errorf("could not restrict config permissions: %w", err)
+	}
+
+	return nil
+}
+
+// yamlMapValue returns the value for key in mapping node m.
+func yamlMapValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// yamlMapDelete removes key from mapping node m.
+func yamlMapDelete(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// rememberIVSChannel saves profile as name in the config file, or removes it
+// when profile is nil, and returns the file's path.
+func rememberIVSChannel(name string, profile *ivsChannelProfile) (string, error) {
+	path, err := ivsConfigFile()
+	if err != nil {
+		return "", err
+	}
+
+	return path, saveIVSChannel(path, name, profile)
+}
+
+// createIVSChannel creates a channel named name and returns its profile.
+func createIVSChannel(api ivsChannelAPI, name, latency, channelType string) (ivsChannelProfile, error) {
+	input := &ivs.CreateChannelInput{Name: aws.String(name)}
+	if latency != "" {
+		input.LatencyMode = aws.String(strings.ToUpper(latency))
+	}
+	if channelType != "" {
+		input.Type = aws.String(strings.ToUpper(channelType))
+	}
+
+	out, err := api.CreateChannel(input)
+	if err != nil {
+		return ivsChannelProfile{}, fmt.Errorf("could not create channel: %w", err)
+	}
+
+	profile := ivsChannelProfile{}
+	if out.Channel != nil {
+		profile.ARN = aws.StringValue(out.Channel.Arn)
+		profile.IngestEndpoint = aws.StringValue(out.Channel.IngestEndpoint)
+		profile.PlaybackURL = aws.StringValue(out.Channel.PlaybackUrl)
+	}
+	if out.StreamKey != nil {
+		profile.StreamKey = aws.StringValue(out.StreamKey.Value)
+	}
+
+	return profile, nil
+}
+
+// rotateIVSStreamKey deletes the channel's stream keys and creates a new one.
+// IVS allows one key per channel, so the old key goes first.
+func rotateIVSStreamKey(api ivsChannelAPI, arn string) (string, error) {
+	keys, err := api.ListStreamKeys(&ivs.ListStreamKeysInput{ChannelArn: aws.String(arn)})
+	if err != nil {
+		return "", fmt.Errorf("could not list stream keys: %w", err)
+	}
+
+	for _, k := range keys.StreamKeys {
+		if _, err := api.DeleteStreamKey(&ivs.DeleteStreamKeyInput{Arn: k.Arn}); err != nil {
+			return "", fmt.Errorf("could not delete stream key: %w", err)
+		}
+	}
+
+	out, err := api.CreateStreamKey(&ivs.CreateStreamKeyInput{ChannelArn: aws.String(arn)})
+	if err != nil {
+		return "", fmt.Errorf("could not create stream key: %w", err)
+	}
+
+	if out.StreamKey == nil {
+		return "", fmt.Errorf("IVS returned no stream key")
+	}
+
+	return aws.StringValue(out.StreamKey.Value), nil
+}
+
+// listIVSChannels writes the account's channels with the profile each is
+// saved as, followed by saved profiles whose channel no longer exists.
+func listIVSChannels(w io.Writer, api ivsChannelAPI, profiles map[string]ivsChannelProfile) error {
+	saved := map[string]string{}
+	for name, p := range profiles {
+		saved[p.ARN] = name
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "PROFILE\tNAME\tLATENCY\tTYPE\tARN"); err != nil {
+		return fmt.Errorf("could not write table header: %w", err)
+	}
+
+	input := &ivs.ListChannelsInput{}
+	for {
+		out, err := api.ListChannels(input)
+		if err != nil {
+			return fmt.Errorf("could not list channels: %w", err)
+		}
+
+		for _, c := range out.Channels {
+			arn := aws.StringValue(c.Arn)
+			profile := saved[arn]
+			delete(saved, arn)
+
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", orDash(profile), orDash(aws.StringValue(c.Name)),
+				strings.ToLower(aws.StringValue(c.LatencyMode)), strings.ToLower(aws.StringValue(c.Type)), arn); err != nil {
+				return fmt.Errorf("could not write table row: %w", err)
+			}
+		}
+
+		if aws.StringValue(out.NextToken) == "" {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+
+	missing := make([]string, 0, len(saved))
+	for arn := range saved {
+		missing = append(missing, arn)
+	}
+	sort.Strings(missing)
+	for _, arn := range missing {
+		if _, err := fmt.Fprintf(tw, "%s\t(not found)\t-\t-\t%s\n", saved[arn], arn); err != nil {
+			return fmt.Errorf("could not write table row: %w", err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("could not flush table: %w", err)
+	}
+
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+func printIVSChannel(name string, p ivsChannelProfile) {
+	fmt.Printf("Channel:      %s\n", name)
+	fmt.Printf("ARN:          %s\n", p.ARN)
+	fmt.Printf("Ingest:       %s\n", p.ingestURL())
+	fmt.Printf("Stream key:   %s\n", p.StreamKey)
+	fmt.Printf("Playback URL: %s\n", p.PlaybackURL)
+}
+
+func ivsChannelCreate(cmd *coral.Command, args []string) {
+	name := args[0]
+	if err := checkProfileName(name); err != nil {
+		log.Fatal().Err(err).Msg("Invalid channel name")
+	}
+
+	if _, err := loadIVSChannel(name); err == nil {
+		log.Fatal().Msgf("A channel is already saved as %q; delete it or pick another name", name)
+	}
+
+	latency, _ := cmd.Flags().GetString("latency")
+	channelType, _ := cmd.Flags().GetString("type")
+
+	api := ivs.New(session.Must(session.NewSession()))
+	profile, err := createIVSChannel(api, name, latency, channelType)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not create channel")
+	}
+
+	path, err := rememberIVSChannel(name, &profile)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Created %s but could not save it", profile.ARN)
+	}
+
+	printIVSChannel(name, profile)
+	log.Info().Msgf("Saved channel %s to %s", name, path)
+}
+
+func ivsChannelList(_ *coral.Command, _ []string) {
+	profiles, err := ivsChannelProfiles()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not read saved channels")
+	}
+
+	api := ivs.New(session.Must(session.NewSession()))
+	if err := listIVSChannels(os.Stdout, api, profiles); err != nil {
+		log.Fatal().Err(err).Msg("Could not list channels")
+	}
+}
+
+func ivsChannelDelete(cmd *coral.Command, args []string) {
+	name := strings.ToLower(args[0])
+
+	profile, err := loadIVSChannel(name)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not find channel")
+	}
+
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+		log.Fatal().Msgf("This deletes %s from IVS; run again with --yes to confirm", profile.ARN)
+	}
+
+	api := ivs.New(session.Must(session.NewSession()))
+	if _, err := api.DeleteChannel(&ivs.DeleteChannelInput{Arn: aws.String(profile.ARN)}); err != nil {
+		log.Fatal().Err(err).Msg("Could not delete channel")
+	}
+
+	path, err := rememberIVSChannel(name, nil)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Deleted %s but could not remove its profile", profile.ARN)
+	}
+
+	log.Info().Msgf("Deleted channel %s and removed it from %s", name, path)
+}
+
+func ivsChannelRotateKey(_ *coral.Command, args []string) {
+	name := strings.ToLower(args[0])
+
+	profile, err := loadIVSChannel(name)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not find channel")
+	}
+
+	api := ivs.New(session.Must(session.NewSession()))
+	profile.StreamKey, err = rotateIVSStreamKey(api, profile.ARN)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not rotate stream key")
+	}
+
+	path, err := rememberIVSChannel(name, &profile)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Rotated the key to %s but could not save it", profile.StreamKey)
+	}
+
+	printIVSChannel(name, profile)
+	log.Info().Msgf("Saved new stream key to %s", path)
+}
+
+func init() {
+	ivsChannelCreateCmd.Flags().String("latency", "low", "Latency mode: low or normal")
+	ivsChannelCreateCmd.Flags().String("type", "", "Channel type, such as standard or basic (IVS default if not set)")
+	ivsChannelDeleteCmd.Flags().Bool("yes", false, "Confirm deleting the channel")
+
+	ivsChannelCmd.AddCommand(ivsChannelCreateCmd)
+	ivsChannelCmd.AddCommand(ivsChannelListCmd)
+	ivsChannelCmd.AddCommand(ivsChannelDeleteCmd)
+	ivsChannelCmd.AddCommand(ivsChannelRotateKeyCmd)
+	rootCmd.AddCommand(ivsChannelCmd)
+}
@@ -0,0 +1,274 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ivs"
+	"github.com/muesli/coral"
+	"github.com/spf13/viper"
+)
+
+// fakeIVSChannels records channel management calls.
+type fakeIVSChannels struct {
+	calls    []string
+	channels [][]*ivs.ChannelSummary
+	keys     []string
+}
+
+func (f *fakeIVSChannels) CreateChannel(in *ivs.CreateChannelInput) (*ivs.CreateChannelOutput, error) {
+	f.calls = append(f.calls, "CreateChannel "+aws.StringValue(in.Name)+" "+aws.StringValue(in.LatencyMode)+" "+aws.StringValue(in.Type))
+
+	return &ivs.CreateChannelOutput{
+		Channel: &ivs.Channel{
+			Arn:            aws.String("arn:aws:ivs:us-west-2:1:channel/new"),
+			IngestEndpoint: aws.String("abc.global-contribute.live-video.net"),
+			PlaybackUrl:    aws.String("https://abc.m3u8"),
+		},
+		StreamKey: &ivs.StreamKey{Value: aws.String("sk_secret")},
+	}, nil
+}
+
+func (f *fakeIVSChannels) ListChannels(in *ivs.ListChannelsInput) (*ivs.ListChannelsOutput, error) {
+	page := 0
+	if in.NextToken != nil {
+		page = 1
+	}
+	f.calls = append(f.calls, "ListChannels")
+
+	out := &ivs.ListChannelsOutput{Channels: f.channels[page]}
+	if page+1 < len(f.channels) {
+		out.NextToken = aws.String("more")
+	}
+
+	return out, nil
+}
+
+func (f *fakeIVSChannels) DeleteChannel(in *ivs.DeleteChannelInput) (*ivs.DeleteChannelOutput, error) {
+	f.calls = append(f.calls, "DeleteChannel "+aws.StringValue(in.Arn))
+
+	return &ivs.DeleteChannelOutput{}, nil
+}
+
+func (f *fakeIVSChannels) ListStreamKeys(in *ivs.ListStreamKeysInput) (*ivs.ListStreamKeysOutput, error) {
+	f.calls = append(f.calls, "ListStreamKeys "+aws.StringValue(in.ChannelArn))
+
+	out := &ivs.ListStreamKeysOutput{}
+	for _, k := range f.keys {
+		out.StreamKeys = append(out.StreamKeys, &ivs.StreamKeySummary{Arn: aws.String(k)})
+	}
+
+	return out, nil
+}
+
+func (f *fakeIVSChannels) CreateStreamKey(in *ivs.CreateStreamKeyInput) (*ivs.CreateStreamKeyOutput, error) {
+	f.calls = append(f.calls, "CreateStreamKey "+aws.StringValue(in.ChannelArn))
+
+	return &ivs.CreateStreamKeyOutput{StreamKey: &ivs.StreamKey{Value: aws.String("sk_rotated")}}, nil
+}
+
+func (f *fakeIVSChannels) DeleteStreamKey(in *ivs.DeleteStreamKeyInput) (*ivs.DeleteStreamKeyOutput, error) {
+	f.calls = append(f.calls, "DeleteStreamKey "+aws.StringValue(in.Arn))
+
+	return &ivs.DeleteStreamKeyOutput{}, nil
+}
+
+func TestCreateIVSChannel(t *testing.T) {
+	api := &fakeIVSChannels{}
+
+	got, err := createIVSChannel(api, "sunday", "low", "standard")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ivsChannelProfile{
+		ARN:            "arn:aws:ivs:us-west-2:1:channel/new",
+		IngestEndpoint: "abc.global-contribute.live-video.net",
+		StreamKey:      "sk_secret",
+		PlaybackURL:    "https://abc.m3u8",
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got.ingestURL() != "rtmps://abc.global-contribute.live-video.net:443/app/" {
+		t.Errorf("ingest URL = %s", got.ingestURL())
+	}
+	if !reflect.DeepEqual(api.calls, []string{"CreateChannel sunday LOW STANDARD"}) {
+		t.Errorf("calls = %v", api.calls)
+	}
+}
+
+func TestRotateIVSStreamKey(t *testing.T) {
+	api := &fakeIVSChannels{keys: []string{"arn:key/old"}}
+
+	key, err := rotateIVSStreamKey(api, "arn:c")
+	if err != nil || key != "sk_rotated" {
+		t.Fatalf("key = %q, err = %v", key, err)
+	}
+
+	want := []string{"ListStreamKeys arn:c", "DeleteStreamKey arn:key/old", "CreateStreamKey arn:c"}
+	if !reflect.DeepEqual(api.calls, want) {
+		t.Errorf("calls = %v, want the old key deleted before the new one is made", api.calls)
+	}
+}
+
+func TestListIVSChannels(t *testing.T) {
+	api := &fakeIVSChannels{channels: [][]*ivs.ChannelSummary{
+		{{Arn: aws.String("arn:a"), Name: aws.String("sunday"), LatencyMode: aws.String("LOW"), Type: aws.String("STANDARD")}},
+		{{Arn: aws.String("arn:b"), Name: aws.String("")}},
+	}}
+	profiles := map[string]ivsChannelProfile{
+		"main": {ARN: "arn:a"},
+		"gone": {ARN: "arn:z"},
+	}
+
+	var out bytes.Buffer
+	if err := listIVSChannels(&out, api, profiles); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("want header and 3 rows, got:\n%s", out.String())
+	}
+	for i, want := range [][]string{
+		{"PROFILE", "NAME", "LATENCY", "TYPE", "ARN"},
+		{"main", "sunday", "low", "standard", "arn:a"},
+		{"-", "-", "arn:b"},
+		{"gone", "(not found)", "arn:z"},
+	} {
+		fields := strings.Fields(lines[i])
+		for _, w := range want {
+			if !strings.Contains(strings.Join(fields, " "), w) {
+				t.Errorf("line %d %q is missing %q", i, lines[i], w)
+			}
+		}
+	}
+	if api.calls[len(api.calls)-1] != "ListChannels" || len(api.calls) != 2 {
+		t.Errorf("should page through channels, calls = %v", api.calls)
+	}
+}
+
+func TestSaveIVSChannel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vbs", "config.yaml")
+
+	original := "# my settings\nivs_port: \"4427\" # OSC\nivs_channels:\n  old:\n    arn: arn:old\n"
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	profile := ivsChannelProfile{ARN: "arn:new", StreamKey: "sk_1"}
+	if err := saveIVSChannel(path, "sunday", &profile); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveIVSChannel(path, "old", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, want := range []string{"# my settings", "# OSC", "sunday:", "arn: arn:new", "stream_key: sk_1"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("config is missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "arn:old") {
+		t.Errorf("old profile should be removed:\n%s", text)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("config holding stream keys has mode %v", info.Mode().Perm())
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.GetString("ivs_channels.sunday.stream_key"); got != "sk_1" {
+		t.Errorf("viper reads stream key %q", got)
+	}
+
+	fresh := filepath.Join(t.TempDir(), "new", "config.yaml")
+	if err := saveIVSChannel(fresh, "sunday", &profile); err != nil {
+		t.Fatalf("should create a missing config file: %v", err)
+	}
+}
+
+func TestChannelARN(t *testing.T) {
+	viper.Set(ivsChannelsKey, map[string]interface{}{
+		"sunday": map[string]interface{}{"arn": "arn:sunday"},
+	})
+	t.Cleanup(func() { viper.Set(ivsChannelsKey, nil) })
+
+	newCmd := func(channel string) *coral.Command {
+		cmd := &coral.Command{}
+		addChannelFlag(cmd)
+		if channel != "" {
+			if err := cmd.Flags().Set("channel", channel); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		return cmd
+	}
+
+	arn, rest, err := channelARN(newCmd(""), []string{"arn:given", "payload"})
+	if err != nil || arn != "arn:given" || !reflect.DeepEqual(rest, []string{"payload"}) {
+		t.Errorf("without --channel got %q %v %v", arn, rest, err)
+	}
+
+	arn, rest, err = channelARN(newCmd("Sunday"), []string{"payload"})
+	if err != nil || arn != "arn:sunday" || !reflect.DeepEqual(rest, []string{"payload"}) {
+		t.Errorf("with --channel got %q %v %v", arn, rest, err)
+	}
+
+	if _, _, err := channelARN(newCmd("missing"), nil); err == nil || !strings.Contains(err.Error(), "no channel saved") {
+		t.Errorf("unknown channel err = %v", err)
+	}
+
+	if err := channelArgs(2)(newCmd("sunday"), []string{"payload"}); err != nil {
+		t.Errorf("--channel should stand in for the ARN: %v", err)
+	}
+	if err := channelArgs(2)(newCmd(""), []string{"payload"}); err == nil {
+		t.Error("without --channel the ARN is required")
+	}
+
+	if err := checkProfileName("sunday-2"); err != nil {
+		t.Error(err)
+	}
+	for _, bad := range []string{"Sunday", "a.b", "", "-x"} {
+		if err := checkProfileName(bad); err == nil {
+			t.Errorf("%q should be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,466 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/muesli/coral"
+	"github.com/rs/zerolog/log"
+)
+
+var ivsExportMetadataCmd = &coral.Command{
+	Use:   "ivs-export-metadata <journal.jsonl> [recording.mp4]",
+	Short: "Turn a metadata journal into a track or chapters for the recording.",
+	Long: `Read the journal written by ivs-bridge, ivs-put or ivs-timeline with
+--journal and export the payloads sent during one stream, timed from the
+start of the stream:
+
+  vtt       a WebVTT metadata track, one cue per payload (the default)
+  youtube   chapter text to paste into a YouTube description
+  json      a timeline of {"at", "payload"} that ivs-timeline can replay
+
+Given a recording, the payloads become chapters muxed into a copy of it with
+ffmpeg instead, written to --out or <recording>_chapters.mp4.
+
+Chapter titles come from a "title", "name", "text" or "label" field of JSON
+payloads, or from the payload's values, or the payload itself.
+
+By default the journal entries for the most recent stream on the channel of
+the last payload are exported. --channel picks another channel, and --start
+times payloads from when the recording began instead, for streams whose
+start was not journaled.`,
+	Example: `vbs ivs-export-metadata sunday.jsonl > sunday.vtt
+vbs ivs-export-metadata --format youtube --channel sunday sunday.jsonl
+vbs ivs-export-metadata --start 2026-10-18T09:00:00-05:00 sunday.jsonl recording.mp4`,
+	Run:  ivsExportMetadata,
+	Args: coral.RangeArgs(1, 2), //nolint:gomnd // journal and optional recording
+}
+
+var (
+	exportFormat  string
+	exportOut     string
+	exportChannel string
+	exportStart   string
+)
+
+const (
+	// exportLastCue is how long the last WebVTT cue lasts.
+	exportLastCue = 10.0
+	// youtubeMinChapter is the shortest chapter YouTube accepts, in seconds.
+	youtubeMinChapter = 10.0
+)
+
+// journalTrack picks the payloads sent to channel during one stream, timed
+// from its start. An empty channel means the channel of the last entry. A
+// zero start means the most recent journaled stream start, or the first
+// payload when no stream start was journaled.
+func journalTrack(entries []journalEntry, channel string, start time.Time) ([]timelineEntry, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("the journal is empty")
+	}
+
+	if channel == "" {
+		channel = entries[len(entries)-1].Channel
+	}
+
+	var sent []journalEntry
+	for _, e := range entries {
+		if e.Channel == channel {
+			sent = append(sent, e)
+		}
+	}
+
+	if len(sent) == 0 {
+		return nil, fmt.Errorf("nothing was journaled for channel %s", channel)
+	}
+
+	var session *time.Time
+	if start.IsZero() {
+		for _, e := range sent {
+			if e.StreamStart != nil && (session == nil || e.StreamStart.After(*session)) {
+				session = e.StreamStart
+			}
+		}
+
+		if session == nil {
+			start = sent[0].Time
+			log.Warn().Msgf("No stream start in the journal, timing from the first payload at %s; use --start to set it", start.Local().Format(time.RFC3339))
+		}
+	}
+
+	var track []timelineEntry
+	for _, e := range sent {
+		switch {
+		case session != nil && e.StreamStart != nil && e.StreamStart.Equal(*session):
+			track = append(track, timelineEntry{at: e.Time.Sub(*session).Seconds(), payload: e.Payload})
+		case session == nil && !e.Time.Before(start):
+			track = append(track, timelineEntry{at: e.Time.Sub(start).Seconds(), payload: e.Payload})
+		}
+	}
+
+	if len(track) == 0 {
+		return nil, fmt.Errorf("nothing was journaled for channel %s after %s", channel, start.Local().Format(time.RFC3339))
+	}
+
+	sort.SliceStable(track, func(i, j int) bool { return track[i].at < track[j].at })
+
+	return track, nil
+}
+
+// payloadTitle makes a chapter title from a payload.
+func payloadTitle(payload string) string {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+		return strings.Join(strings.Fields(payload), " ")
+	}
+
+	for _, key := range []string{"title", "name", "text", "label"} {
+		if v, ok := fields[key].(string); ok && v != "" {
+			return v
+		}
+	}
+
+	// otherwise the scalar values in order, so {"type":"song","n":3} is "song 3"
+	var values []string
+	dec := json.NewDecoder(strings.NewReader(payload))
+	_, _ = dec.Token() // {
+	for dec.More() {
+		if _, err := dec.Token(); err != nil { // key
+			break
+		}
+
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			break
+		}
+
+		switch v := v.(type) {
+		case string, float64, bool:
+			values = append(values, fmt.Sprint(v))
+		}
+	}
+
+	if len(values) == 0 {
+		return payload
+	}
+
+	return strings.Join(values, " ")
+}
+
+// exportChapters makes chapters from a track: the first starts at zero, a
+// payload repeating the current title is dropped, and a payload within
+// minLength of the previous chapter renames it, as when a wrong song number
+// was corrected.
+func exportChapters(track []timelineEntry, minLength float64) []playChapter {
+	var chapters []playChapter
+
+	for _, e := range track {
+		title := payloadTitle(e.payload)
+		last := len(chapters) - 1
+
+		switch {
+		case last >= 0 && chapters[last].title == title:
+		case last >= 0 && e.at-chapters[last].start < minLength:
+			chapters[last].title = title
+		default:
+			chapters = append(chapters, playChapter{start: e.at, title: title})
+		}
+	}
+
+	if len(chapters) > 0 && chapters[0].start > 0 {
+		if chapters[0].start < minLength {
+			chapters[0].start = 0
+		} else {
+			chapters = append([]playChapter{{start: 0, title: "Start"}}, chapters...)
+		}
+	}
+
+	return chapters
+}
+
+// vttTimestamp renders seconds as hh:mm:ss.ttt.
+func vttTimestamp(seconds float64) string {
+	ms := int64(math.Round(math.Max(seconds, 0) * 1000)) //nolint:gomnd // milliseconds
+
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// writeWebVTT writes a metadata track with a cue per payload, each lasting
+// until the next.
+func writeWebVTT(w io.Writer, track []timelineEntry) error {
+	var b strings.Builder
+
+	b.WriteString("WEBVTT\nKind: metadata\n")
+
+	for i, e := range track {
+		end := e.at + exportLastCue
+		if i+1 < len(track) {
+			end = track[i+1].at
+		}
+		end = math.Max(end, e.at+0.001) //nolint:gomnd // cues must not be empty
+
+		// a cue ends at a blank line and its timing is marked by -->
+		text := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "-->", "--&gt;").Replace(e.payload)
+
+		fmt.Fprintf(&b, "\n%d\n%s --> %s\n%s\n", i+1, vttTimestamp(e.at), vttTimestamp(end), text)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// youtubeTimestamp renders seconds as m:ss, or h:mm:ss from an hour.
+func youtubeTimestamp(seconds float64) string {
+	s := int(seconds)
+	if s >= 3600 { //nolint:gomnd // an hour
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// writeYouTubeChapters writes chapter lines for a YouTube description.
+func writeYouTubeChapters(w io.Writer, track []timelineEntry) error {
+	chapters := exportChapters(track, youtubeMinChapter)
+	if len(chapters) < 3 { //nolint:gomnd // YouTube's minimum
+		log.Warn().Msgf("YouTube needs at least 3 chapters, the journal has %d", len(chapters))
+	}
+
+	var b strings.Builder
+	for _, c := range chapters {
+		fmt.Fprintf(&b, "%s %s\n", youtubeTimestamp(c.start), c.title)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// writeTrackJSON writes the track as a timeline ivs-timeline can replay.
+func writeTrackJSON(w io.Writer, track []timelineEntry) error {
+	type entry struct {
+		At      float64 `json:"at"`
+		Payload string  `json:"payload"`
+	}
+
+	entries := make([]entry, 0, len(track))
+	for _, e := range track {
+		entries = append(entries, entry{At: math.Round(e.at*1000) / 1000, Payload: e.payload}) //nolint:gomnd // milliseconds
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(entries)
+}
+
+// ffmetadataEscape escapes the characters special to ffmpeg metadata files.
+func ffmetadataEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "=", `\=`, ";", `\;`, "#", `\#`, "\n", `\`+"\n").Replace(s)
+}
+
+// writeFFMetadata writes chapters in ffmpeg's metadata format, the last one
+// ending at duration. Chapters after duration are left out.
+func writeFFMetadata(w io.Writer, chapters []playChapter, duration float64) error {
+	var b bytes.Buffer
+
+	b.WriteString(";FFMETADATA1\n")
+
+	for i, c := range chapters {
+		end := duration
+		if i+1 < len(chapters) {
+			end = math.Min(chapters[i+1].start, duration)
+		}
+		if c.start >= end {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n[CHAPTER]\nTIMEBASE=1/1000\nSTART=%d\nEND=%d\ntitle=%s\n",
+			int64(math.Round(c.start*1000)), int64(math.Round(end*1000)), ffmetadataEscape(c.title)) //nolint:gomnd // milliseconds
+	}
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// muxChaptersArgs builds the ffmpeg command line that copies recording to out
+// with the chapters in metadata, keeping every stream and its metadata.
+func muxChaptersArgs(recording, metadata, out string) []string {
+	return []string{
+		"-loglevel", "error",
+		"-i", recording,
+		"-i", metadata,
+		"-map", "0",
+		"-map_metadata", "0",
+		"-map_chapters", "1",
+		"-codec", "copy",
+		"-y", // overwrite output files
+		out,
+	}
+}
+
+// muxChapters writes a copy of recording to out with chapters from track.
+func muxChapters(recording, out string, track []timelineEntry) error {
+	duration, err := probeDuration(recording)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := os.CreateTemp("", "vbs-chapters-*.txt")
+	if err != nil {
+		return fmt.Errorf("could not create chapter metadata: %w", err)
+	}
+	defer os.Remove(metadata.Name())
+
+	err = writeFFMetadata(metadata, exportChapters(track, 0), duration)
+	if cerr := metadata.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("could not write chapter metadata: %w", err)
+	}
+
+	output, err := exec.Command("ffmpeg", muxChaptersArgs(recording, metadata.Name(), out)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg could not mux chapters: %w: %s", err, output)
+	}
+
+	return nil
+}
+
+// parseExportStart reads --start as RFC 3339, or as local date and time.
+func parseExportStart(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("could not parse start time %q, want 2006-01-02T15:04:05-07:00 or 2006-01-02 15:04:05", s)
+}
+
+func ivsExportMetadata(cmd *coral.Command, args []string) {
+	start, err := parseExportStart(exportStart)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid --start")
+	}
+
+	channel := exportChannel
+	if channel != "" && !strings.HasPrefix(channel, "arn:") {
+		profile, err := loadIVSChannel(channel)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Could not find channel")
+		}
+		channel = profile.ARN
+	}
+
+	f, err := os.Open(resolveInputPath(args[0]))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not open journal")
+	}
+	entries, err := readJournal(f)
+	f.Close()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not read journal")
+	}
+
+	track, err := journalTrack(entries, channel, start)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Nothing to export")
+	}
+
+	if len(args) > 1 {
+		exportMuxed(resolveInputPath(args[1]), track)
+
+		return
+	}
+
+	write := map[string]func(io.Writer, []timelineEntry) error{
+		"vtt":     writeWebVTT,
+		"youtube": writeYouTubeChapters,
+		"json":    writeTrackJSON,
+	}[strings.ToLower(exportFormat)]
+	if write == nil {
+		log.Fatal().Msgf("Unknown export format %q, use vtt, youtube or json", exportFormat)
+	}
+
+	w := io.Writer(os.Stdout)
+	if exportOut != "" {
+		out, err := os.Create(resolveInputPath(exportOut))
+		if err != nil {
+			log.Fatal().Err(err).Msg("Could not create output file")
+		}
+		defer out.Close()
+		w = out
+	}
+
+	if err := write(w, track); err != nil {
+		log.Fatal().Err(err).Msg("Could not write export")
+	}
+}
+
+func exportMuxed(recording string, track []timelineEntry) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		log.Fatal().Err(err).Msg("Could not find ffmpeg. Please install ffmpeg.")
+	}
+
+	out := resolveInputPath(exportOut)
+	if out == "" {
+		out = strings.TrimSuffix(recording, filepath.Ext(recording)) + "_chapters" + filepath.Ext(recording)
+	}
+
+	absOut, _ := filepath.Abs(out)
+	absRecording, _ := filepath.Abs(recording)
+	if absOut == absRecording {
+		log.Fatal().Msg("--out must not be the recording itself")
+	}
+
+	if err := muxChapters(recording, out, track); err != nil {
+		log.Fatal().Err(err).Msg("Could not add chapters to the recording")
+	}
+
+	fmt.Println(out)
+}
+
+func init() {
+	ivsExportMetadataCmd.Flags().StringVar(&exportFormat, "format", "vtt", "Export format: vtt, youtube or json")
+	ivsExportMetadataCmd.Flags().StringVarP(&exportOut, "out", "o", "", "File to write, instead of standard output or <recording>_chapters.mp4")
+	ivsExportMetadataCmd.Flags().StringVar(&exportChannel, "channel", "", "Channel ARN or saved channel name to export")
+	ivsExportMetadataCmd.Flags().StringVar(&exportStart, "start", "", "When the recording began, if the journal has no stream start")
+
+	rootCmd.AddCommand(ivsExportMetadataCmd)
+}
@@ -0,0 +1,223 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func journaled(at time.Time, start *time.Time, channel, payload string) journalEntry {
+	return journalEntry{Time: at, StreamStart: start, Channel: channel, Payload: payload}
+}
+
+func TestJournalTrack(t *testing.T) {
+	base := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	rehearsal, service := base, base.Add(time.Hour)
+
+	entries := []journalEntry{
+		journaled(rehearsal.Add(10*time.Second), &rehearsal, "arn:main", "rehearsal"),
+		journaled(service.Add(90*time.Second), &service, "arn:main", "song 2"),
+		journaled(service.Add(30*time.Second), &service, "arn:main", "welcome"),
+		journaled(service.Add(40*time.Second), &service, "arn:other", "elsewhere"),
+		journaled(service.Add(100*time.Second), nil, "arn:main", "offline"),
+	}
+
+	cases := []struct {
+		name    string
+		channel string
+		start   time.Time
+		want    []timelineEntry
+	}{
+		{"latest stream", "", time.Time{}, []timelineEntry{{30, "welcome"}, {90, "song 2"}}},
+		{"other channel", "arn:other", time.Time{}, []timelineEntry{{40, "elsewhere"}}},
+		{"given start", "arn:main", service.Add(60 * time.Second), []timelineEntry{{30, "song 2"}, {40, "offline"}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := journalTrack(entries, tc.channel, tc.start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	noStart := []journalEntry{
+		journaled(base.Add(5*time.Second), nil, "arn:main", "one"),
+		journaled(base.Add(20*time.Second), nil, "arn:main", "two"),
+	}
+	if got, err := journalTrack(noStart, "", time.Time{}); err != nil || !reflect.DeepEqual(got, []timelineEntry{{0, "one"}, {15, "two"}}) {
+		t.Errorf("without a stream start, time from the first payload: %v %v", got, err)
+	}
+
+	if _, err := journalTrack(entries, "arn:missing", time.Time{}); err == nil {
+		t.Error("an unknown channel should fail")
+	}
+	if _, err := journalTrack(nil, "", time.Time{}); err == nil {
+		t.Error("an empty journal should fail")
+	}
+}
+
+func TestPayloadTitle(t *testing.T) {
+	cases := map[string]string{
+		`{"type":"speaker","name":"Ana Díaz"}`: "Ana Díaz",
+		`{"title":"Amazing Grace","n":3}`:      "Amazing Grace",
+		`{"type":"song","n":3}`:                "song 3",
+		`{"list":[1,2]}`:                       `{"list":[1,2]}`,
+		"Amazing Grace,\n verse 1":             "Amazing Grace, verse 1",
+		`42`:                                   "42",
+	}
+
+	for payload, want := range cases {
+		if got := payloadTitle(payload); got != want {
+			t.Errorf("payloadTitle(%q) = %q, want %q", payload, got, want)
+		}
+	}
+}
+
+func TestExportChapters(t *testing.T) {
+	track := []timelineEntry{
+		{95, `{"type":"song","n":2}`},
+		{100, `{"type":"song","n":3}`}, // corrected within 10 seconds
+		{200, `{"type":"song","n":3}`}, // repeated
+		{300, `{"name":"Pastor Kim"}`},
+	}
+
+	want := []playChapter{
+		{start: 0, title: "Start"},
+		{start: 95, title: "song 3"},
+		{start: 300, title: "Pastor Kim"},
+	}
+	if got := exportChapters(track, youtubeMinChapter); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	early := exportChapters([]timelineEntry{{4, "welcome"}, {60, "song"}}, youtubeMinChapter)
+	if early[0].start != 0 || early[0].title != "welcome" {
+		t.Errorf("a first chapter in the opening seconds should move to zero, got %+v", early)
+	}
+
+	var out bytes.Buffer
+	if err := writeYouTubeChapters(&out, append(track, timelineEntry{3725, "Benediction"})); err != nil {
+		t.Fatal(err)
+	}
+	wantText := "0:00 Start\n1:35 song 3\n5:00 Pastor Kim\n1:02:05 Benediction\n"
+	if out.String() != wantText {
+		t.Errorf("youtube chapters:\n%s\nwant:\n%s", out.String(), wantText)
+	}
+}
+
+func TestWriteWebVTT(t *testing.T) {
+	track := []timelineEntry{
+		{1.5, `{"song":3}`},
+		{1.5, "a --> b"},
+		{3725.25, "line one\nline two"},
+	}
+
+	var out bytes.Buffer
+	if err := writeWebVTT(&out, track); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `WEBVTT
+Kind: metadata
+
+1
+00:00:01.500 --> 00:00:01.501
+{"song":3}
+
+2
+00:00:01.500 --> 01:02:05.250
+a --&gt; b
+
+3
+01:02:05.250 --> 01:02:15.250
+line one line two
+`
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestWriteTrackJSON_ReplaysAsTimeline(t *testing.T) {
+	track := []timelineEntry{{12.3456, `{"song":3}`}, {75, "Amazing Grace"}}
+
+	var out bytes.Buffer
+	if err := writeTrackJSON(&out, track); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseTimelineJSON(out.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []timelineEntry{{12.346, `{"song":3}`}, {75, "Amazing Grace"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestWriteFFMetadata(t *testing.T) {
+	chapters := []playChapter{{"Start", 0}, {"Song = 3; #1", 95.5}, {"after the end", 4000}}
+
+	var out bytes.Buffer
+	if err := writeFFMetadata(&out, chapters, 600); err != nil {
+		t.Fatal(err)
+	}
+
+	text := out.String()
+	for _, want := range []string{
+		";FFMETADATA1\n",
+		"START=0\nEND=95500\ntitle=Start\n",
+		"START=95500\nEND=600000\ntitle=Song \\= 3\\; \\#1\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("metadata is missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "after the end") {
+		t.Errorf("a chapter starting after the recording should be left out:\n%s", text)
+	}
+
+	args := muxChaptersArgs("in.mp4", "chapters.txt", "out.mp4")
+	if want := []string{"-map_chapters", "1", "-codec", "copy"}; !strings.Contains(strings.Join(args, " "), strings.Join(want, " ")) {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestParseExportStart(t *testing.T) {
+	got, err := parseExportStart("2026-10-18T09:00:00-05:00")
+	if err != nil || !got.Equal(time.Date(2026, 10, 18, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("RFC 3339 start = %v, %v", got, err)
+	}
+
+	got, err = parseExportStart("2026-10-18 09:00:00")
+	if err != nil || !got.Equal(time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local)) {
+		t.Errorf("local start = %v, %v", got, err)
+	}
+
+	if got, err := parseExportStart(""); err != nil || !got.IsZero() {
+		t.Errorf("no start = %v, %v", got, err)
+	}
+	if _, err := parseExportStart("yesterday"); err == nil {
+		t.Error("a bad start should fail")
+	}
+}
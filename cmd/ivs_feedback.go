@@ -0,0 +1,122 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// OSC addresses the bridge replies on.
+const (
+	ivsAckAddress    = "/vbs/ivsbridge/ack"
+	ivsErrorAddress  = "/vbs/ivsbridge/error"
+	heartbeatAddress = "/vbs/heartbeat"
+	pingAddress      = "/vbs/ping"
+	pongAddress      = "/vbs/pong"
+)
+
+// ivsResultMessage reports the outcome of sending a message on address to
+// arn: /vbs/ivsbridge/ack <address> <arn>, or
+// /vbs/ivsbridge/error <address> <arn> <reason>. arn is empty when the
+// message failed before reaching any channel.
+func ivsResultMessage(address, arn string, err error) *osc.Message {
+	if err != nil {
+		return osc.NewMessage(ivsErrorAddress, address, arn, err.Error())
+	}
+
+	return osc.NewMessage(ivsAckAddress, address, arn)
+}
+
+// oscFeedback tells controllers such as Companion that a service is alive:
+// it answers /vbs/ping with /vbs/pong and sends a periodic /vbs/heartbeat.
+// When a feedback address is configured, replies and heartbeats go there
+// rather than to whoever sent the message.
+type oscFeedback struct {
+	server  *oscServer
+	name    string
+	to      net.Addr
+	started time.Time
+}
+
+// newOSCFeedback sets up feedback for server, identifying it as name in
+// pongs and heartbeats. to is an optional host:port for all feedback.
+func newOSCFeedback(server *oscServer, name, to string) (*oscFeedback, error) {
+	f := &oscFeedback{server: server, name: name, started: time.Now()}
+
+	if to != "" {
+		addr, err := net.ResolveUDPAddr("udp", to)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve feedback address %s: %w", to, err)
+		}
+		f.to = addr
+	}
+
+	server.Handle(pingAddress, f.Wrap(func(_ *osc.Message, reply oscReplyFunc) {
+		reply(osc.NewMessage(pongAddress, f.name))
+	}))
+
+	return f, nil
+}
+
+// Wrap sends h's replies to the feedback address, when there is one.
+func (f *oscFeedback) Wrap(h oscHandlerFunc) oscHandlerFunc {
+	if f.to == nil {
+		return h
+	}
+
+	return func(msg *osc.Message, _ oscReplyFunc) {
+		h(msg, func(reply *osc.Message) { f.server.SendTo(reply, f.to) })
+	}
+}
+
+// heartbeat sends /vbs/heartbeat <name> <uptime seconds> to the feedback
+// address, or to every controller heard from within window.
+func (f *oscFeedback) heartbeat(window time.Duration) {
+	msg := osc.NewMessage(heartbeatAddress, f.name, int32(time.Since(f.started).Seconds()))
+
+	if f.to != nil {
+		f.server.SendTo(msg, f.to)
+		return
+	}
+
+	for _, peer := range f.server.Peers(window) {
+		f.server.SendTo(msg, peer)
+	}
+}
+
+// Run sends a heartbeat every interval until ctx ends. Controllers that have
+// not sent anything for three intervals stop getting heartbeats.
+func (f *oscFeedback) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.heartbeat(3 * interval) //nolint:gomnd // a few missed pings
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// startOSCServer serves s on a free local port for the test.
+func startOSCServer(t *testing.T, s *oscServer) {
+	t.Helper()
+
+	if err := s.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	go func() { _ = s.Serve() }()
+}
+
+// oscConn is a UDP socket for talking to, or hearing from, an oscServer.
+func oscConn(t *testing.T, remote *net.UDPAddr) *net.UDPConn {
+	t.Helper()
+
+	var (
+		conn *net.UDPConn
+		err  error
+	)
+	if remote != nil {
+		conn, err = net.DialUDP("udp", nil, remote)
+	} else {
+		conn, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func sendOSCMessage(t *testing.T, conn *net.UDPConn, msg *osc.Message) {
+	t.Helper()
+
+	data, _ := msg.MarshalBinary()
+	if _, err := conn.Write(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readOSCMessage(t *testing.T, conn *net.UDPConn) *osc.Message {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("no OSC message: %v", err)
+	}
+
+	packet, err := osc.ParsePacket(string(buf[:n]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return packet.(*osc.Message)
+}
+
+func TestOSCFeedback_PingAndHeartbeat(t *testing.T) {
+	server := newOSCServer("127.0.0.1:0")
+	feedback, err := newOSCFeedback(server, "ivs-bridge", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	startOSCServer(t, server)
+
+	client := oscConn(t, server.LocalAddr().(*net.UDPAddr))
+	sendOSCMessage(t, client, osc.NewMessage(pingAddress))
+
+	if pong := readOSCMessage(t, client); pong.Address != pongAddress || pong.Arguments[0] != "ivs-bridge" {
+		t.Errorf("ping answered with %s", pong)
+	}
+
+	feedback.heartbeat(time.Minute)
+	beat := readOSCMessage(t, client)
+	if beat.Address != heartbeatAddress || beat.Arguments[0] != "ivs-bridge" {
+		t.Errorf("heartbeat = %s", beat)
+	}
+	if _, ok := beat.Arguments[1].(int32); !ok {
+		t.Errorf("heartbeat uptime should be an int32, got %T", beat.Arguments[1])
+	}
+
+	if peers := server.Peers(0); len(peers) != 0 {
+		t.Errorf("peers outside the window should be forgotten, got %v", peers)
+	}
+}
+
+func TestOSCFeedback_FixedAddress(t *testing.T) {
+	listener := oscConn(t, nil)
+
+	server := newOSCServer("127.0.0.1:0")
+	feedback, err := newOSCFeedback(server, "ivs-bridge", listener.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	route := ivsRoute{Address: "/vbs/song", Channels: []string{"arn:main"}}
+	send := func(_, _ string, done func(error)) error {
+		done(nil)
+		return nil
+	}
+	server.Handle(route.Address, feedback.Wrap(ivsRouteHandler(route, send)))
+	startOSCServer(t, server)
+
+	client := oscConn(t, server.LocalAddr().(*net.UDPAddr))
+	sendOSCMessage(t, client, osc.NewMessage("/vbs/song", "Amazing Grace"))
+
+	ack := readOSCMessage(t, listener)
+	if ack.Address != ivsAckAddress || ack.Arguments[0] != "/vbs/song" || ack.Arguments[1] != "arn:main" {
+		t.Errorf("ack = %s", ack)
+	}
+
+	feedback.heartbeat(time.Minute)
+	if beat := readOSCMessage(t, listener); beat.Address != heartbeatAddress {
+		t.Errorf("heartbeat should go to the feedback address, got %s", beat)
+	}
+
+	if _, err := newOSCFeedback(newOSCServer("127.0.0.1:0"), "x", "no-port"); err == nil {
+		t.Error("a bad feedback address should fail")
+	}
+}
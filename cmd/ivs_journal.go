@@ -0,0 +1,205 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ivs"
+	"github.com/muesli/coral"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// streamStartRecheck is how long a stream start lookup is trusted before
+// IVS is asked again, so a restarted stream is noticed.
+const streamStartRecheck = 30 * time.Second
+
+// journalEntry is one line of the metadata journal: a payload that was sent,
+// and where it fell in the live stream when the stream start was known.
+type journalEntry struct {
+	Time        time.Time  `json:"time"`
+	StreamStart *time.Time `json:"stream_start,omitempty"`
+	Offset      *float64   `json:"offset,omitempty"`
+	Channel     string     `json:"channel"`
+	Payload     string     `json:"payload"`
+}
+
+// streamStartFunc returns when the stream on a channel started, or false
+// when the channel is not live or could not be asked.
+type streamStartFunc func(arn string) (time.Time, bool)
+
+// journalSink passes payloads to its sink and records each one that was
+// delivered, so the metadata can be attached to the recording afterwards.
+type journalSink struct {
+	metadataSink
+	mu          sync.Mutex
+	w           io.Writer
+	closer      io.Closer
+	streamStart streamStartFunc
+	now         func() time.Time
+}
+
+func newJournalSink(sink metadataSink, path string, streamStart streamStartFunc) (*journalSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open metadata journal: %w", err)
+	}
+
+	return &journalSink{metadataSink: sink, w: f, closer: f, streamStart: streamStart, now: time.Now}, nil
+}
+
+func (s *journalSink) Put(channel, payload string) error {
+	if err := s.metadataSink.Put(channel, payload); err != nil {
+		return err
+	}
+
+	entry := journalEntry{Time: s.now().UTC(), Channel: channel, Payload: payload}
+	if start, ok := s.streamStart(channel); ok {
+		start = start.UTC()
+		offset := entry.Time.Sub(start).Seconds()
+		entry.StreamStart, entry.Offset = &start, &offset
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not encode journal entry: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the payload went out, so a journal problem is logged rather than
+	// retried, which would send it again
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		log.Error().Err(err).Msg("Could not write metadata journal")
+	}
+
+	return nil
+}
+
+func (s *journalSink) Close() error {
+	err := s.metadataSink.Close()
+	if cerr := s.closer.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// ivsStreamAPI is the IVS call used to find when a stream started.
+type ivsStreamAPI interface {
+	GetStream(*ivs.GetStreamInput) (*ivs.GetStreamOutput, error)
+}
+
+type streamStartLookup struct {
+	start   time.Time
+	ok      bool
+	checked time.Time
+}
+
+// ivsStreamStarts looks up stream start times with api, created on first use,
+// remembering each answer for streamStartRecheck.
+func ivsStreamStarts(api func() ivsStreamAPI, now func() time.Time) streamStartFunc {
+	var (
+		mu    sync.Mutex
+		once  sync.Once
+		svc   ivsStreamAPI
+		cache = map[string]streamStartLookup{}
+	)
+
+	return func(arn string) (time.Time, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if c, ok := cache[arn]; ok && now().Sub(c.checked) < streamStartRecheck {
+			return c.start, c.ok
+		}
+
+		once.Do(func() { svc = api() })
+
+		lookup := streamStartLookup{checked: now()}
+		out, err := svc.GetStream(&ivs.GetStreamInput{ChannelArn: aws.String(arn)})
+		if err != nil {
+			log.Debug().Err(err).Msgf("No stream start for %s", arn)
+		} else if out.Stream != nil && out.Stream.StartTime != nil {
+			lookup.start, lookup.ok = *out.Stream.StartTime, true
+		}
+		cache[arn] = lookup
+
+		return lookup.start, lookup.ok
+	}
+}
+
+func newIVSStreamAPI() ivsStreamAPI {
+	return ivs.New(session.Must(session.NewSession()))
+}
+
+// openMetadataJournal wraps sink in a journal when --journal, or the
+// ivs_journal config key, names a file.
+func openMetadataJournal(cmd *coral.Command, sink metadataSink) (metadataSink, error) {
+	path := viper.GetString("ivs_journal")
+	if f := cmd.Flags().Lookup("journal"); f != nil && f.Changed {
+		path = f.Value.String()
+	}
+
+	if path == "" {
+		return sink, nil
+	}
+
+	return newJournalSink(sink, resolveInputPath(path), ivsStreamStarts(newIVSStreamAPI, time.Now))
+}
+
+func addJournalFlag(cmd *coral.Command) {
+	cmd.Flags().String("journal", "", "Append every payload sent, with its offset in the live stream, to this file; overrides ivs_journal")
+}
+
+// readJournal reads the entries of a metadata journal. Lines that are not
+// journal entries are skipped with a warning so a damaged line does not
+// lose the rest of the event.
+func readJournal(r io.Reader) ([]journalEntry, error) {
+	var entries []journalEntry
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) //nolint:gomnd // payloads are at most 1 KB
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var e journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.Time.IsZero() {
+			log.Warn().Msgf("Skipping journal line %d, it is not a journal entry", line)
+
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read journal: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,154 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ivs"
+)
+
+// failingSink records payloads, failing those for arn:broken.
+type failingSink struct {
+	put    []string
+	closed bool
+}
+
+func (s *failingSink) Put(channel, payload string) error {
+	if channel == "arn:broken" {
+		return errors.New("unreachable")
+	}
+	s.put = append(s.put, payload)
+
+	return nil
+}
+
+func (s *failingSink) Close() error {
+	s.closed = true
+
+	return nil
+}
+
+func TestJournalSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	streamStart := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	now := streamStart.Add(75 * time.Second)
+
+	inner := &failingSink{}
+	sink, err := newJournalSink(inner, path, func(arn string) (time.Time, bool) {
+		return streamStart, arn == "arn:live"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.now = func() time.Time { return now }
+
+	if err := sink.Put("arn:live", `{"song":3}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Put("arn:broken", "lost"); err == nil {
+		t.Error("a failed put should be reported")
+	}
+	if err := sink.Put("arn:offline", "rehearsal"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil || !inner.closed {
+		t.Fatalf("close: %v, inner closed %v", err, inner.closed)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	entries, err := readJournal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("only delivered payloads should be journaled, got %+v", entries)
+	}
+
+	live := entries[0]
+	if live.Channel != "arn:live" || live.Payload != `{"song":3}` || !live.Time.Equal(now) {
+		t.Errorf("live entry = %+v", live)
+	}
+	if live.StreamStart == nil || !live.StreamStart.Equal(streamStart) || live.Offset == nil || *live.Offset != 75 {
+		t.Errorf("live entry should be timed from the stream start, got %+v", live)
+	}
+	if entries[1].StreamStart != nil || entries[1].Offset != nil {
+		t.Errorf("an offline channel has no stream offset, got %+v", entries[1])
+	}
+}
+
+func TestReadJournal_SkipsDamagedLines(t *testing.T) {
+	journal := `{"time":"2026-10-18T09:00:05Z","channel":"arn:c","payload":"one"}
+{"time":"2026-10-18T09:00:
+
+{"channel":"arn:c","payload":"no time"}
+{"time":"2026-10-18T09:01:00Z","channel":"arn:c","payload":"two"}
+`
+
+	entries, err := readJournal(strings.NewReader(journal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Payload != "one" || entries[1].Payload != "two" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+// fakeIVSStream answers GetStream, counting calls.
+type fakeIVSStream struct {
+	start *time.Time
+	calls int
+}
+
+func (f *fakeIVSStream) GetStream(*ivs.GetStreamInput) (*ivs.GetStreamOutput, error) {
+	f.calls++
+	if f.start == nil {
+		return nil, errors.New("channel is not broadcasting")
+	}
+
+	return &ivs.GetStreamOutput{Stream: &ivs.Stream{StartTime: aws.Time(*f.start)}}, nil
+}
+
+func TestIVSStreamStarts(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	api := &fakeIVSStream{}
+
+	lookup := ivsStreamStarts(func() ivsStreamAPI { return api }, func() time.Time { return now })
+
+	if _, ok := lookup("arn:c"); ok {
+		t.Error("an offline channel has no start")
+	}
+	now = now.Add(time.Second)
+	api.start = aws.Time(now)
+	if _, ok := lookup("arn:c"); ok || api.calls != 1 {
+		t.Errorf("the answer should be remembered, calls = %d", api.calls)
+	}
+
+	now = now.Add(streamStartRecheck)
+	if start, ok := lookup("arn:c"); !ok || !start.Equal(*api.start) || api.calls != 2 {
+		t.Errorf("after %s the stream should be checked again, got %v %v after %d calls", streamStartRecheck, start, ok, api.calls)
+	}
+}
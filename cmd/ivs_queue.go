@@ -0,0 +1,304 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// ivsMaxPayload is the PutMetadata payload limit in bytes.
+	ivsMaxPayload = 1024
+	// ivsDefaultRate is IVS's default PutMetadata quota, per channel per second.
+	ivsDefaultRate  = 5.0
+	ivsDefaultQueue = 100
+	ivsMaxAttempts  = 5
+)
+
+// ivsRetryDelay is the first backoff after a throttled send; it doubles on
+// each retry up to ivsMaxRetryDelay.
+var (
+	ivsRetryDelay    = 250 * time.Millisecond
+	ivsMaxRetryDelay = 4 * time.Second
+)
+
+var errQueueFull = errors.New("send queue is full")
+
+// validateIVSPayload checks a payload against the PutMetadata limits before
+// it is sent, so oversized metadata fails with a clear message.
+func validateIVSPayload(payload string) error {
+	if payload == "" {
+		return fmt.Errorf("metadata payload is empty")
+	}
+
+	if len(payload) > ivsMaxPayload {
+		return fmt.Errorf("metadata payload is %d bytes; IVS allows at most %d", len(payload), ivsMaxPayload)
+	}
+
+	return nil
+}
+
+// isThrottled reports whether err is AWS, or another sink, asking us to slow
+// down.
+func isThrottled(err error) bool {
+	if errors.Is(err, errThrottled) {
+		return true
+	}
+
+	var awsErr awserr.Error
+
+	return errors.As(err, &awsErr) && request.IsErrorThrottle(awsErr)
+}
+
+// putWithRetry sends payload, backing off and retrying while IVS throttles.
+// It returns how many retries were needed.
+func putWithRetry(ctx context.Context, put metadataPutFunc, arn, payload string) (int, error) {
+	delay := ivsRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		err := put(arn, payload)
+		if err == nil || !isThrottled(err) || attempt == ivsMaxAttempts {
+			return attempt - 1, err
+		}
+
+		log.Warn().Err(err).Msgf("Throttled sending to %s, retrying in %v", arn, delay)
+
+		select {
+		case <-ctx.Done():
+			return attempt - 1, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > ivsMaxRetryDelay {
+			delay = ivsMaxRetryDelay
+		}
+	}
+}
+
+// metadataStats counts what happened to payloads for one channel.
+type metadataStats struct {
+	Sent    int64
+	Retried int64
+	Dropped int64
+	Failed  int64
+}
+
+// queuedPayload is a payload waiting to be sent, with an optional callback
+// for the result.
+type queuedPayload struct {
+	payload string
+	done    func(error)
+}
+
+func (p queuedPayload) finish(err error) {
+	if p.done != nil {
+		p.done(err)
+	}
+}
+
+// channelQueue delivers payloads for one channel in order, within the
+// channel's rate limit.
+type channelQueue struct {
+	arn     string
+	queue   chan queuedPayload
+	limiter *rate.Limiter
+
+	sent, retried, dropped, failed atomic.Int64
+}
+
+func (q *channelQueue) stats() metadataStats {
+	return metadataStats{
+		Sent:    q.sent.Load(),
+		Retried: q.retried.Load(),
+		Dropped: q.dropped.Load(),
+		Failed:  q.failed.Load(),
+	}
+}
+
+// metadataQueue puts metadata from the OSC handlers without blocking them.
+// Each channel gets its own queue and worker so a throttled channel does not
+// hold up the others. Anything that cannot be delivered is logged as dropped
+// or failed and counted.
+type metadataQueue struct {
+	put   metadataPutFunc
+	rate  float64
+	depth int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	channels map[string]*channelQueue
+	closed   bool
+}
+
+// newMetadataQueue sends through put at up to perSecond payloads per channel,
+// holding at most depth waiting payloads per channel.
+func newMetadataQueue(put metadataPutFunc, perSecond float64, depth int) *metadataQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &metadataQueue{
+		put:      put,
+		rate:     perSecond,
+		depth:    depth,
+		ctx:      ctx,
+		cancel:   cancel,
+		channels: map[string]*channelQueue{},
+	}
+}
+
+// Send validates payload and queues it for arn. It fails without queueing
+// when the payload is invalid or the channel's queue is full.
+func (m *metadataQueue) Send(arn, payload string) error {
+	return m.SendNotify(arn, payload, nil)
+}
+
+// SendNotify is Send, calling done with the outcome once a queued payload
+// has been sent or given up on. done is not called when SendNotify fails.
+func (m *metadataQueue) SendNotify(arn, payload string, done func(error)) error {
+	if err := validateIVSPayload(payload); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return fmt.Errorf("metadata queue is closed")
+	}
+
+	q := m.channelLocked(arn)
+	select {
+	case q.queue <- queuedPayload{payload: payload, done: done}:
+		return nil
+	default:
+		q.dropped.Add(1)
+		log.Warn().Msgf("Dropped metadata for %s, %d payloads already waiting: %s", arn, m.depth, payload)
+
+		return errQueueFull
+	}
+}
+
+func (m *metadataQueue) channelLocked(arn string) *channelQueue {
+	if q, ok := m.channels[arn]; ok {
+		return q
+	}
+
+	q := &channelQueue{
+		arn:     arn,
+		queue:   make(chan queuedPayload, m.depth),
+		limiter: rate.NewLimiter(rate.Limit(m.rate), 1),
+	}
+	m.channels[arn] = q
+
+	m.wg.Add(1)
+	go m.deliver(q)
+
+	return q
+}
+
+func (m *metadataQueue) deliver(q *channelQueue) {
+	defer m.wg.Done()
+
+	for item := range q.queue {
+		payload := item.payload
+
+		if err := q.limiter.Wait(m.ctx); err != nil {
+			q.dropped.Add(1)
+			log.Warn().Msgf("Dropped metadata for %s at shutdown: %s", q.arn, payload)
+			item.finish(fmt.Errorf("dropped at shutdown: %w", err))
+
+			continue
+		}
+
+		retries, err := putWithRetry(m.ctx, m.put, q.arn, payload)
+		q.retried.Add(int64(retries))
+
+		if err != nil {
+			q.failed.Add(1)
+			log.Error().Err(err).Msgf("Could not send metadata to %s after %d attempts: %s", q.arn, retries+1, payload)
+			item.finish(err)
+
+			continue
+		}
+
+		q.sent.Add(1)
+		log.Debug().Msgf("Sent metadata to %s: %s", q.arn, payload)
+		item.finish(nil)
+	}
+}
+
+// Stats reports per-channel counts.
+func (m *metadataQueue) Stats() map[string]metadataStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make(map[string]metadataStats, len(m.channels))
+	for arn, q := range m.channels {
+		stats[arn] = q.stats()
+	}
+
+	return stats
+}
+
+// Close stops accepting payloads and waits up to timeout for the queues to
+// drain. Anything still waiting after that is dropped. The final counts are
+// logged so the operator can see whether everything went out.
+func (m *metadataQueue) Close(timeout time.Duration) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	for _, q := range m.channels {
+		close(q.queue)
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		m.cancel()
+		<-done
+	}
+	m.cancel()
+
+	for arn, s := range m.Stats() {
+		event := log.Info()
+		if s.Dropped > 0 || s.Failed > 0 {
+			event = log.Warn()
+		}
+		event.Msgf("Metadata for %s: %d sent, %d retries, %d dropped, %d failed", arn, s.Sent, s.Retried, s.Dropped, s.Failed)
+	}
+}
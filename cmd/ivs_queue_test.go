@@ -0,0 +1,234 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ivs"
+)
+
+// fastRetries shortens the backoff for the duration of a test.
+func fastRetries(t *testing.T) {
+	t.Helper()
+	delay, maxDelay := ivsRetryDelay, ivsMaxRetryDelay
+	ivsRetryDelay, ivsMaxRetryDelay = time.Millisecond, 2*time.Millisecond
+	t.Cleanup(func() { ivsRetryDelay, ivsMaxRetryDelay = delay, maxDelay })
+}
+
+func throttleErr() error {
+	return fmt.Errorf("error from ivs.PutMetadata: %w",
+		awserr.New(ivs.ErrCodeThrottlingException, "Rate exceeded", nil))
+}
+
+func TestValidateIVSPayload(t *testing.T) {
+	if err := validateIVSPayload(strings.Repeat("x", ivsMaxPayload)); err != nil {
+		t.Errorf("a payload at the limit should pass: %v", err)
+	}
+	if err := validateIVSPayload(strings.Repeat("x", ivsMaxPayload+1)); err == nil || !strings.Contains(err.Error(), "1025 bytes") {
+		t.Errorf("oversized payload error = %v", err)
+	}
+	if err := validateIVSPayload(""); err == nil {
+		t.Error("empty payload should fail")
+	}
+}
+
+func TestPutWithRetry(t *testing.T) {
+	fastRetries(t)
+
+	cases := []struct {
+		name        string
+		errs        []error
+		wantRetries int
+		wantErr     bool
+	}{
+		{"first time", []error{nil}, 0, false},
+		{"throttled then sent", []error{throttleErr(), throttleErr(), nil}, 2, false},
+		{"other errors are not retried", []error{errors.New("AccessDenied")}, 0, true},
+		{"gives up", []error{throttleErr(), throttleErr(), throttleErr(), throttleErr(), throttleErr(), nil}, ivsMaxAttempts - 1, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			put := func(_, _ string) error {
+				err := tc.errs[calls]
+				calls++
+				return err
+			}
+
+			retries, err := putWithRetry(context.Background(), put, "arn:main", "{}")
+			if retries != tc.wantRetries || (err != nil) != tc.wantErr {
+				t.Errorf("retries %d err %v, want %d and error %v", retries, err, tc.wantRetries, tc.wantErr)
+			}
+		})
+	}
+}
+
+// recordingPut records payloads per channel; channels in block wait for
+// release before returning.
+type recordingPut struct {
+	mu      sync.Mutex
+	sent    map[string][]string
+	block   map[string]chan struct{}
+	started chan string
+}
+
+func newRecordingPut() *recordingPut {
+	return &recordingPut{sent: map[string][]string{}, block: map[string]chan struct{}{}, started: make(chan string, 100)}
+}
+
+func (r *recordingPut) put(arn, payload string) error {
+	r.started <- arn
+	if ch, ok := r.block[arn]; ok {
+		<-ch
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent[arn] = append(r.sent[arn], payload)
+
+	return nil
+}
+
+func (r *recordingPut) get(arn string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.sent[arn]...)
+}
+
+func TestMetadataQueue_DeliversInOrder(t *testing.T) {
+	rec := newRecordingPut()
+	q := newMetadataQueue(rec.put, 1000, 10)
+
+	for i := 1; i <= 3; i++ {
+		if err := q.Send("arn:main", fmt.Sprintf(`{"n":%d}`, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q.Close(time.Second)
+
+	if got := fmt.Sprint(rec.get("arn:main")); got != `[{"n":1} {"n":2} {"n":3}]` {
+		t.Errorf("sent = %s", got)
+	}
+	if s := q.Stats()["arn:main"]; s.Sent != 3 || s.Dropped != 0 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestMetadataQueue_RateLimits(t *testing.T) {
+	rec := newRecordingPut()
+	q := newMetadataQueue(rec.put, 20, 10)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_ = q.Send("arn:main", "{}")
+	}
+	q.Close(time.Second)
+
+	// a burst of one, then 50ms between sends
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 sends at 20/s took %v, want at least 100ms", elapsed)
+	}
+}
+
+func TestMetadataQueue_DropsWhenFull(t *testing.T) {
+	rec := newRecordingPut()
+	release := make(chan struct{})
+	rec.block["arn:main"] = release
+	q := newMetadataQueue(rec.put, 1000, 1)
+
+	_ = q.Send("arn:main", "first")
+	<-rec.started // the worker holds "first"
+	if err := q.Send("arn:main", "second"); err != nil {
+		t.Fatalf("second should queue: %v", err)
+	}
+	if err := q.Send("arn:main", "third"); !errors.Is(err, errQueueFull) {
+		t.Fatalf("third = %v, want errQueueFull", err)
+	}
+
+	// a busy channel does not hold up another one
+	if err := q.Send("arn:overflow", "other"); err != nil {
+		t.Fatal(err)
+	}
+	if arn := <-rec.started; arn != "arn:overflow" {
+		t.Errorf("expected the overflow channel to send, got %s", arn)
+	}
+
+	close(release)
+	q.Close(time.Second)
+
+	if s := q.Stats()["arn:main"]; s.Sent != 2 || s.Dropped != 1 {
+		t.Errorf("stats = %+v, want 2 sent 1 dropped", s)
+	}
+	if err := q.Send("arn:main", "late"); err == nil {
+		t.Error("send after close should fail")
+	}
+}
+
+func TestMetadataQueue_RejectsOversized(t *testing.T) {
+	q := newMetadataQueue(newRecordingPut().put, 1000, 1)
+	defer q.Close(time.Second)
+
+	if err := q.Send("arn:main", strings.Repeat("x", 2000)); err == nil {
+		t.Error("oversized payload should be rejected before queueing")
+	}
+}
+
+func TestMetadataQueue_SendNotify(t *testing.T) {
+	fastRetries(t)
+
+	put := func(arn, _ string) error {
+		if arn == "arn:broken" {
+			return errors.New("access denied")
+		}
+		return nil
+	}
+	q := newMetadataQueue(put, 1000, 10)
+
+	results := make(chan string, 2)
+	notify := func(arn string) func(error) {
+		return func(err error) { results <- fmt.Sprint(arn, " ", err) }
+	}
+
+	if err := q.SendNotify("arn:main", "ok", notify("arn:main")); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.SendNotify("arn:broken", "ok", notify("arn:broken")); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.SendNotify("arn:main", "", notify("never")); err == nil {
+		t.Error("an invalid payload should fail without queueing")
+	}
+
+	q.Close(time.Second)
+	close(results)
+
+	got := map[string]bool{}
+	for r := range results {
+		got[r] = true
+	}
+	if !got["arn:main <nil>"] || !got["arn:broken access denied"] || len(got) != 2 {
+		t.Errorf("results = %v", got)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// ivsBridgeAddress is the OSC address the bridge has always listened on; an
+// ARN given on the command line is routed from here.
+const ivsBridgeAddress = "/vbs/ivsbridge"
+
+// ivsRoute sends messages arriving on an OSC address to one or more IVS
+// channels, rendering the OSC arguments through Template. Routes are read
+// from the ivs_routes config key:
+//
+//	ivs_routes:
+//	  - address: /vbs/song
+//	    channels: [arn:aws:ivs:...:channel/main, arn:aws:ivs:...:channel/overflow]
+//	    template: '{"type":"song","n":$1}'
+type ivsRoute struct {
+	Address  string   `mapstructure:"address"`
+	Channels []string `mapstructure:"channels"`
+	Template string   `mapstructure:"template"`
+}
+
+// metadataPutFunc delivers one payload to one channel.
+type metadataPutFunc func(arn, payload string) error
+
+// metadataSendFunc queues one payload for one channel. done is called with
+// the outcome once a queued payload has been sent or given up on.
+type metadataSendFunc func(arn, payload string, done func(error)) error
+
+// loadIVSRoutes reads ivs_routes and adds a route from /vbs/ivsbridge to arn
+// when one is given.
+func loadIVSRoutes(arn string) ([]ivsRoute, error) {
+	var routes []ivsRoute
+	if err := viper.UnmarshalKey("ivs_routes", &routes); err != nil {
+		return nil, fmt.Errorf("could not read ivs_routes: %w", err)
+	}
+
+	if arn != "" {
+		routes = append([]ivsRoute{{Address: ivsBridgeAddress, Channels: []string{arn}}}, routes...)
+	}
+
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("nothing to bridge: pass a channel ARN or configure ivs_routes")
+	}
+
+	seen := map[string]bool{}
+	for _, r := range routes {
+		if !strings.HasPrefix(r.Address, "/") {
+			return nil, fmt.Errorf("ivs route address %q must start with /", r.Address)
+		}
+		if seen[r.Address] {
+			return nil, fmt.Errorf("ivs route address %s is configured twice", r.Address)
+		}
+		seen[r.Address] = true
+
+		if len(r.Channels) == 0 {
+			return nil, fmt.Errorf("ivs route %s has no channels", r.Address)
+		}
+	}
+
+	return routes, nil
+}
+
+// renderIVSPayload builds the metadata for msg. Without a template the first
+// argument is sent as text, as the bridge always did. In a template, $1..$n
+// insert the OSC arguments as JSON values (strings quoted, numbers bare), $*
+// inserts all of them as a JSON array, and $$ is a literal $. A template that
+// looks like JSON must render to valid JSON.
+func renderIVSPayload(template string, args []interface{}) (string, error) {
+	if template == "" {
+		if len(args) == 0 {
+			return "", fmt.Errorf("message has no arguments to send")
+		}
+
+		return fmt.Sprintf("%v", args[0]), nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c != '$' || i+1 == len(template) {
+			b.WriteByte(c)
+			continue
+		}
+
+		switch next := template[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '*':
+			all := make([]json.RawMessage, 0, len(args))
+			for _, arg := range args {
+				all = append(all, oscArgJSON(arg))
+			}
+			encoded, _ := json.Marshal(all)
+			b.Write(encoded)
+			i++
+		case next >= '0' && next <= '9':
+			j := i + 1
+			for j < len(template) && template[j] >= '0' && template[j] <= '9' {
+				j++
+			}
+			n, _ := strconv.Atoi(template[i+1 : j])
+			if n < 1 || n > len(args) {
+				return "", fmt.Errorf("template uses $%d but the message has %d arguments", n, len(args))
+			}
+			b.Write(oscArgJSON(args[n-1]))
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	payload := b.String()
+	if trimmed := strings.TrimSpace(payload); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		if !json.Valid([]byte(payload)) {
+			return "", fmt.Errorf("template rendered invalid JSON: %s", payload)
+		}
+	}
+
+	return payload, nil
+}
+
+// oscArgJSON encodes one OSC argument as a JSON value. float32 keeps its
+// short form so 1.1 does not become 1.100000023841858.
+func oscArgJSON(arg interface{}) json.RawMessage {
+	switch v := arg.(type) {
+	case float32:
+		return json.RawMessage(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case int32, int64, float64, bool, string, nil:
+		encoded, _ := json.Marshal(v)
+		return encoded
+	case []byte:
+		encoded, _ := json.Marshal(string(v))
+		return encoded
+	default:
+		encoded, _ := json.Marshal(fmt.Sprint(v))
+		return encoded
+	}
+}
+
+// ivsRouteHandler renders each message for route and sends it to every
+// channel, replying with an ack or error for each channel once the outcome
+// is known.
+func ivsRouteHandler(route ivsRoute, send metadataSendFunc) oscHandlerFunc {
+	return func(msg *osc.Message, reply oscReplyFunc) {
+		log.Debug().Msg(msg.String())
+
+		payload, err := renderIVSPayload(route.Template, msg.Arguments)
+		if err != nil {
+			log.Error().Err(err).Msgf("Could not render metadata for %s", msg.Address)
+			reply(ivsResultMessage(msg.Address, "", err))
+
+			return
+		}
+
+		for _, arn := range route.Channels {
+			arn := arn
+			done := func(err error) { reply(ivsResultMessage(msg.Address, arn, err)) }
+
+			if err := send(arn, payload, done); err != nil {
+				log.Error().Err(err).Msgf("Could not send metadata to %s", arn)
+				done(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/spf13/viper"
+)
+
+func TestRenderIVSPayload(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		args     []interface{}
+		want     string
+		wantErr  string
+	}{
+		{"legacy first argument", "", []interface{}{"hello", int32(2)}, "hello", ""},
+		{"legacy needs an argument", "", nil, "", "no arguments"},
+		{"number", `{"type":"song","n":$1}`, []interface{}{int32(42)}, `{"type":"song","n":42}`, ""},
+		{"string is quoted and escaped", `{"speaker":$1}`, []interface{}{`Ana "MC" Díaz`}, `{"speaker":"Ana \"MC\" Díaz"}`, ""},
+		{"float32 stays short", `{"v":$1}`, []interface{}{float32(1.1)}, `{"v":1.1}`, ""},
+		{"several and bool", `{"a":$2,"b":$1,"on":$3}`, []interface{}{"x", int64(7), true}, `{"a":7,"b":"x","on":true}`, ""},
+		{"all arguments", `{"args":$*}`, []interface{}{"x", int32(1)}, `{"args":["x",1]}`, ""},
+		{"literal dollar", `price $$5`, nil, `price $5`, ""},
+		{"two digit index", `[$10]`, []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9, "ten"}, `["ten"]`, ""},
+		{"missing argument", `{"n":$2}`, []interface{}{int32(1)}, "", "has 1 arguments"},
+		{"invalid JSON", `{"n":$1`, []interface{}{int32(1)}, "", "invalid JSON"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := renderIVSPayload(tc.template, tc.args)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadIVSRoutes(t *testing.T) {
+	t.Cleanup(func() { viper.Set("ivs_routes", nil) })
+
+	viper.Set("ivs_routes", []map[string]interface{}{
+		{"address": "/vbs/song", "channels": []string{"arn:main", "arn:overflow"}, "template": `{"n":$1}`},
+	})
+
+	routes, err := loadIVSRoutes("arn:cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 2 || routes[0].Address != ivsBridgeAddress || routes[0].Channels[0] != "arn:cli" {
+		t.Fatalf("routes = %+v, want the CLI route first", routes)
+	}
+	if fmt.Sprint(routes[1].Channels) != "[arn:main arn:overflow]" || routes[1].Template != `{"n":$1}` {
+		t.Errorf("configured route = %+v", routes[1])
+	}
+}
+
+func TestLoadIVSRoutes_Errors(t *testing.T) {
+	t.Cleanup(func() { viper.Set("ivs_routes", nil) })
+
+	cases := map[string]interface{}{
+		"nothing to bridge": nil,
+		"must start with /": []map[string]interface{}{{"address": "vbs/x", "channels": []string{"a"}}},
+		"no channels":       []map[string]interface{}{{"address": "/vbs/x"}},
+		"configured twice": []map[string]interface{}{
+			{"address": "/vbs/x", "channels": []string{"a"}},
+			{"address": "/vbs/x", "channels": []string{"b"}},
+		},
+	}
+
+	for want, routes := range cases {
+		viper.Set("ivs_routes", routes)
+		if _, err := loadIVSRoutes(""); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestIVSRouteHandler_SendsToEveryChannel(t *testing.T) {
+	var sent []string
+	send := func(arn, payload string, done func(error)) error {
+		sent = append(sent, arn+" "+payload)
+		if arn == "arn:broken" {
+			return fmt.Errorf("throttled")
+		}
+		done(nil)
+		return nil
+	}
+
+	var replies []string
+	route := ivsRoute{Address: "/vbs/song", Channels: []string{"arn:broken", "arn:main"}, Template: `{"n":$1}`}
+	msg := osc.NewMessage("/vbs/song", int32(12))
+	ivsRouteHandler(route, send)(msg, func(reply *osc.Message) {
+		replies = append(replies, reply.String())
+	})
+
+	if fmt.Sprint(sent) != `[arn:broken {"n":12} arn:main {"n":12}]` {
+		t.Errorf("sent = %v; a failed channel should not stop the others", sent)
+	}
+
+	want := []string{
+		"/vbs/ivsbridge/error ,sss /vbs/song arn:broken throttled",
+		"/vbs/ivsbridge/ack ,ss /vbs/song arn:main",
+	}
+	if fmt.Sprint(replies) != fmt.Sprint(want) {
+		t.Errorf("replies = %q, want %q", replies, want)
+	}
+}
+
+func TestIVSRouteHandler_RepliesRenderError(t *testing.T) {
+	send := func(string, string, func(error)) error {
+		t.Error("nothing should be sent")
+		return nil
+	}
+
+	var replies []string
+	route := ivsRoute{Address: "/vbs/song", Channels: []string{"arn:main"}, Template: `{"n":$2}`}
+	ivsRouteHandler(route, send)(osc.NewMessage("/vbs/song", int32(1)), func(reply *osc.Message) {
+		replies = append(replies, reply.Address)
+	})
+
+	if fmt.Sprint(replies) != "[/vbs/ivsbridge/error]" {
+		t.Errorf("replies = %v", replies)
+	}
+}
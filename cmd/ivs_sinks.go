@@ -0,0 +1,330 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ivs"
+	"github.com/muesli/coral"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// metadataSink delivers timed metadata for a channel. The IVS bridge and
+// ivs-put send through a sink so they can run against a local stand-in, and
+// feed players other than IVS.
+type metadataSink interface {
+	Put(channel, payload string) error
+	Close() error
+}
+
+// errThrottled marks a sink error that should be retried after a backoff.
+var errThrottled = errors.New("throttled")
+
+// sinkMessage is what the non-IVS sinks emit for each payload.
+type sinkMessage struct {
+	Time    time.Time `json:"time"`
+	Channel string    `json:"channel"`
+	Payload string    `json:"payload"`
+}
+
+// newMetadataSink builds the sinks named in spec, separated by commas:
+//
+//	ivs                   the IVS PutMetadata API (the default)
+//	stdout                JSON lines on standard output
+//	file:<path>           JSON lines appended to a file
+//	http(s)://...         a JSON POST to a webhook for every payload
+//	websocket:<host:port> broadcast to WebSocket clients of ws://host:port/metadata
+func newMetadataSink(spec string) (metadataSink, error) {
+	var sinks multiSink
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+
+		var (
+			sink metadataSink
+			err  error
+		)
+
+		switch {
+		case part == "" || part == "ivs":
+			sink = newIVSSink()
+		case part == "stdout":
+			sink = &jsonLinesSink{w: os.Stdout}
+		case strings.HasPrefix(part, "file:"):
+			sink, err = newFileSink(resolveInputPath(strings.TrimPrefix(part, "file:")))
+		case strings.HasPrefix(part, "http://"), strings.HasPrefix(part, "https://"):
+			sink = &webhookSink{url: part, client: &http.Client{Timeout: 10 * time.Second}}
+		case strings.HasPrefix(part, "websocket:"):
+			sink, err = newWebsocketSink(strings.TrimPrefix(part, "websocket:"))
+		default:
+			err = fmt.Errorf("unknown metadata sink %q; use ivs, stdout, file:<path>, an http(s) URL or websocket:<addr>", part)
+		}
+
+		if err != nil {
+			_ = sinks.Close()
+
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if len(sinks) == 1 {
+		return sinks[0], nil
+	}
+
+	return sinks, nil
+}
+
+// openMetadataSink builds the sinks named by --sink, or the ivs_sink config
+// key when the flag is not given, journaled when a journal is configured.
+func openMetadataSink(cmd *coral.Command) (metadataSink, error) {
+	spec := viper.GetString("ivs_sink")
+	if f := cmd.Flags().Lookup("sink"); f != nil && f.Changed {
+		spec = f.Value.String()
+	}
+
+	sink, err := newMetadataSink(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	journaled, err := openMetadataJournal(cmd, sink)
+	if err != nil {
+		_ = sink.Close()
+
+		return nil, err
+	}
+
+	return journaled, nil
+}
+
+func addSinkFlag(cmd *coral.Command) {
+	cmd.Flags().String("sink", "", "Where to send metadata: ivs, stdout, file:<path>, an http(s) URL or websocket:<addr>; overrides ivs_sink")
+}
+
+// multiSink puts every payload to each of its sinks. A throttled sink is
+// retried on its own, so a slow webhook or IVS never makes the others send
+// the same payload twice.
+type multiSink []metadataSink
+
+func (m multiSink) Put(channel, payload string) error {
+	errs := make([]error, len(m))
+
+	var wg sync.WaitGroup
+	for i, s := range m {
+		wg.Add(1)
+		go func(i int, s metadataSink) {
+			defer wg.Done()
+
+			if _, err := putWithRetry(context.Background(), s.Put, channel, payload); err != nil {
+				errs[i] = retriedError{err}
+			}
+		}(i, s)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// retriedError hides throttling from callers that would retry again, since
+// the sink that failed has already had its retries.
+type retriedError struct {
+	err error
+}
+
+func (e retriedError) Error() string {
+	return e.err.Error()
+}
+
+func (m multiSink) Close() error {
+	var errs []error
+	for _, s := range m {
+		errs = append(errs, s.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// ivsSink sends with the IVS PutMetadata API. The AWS session is created on
+// first use so other sinks work without credentials.
+type ivsSink struct {
+	once sync.Once
+	svc  *ivs.IVS
+}
+
+func newIVSSink() *ivsSink {
+	return &ivsSink{}
+}
+
+func (s *ivsSink) Put(arn, payload string) error {
+	s.once.Do(func() {
+		s.svc = ivs.New(session.Must(session.NewSession()))
+	})
+
+	_, err := s.svc.PutMetadata(&ivs.PutMetadataInput{
+		ChannelArn: aws.String(arn),
+		Metadata:   aws.String(payload),
+	})
+	if err != nil {
+		return fmt.Errorf("error from ivs.PutMetadata: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ivsSink) Close() error {
+	return nil
+}
+
+// jsonLinesSink writes one sinkMessage per line.
+type jsonLinesSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+func newFileSink(path string) (*jsonLinesSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open metadata file: %w", err)
+	}
+
+	return &jsonLinesSink{w: f, closer: f}, nil
+}
+
+func (s *jsonLinesSink) Put(channel, payload string) error {
+	line, err := json.Marshal(sinkMessage{Time: time.Now().UTC(), Channel: channel, Payload: payload})
+	if err != nil {
+		return fmt.Errorf("could not encode metadata: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("could not write metadata: %w", err)
+	}
+
+	return nil
+}
+
+func (s *jsonLinesSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+
+	return s.closer.Close()
+}
+
+// webhookSink POSTs each sinkMessage as JSON. A 429 reply is treated as
+// throttling and retried.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookSink) Put(channel, payload string) error {
+	body, err := json.Marshal(sinkMessage{Time: time.Now().UTC(), Channel: channel, Payload: payload})
+	if err != nil {
+		return fmt.Errorf("could not encode metadata: %w", err)
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not post metadata: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("webhook %s: %w", s.url, errThrottled)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("webhook %s answered %s", s.url, resp.Status)
+	}
+
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	return nil
+}
+
+// websocketSink serves ws://<addr>/metadata and broadcasts each sinkMessage
+// to every connected client.
+type websocketSink struct {
+	hub      *wsHub
+	server   *http.Server
+	listener net.Listener
+}
+
+func newWebsocketSink(addr string) (*websocketSink, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen for websocket clients: %w", err)
+	}
+
+	hub := newWSHub()
+	mux := http.NewServeMux()
+	mux.Handle("/metadata", hub)
+
+	s := &websocketSink{
+		hub:      hub,
+		server:   &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+		listener: listener,
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("websocket sink stopped")
+		}
+	}()
+
+	log.Info().Msgf("Broadcasting metadata on ws://%s/metadata", listener.Addr())
+
+	return s, nil
+}
+
+func (s *websocketSink) Put(channel, payload string) error {
+	message, err := json.Marshal(sinkMessage{Time: time.Now().UTC(), Channel: channel, Payload: payload})
+	if err != nil {
+		return fmt.Errorf("could not encode metadata: %w", err)
+	}
+
+	s.hub.Broadcast(message)
+
+	return nil
+}
+
+func (s *websocketSink) Close() error {
+	s.hub.Close()
+
+	return s.server.Close()
+}
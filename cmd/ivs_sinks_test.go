@@ -0,0 +1,326 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func decodeSinkLines(t *testing.T, data []byte) []sinkMessage {
+	t.Helper()
+
+	var messages []sinkMessage
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var m sinkMessage
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("bad JSON line %q: %v", line, err)
+		}
+		messages = append(messages, m)
+	}
+
+	return messages
+}
+
+func TestNewMetadataSink(t *testing.T) {
+	dir := t.TempDir()
+
+	cases := []struct {
+		spec    string
+		want    string
+		wantErr string
+	}{
+		{spec: "", want: "*cmd.ivsSink"},
+		{spec: "ivs", want: "*cmd.ivsSink"},
+		{spec: "stdout", want: "*cmd.jsonLinesSink"},
+		{spec: "file:" + filepath.Join(dir, "meta.jsonl"), want: "*cmd.jsonLinesSink"},
+		{spec: "http://127.0.0.1:9/hook", want: "*cmd.webhookSink"},
+		{spec: "websocket:127.0.0.1:0", want: "*cmd.websocketSink"},
+		{spec: "stdout, ivs", want: "cmd.multiSink"},
+		{spec: "carrier-pigeon", wantErr: "unknown metadata sink"},
+		{spec: "stdout,file:" + filepath.Join(dir, "missing", "meta.jsonl"), wantErr: "could not open metadata file"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.spec, func(t *testing.T) {
+			sink, err := newMetadataSink(tc.spec)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sink.Close()
+
+			if got := fmt.Sprintf("%T", sink); got != tc.want {
+				t.Errorf("sink is %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meta.jsonl")
+
+	sink, err := newMetadataSink("file:" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, payload := range []string{`{"song":1}`, "amen"} {
+		if err := sink.Put("arn:test", payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := decodeSinkLines(t, data)
+	if len(got) != 2 || got[0].Payload != `{"song":1}` || got[1].Payload != "amen" || got[1].Channel != "arn:test" {
+		t.Errorf("file holds %+v", got)
+	}
+	if got[0].Time.IsZero() {
+		t.Error("lines should be timestamped")
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []sinkMessage
+		status   = http.StatusNoContent
+	)
+	setStatus := func(code int) {
+		mu.Lock()
+		status = code
+		mu.Unlock()
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m sinkMessage
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			t.Errorf("bad webhook body: %v", err)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("content type %q", ct)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, m)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink, err := newMetadataSink(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Put("arn:a", "one"); err != nil {
+		t.Fatalf("2xx should succeed: %v", err)
+	}
+
+	setStatus(http.StatusTooManyRequests)
+	if err := sink.Put("arn:a", "two"); !isThrottled(err) {
+		t.Errorf("429 should be retried as throttling, got %v", err)
+	}
+
+	setStatus(http.StatusInternalServerError)
+	if err := sink.Put("arn:a", "three"); err == nil || isThrottled(err) {
+		t.Errorf("500 should fail without retry, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 3 || received[0].Channel != "arn:a" || received[0].Payload != "one" {
+		t.Errorf("webhook received %+v", received)
+	}
+}
+
+// dialWS connects a WebSocket client to path on addr.
+func dialWS(t *testing.T, addr, path string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n", path, addr)
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake answered %s", resp.Status)
+	}
+	// the example key and accept value from RFC 6455
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Sec-WebSocket-Accept = %q", got)
+	}
+
+	return conn, r
+}
+
+func TestWebsocketSink(t *testing.T) {
+	sink, err := newWebsocketSink("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	addr := sink.listener.Addr().String()
+	conn, r := dialWS(t, addr, "/metadata")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for sink.hub.Clients() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := sink.Put("arn:ws", `{"cue":3}`); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	op, payload, err := readWSFrame(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op != wsOpText {
+		t.Fatalf("opcode %x, want text", op)
+	}
+
+	var m sinkMessage
+	if err := json.Unmarshal(payload, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Channel != "arn:ws" || m.Payload != `{"cue":3}` {
+		t.Errorf("client received %+v", m)
+	}
+
+	// a masked ping from the client is answered with a pong
+	if _, err := conn.Write([]byte{0x80 | wsOpPing, 0x80 | 2, 1, 2, 3, 4, 'h' ^ 1, 'i' ^ 2}); err != nil {
+		t.Fatal(err)
+	}
+	op, payload, err = readWSFrame(r)
+	if err != nil || op != wsOpPong || string(payload) != "hi" {
+		t.Errorf("ping answered with %x %q %v", op, payload, err)
+	}
+
+	resp, err := http.Get("http://" + addr + "/metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("plain GET answered %s", resp.Status)
+	}
+}
+
+func TestWSFrameLengths(t *testing.T) {
+	for _, n := range []int{0, 125, 126, 0xFFFF, 0x10000} {
+		payload := bytes.Repeat([]byte{'x'}, n)
+
+		op, got, err := readWSFrame(bytes.NewReader(wsFrame(wsOpText, payload)))
+		if err != nil || op != wsOpText || len(got) != n {
+			t.Errorf("%d byte frame read back as %x, %d bytes, %v", n, op, len(got), err)
+		}
+	}
+
+	if _, _, err := readWSFrame(bytes.NewReader(nil)); err != io.EOF {
+		t.Errorf("empty input err = %v", err)
+	}
+}
+
+func TestMultiSink(t *testing.T) {
+	var a, b bytes.Buffer
+	sink := multiSink{&jsonLinesSink{w: &a}, &jsonLinesSink{w: &b}}
+
+	if err := sink.Put("arn:m", "both"); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, buf := range map[string]*bytes.Buffer{"first": &a, "second": &b} {
+		if got := decodeSinkLines(t, buf.Bytes()); len(got) != 1 || got[0].Payload != "both" {
+			t.Errorf("%s sink got %+v", name, got)
+		}
+	}
+}
+
+// countingSink counts Puts and fails the first ones with err.
+type countingSink struct {
+	mu    sync.Mutex
+	puts  int
+	fails int
+	err   error
+}
+
+func (s *countingSink) Put(_, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.puts++
+	if s.puts <= s.fails {
+		return s.err
+	}
+
+	return nil
+}
+
+func (s *countingSink) Close() error { return nil }
+
+func TestMultiSink_RetriesEachSinkAlone(t *testing.T) {
+	fastRetries(t)
+
+	throttled := &countingSink{fails: 2, err: throttleErr()}
+	other := &countingSink{}
+	if err := (multiSink{throttled, other}).Put("arn:m", "{}"); err != nil {
+		t.Fatal(err)
+	}
+	if throttled.puts != 3 || other.puts != 1 {
+		t.Errorf("throttled sink put %d times, other %d; want 3 and 1", throttled.puts, other.puts)
+	}
+
+	// once a sink has used its retries, the caller must not retry everything
+	stuck := &countingSink{fails: ivsMaxAttempts, err: throttleErr()}
+	other = &countingSink{}
+	retries, err := putWithRetry(context.Background(), multiSink{stuck, other}.Put, "arn:m", "{}")
+	if err == nil || retries != 0 || other.puts != 1 {
+		t.Errorf("retries %d, err %v, other sink put %d times", retries, err, other.puts)
+	}
+}
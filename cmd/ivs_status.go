@@ -0,0 +1,408 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ivs"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/muesli/coral"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+var ivsStatusCmd = &coral.Command{
+	Use:   "ivs-status <ivs-stream-arn | --channel name>",
+	Short: "Monitor an IVS channel's stream health.",
+	Long: `Poll GetChannel and GetStream and show the stream state, health, viewer
+count and ingest bitrate and resolution. State changes such as the stream
+dropping or its health degrading are highlighted as alerts.
+
+With --alert-osc, an OSC message is sent to Companion (at the companion
+address) whenever the stream turns unhealthy or recovers, carrying a red or
+green r g b color, so a button can show stream health. For Companion's
+button style API use an address like /style/bgcolor/<page>/<button>.`,
+	Example: `vbs ivs-status arn:aws:ivs:us-west-2:123456789012:channel/abcd
+vbs ivs-status --interval 10s --alert-osc /style/bgcolor/20/12 arn:aws:ivs:us-west-2:123456789012:channel/abcd`,
+	Run:  ivsStatus,
+	Args: channelArgs(1),
+}
+
+// ivsStatusAPI is the part of the IVS API the monitor uses.
+type ivsStatusAPI interface {
+	GetChannel(*ivs.GetChannelInput) (*ivs.GetChannelOutput, error)
+	GetStream(*ivs.GetStreamInput) (*ivs.GetStreamOutput, error)
+	GetStreamSession(*ivs.GetStreamSessionInput) (*ivs.GetStreamSessionOutput, error)
+}
+
+// streamOffline is the state shown when the channel is not broadcasting.
+const streamOffline = "OFFLINE"
+
+// streamStatus is one poll of a channel.
+type streamStatus struct {
+	at      time.Time
+	name    string
+	latency string
+	state   string
+	health  string
+	viewers int64
+	started time.Time
+	// ingest is only known while a stream session is live
+	width, height, framerate, bitrate int64
+	codec                             string
+	err                               error
+}
+
+// unhealthy reports whether the stream needs attention: not live, starving,
+// or not reachable.
+func (s streamStatus) unhealthy() bool {
+	return s.err != nil || s.state != ivs.StreamStateLive || s.health == ivs.StreamHealthStarving
+}
+
+// pollStreamStatus reads the channel, its stream and, when live, the ingest
+// configuration of the current session.
+func pollStreamStatus(api ivsStatusAPI, arn string, now time.Time) streamStatus {
+	status := streamStatus{at: now}
+
+	channel, err := api.GetChannel(&ivs.GetChannelInput{Arn: aws.String(arn)})
+	if err != nil {
+		status.err = fmt.Errorf("could not get channel: %w", err)
+		return status
+	}
+	if channel.Channel != nil {
+		status.name = aws.StringValue(channel.Channel.Name)
+		status.latency = aws.StringValue(channel.Channel.LatencyMode)
+	}
+
+	stream, err := api.GetStream(&ivs.GetStreamInput{ChannelArn: aws.String(arn)})
+	var awsErr awserr.Error
+	switch {
+	case errors.As(err, &awsErr) && awsErr.Code() == ivs.ErrCodeChannelNotBroadcasting:
+		status.state = streamOffline
+		return status
+	case err != nil:
+		status.err = fmt.Errorf("could not get stream: %w", err)
+		return status
+	case stream.Stream == nil:
+		status.state = streamOffline
+		return status
+	}
+
+	status.state = aws.StringValue(stream.Stream.State)
+	status.health = aws.StringValue(stream.Stream.Health)
+	status.viewers = aws.Int64Value(stream.Stream.ViewerCount)
+	status.started = aws.TimeValue(stream.Stream.StartTime)
+
+	sess, err := api.GetStreamSession(&ivs.GetStreamSessionInput{
+		ChannelArn: aws.String(arn),
+		StreamId:   stream.Stream.StreamId,
+	})
+	// ingest details are a nice to have; state and health are what matter
+	if err != nil || sess.StreamSession == nil || sess.StreamSession.IngestConfiguration == nil {
+		return status
+	}
+	if video := sess.StreamSession.IngestConfiguration.Video; video != nil {
+		status.width = aws.Int64Value(video.VideoWidth)
+		status.height = aws.Int64Value(video.VideoHeight)
+		status.framerate = aws.Int64Value(video.TargetFramerate)
+		status.bitrate = aws.Int64Value(video.TargetBitrate)
+		status.codec = aws.StringValue(video.Codec)
+	}
+
+	return status
+}
+
+// statusAlerts describes what changed between two polls that an operator
+// should notice.
+func statusAlerts(prev, cur streamStatus) []string {
+	var alerts []string
+
+	switch {
+	case cur.err != nil && prev.err == nil:
+		alerts = append(alerts, "Lost contact with IVS: "+cur.err.Error())
+	case cur.err == nil && prev.err != nil:
+		alerts = append(alerts, "Contact with IVS restored")
+	}
+	if cur.err != nil {
+		return alerts
+	}
+
+	if prev.state != "" && cur.state != prev.state {
+		switch {
+		case cur.state == ivs.StreamStateLive:
+			alerts = append(alerts, "Stream is live")
+		case prev.state == ivs.StreamStateLive:
+			alerts = append(alerts, "Stream dropped, now "+cur.state)
+		default:
+			alerts = append(alerts, "Stream is now "+cur.state)
+		}
+	}
+
+	if cur.state == ivs.StreamStateLive && prev.health != "" && cur.health != prev.health {
+		if cur.health == ivs.StreamHealthStarving {
+			alerts = append(alerts, "Stream health degraded: ingest is starving")
+		} else {
+			alerts = append(alerts, "Stream health is now "+cur.health)
+		}
+	}
+
+	return alerts
+}
+
+// statusAlert is an alert shown in the monitor.
+type statusAlert struct {
+	at   time.Time
+	text string
+}
+
+// maxStatusAlerts is how many recent alerts the monitor shows.
+const maxStatusAlerts = 8
+
+// statusPollMsg asks for the next scheduled poll. Only the latest scheduled
+// poll runs, so a manual refresh does not start a second polling loop.
+type statusPollMsg struct {
+	seq int
+}
+
+type streamStatusMsg struct {
+	status streamStatus
+}
+
+// statusModel is the ivs-status TUI.
+type statusModel struct {
+	api      ivsStatusAPI
+	arn      string
+	interval time.Duration
+	now      func() time.Time
+	// notify is told whether the stream is unhealthy when that changes
+	notify func(unhealthy bool)
+
+	status   streamStatus
+	polled   bool
+	polling  bool
+	seq      int
+	alerts   []statusAlert
+	quitting bool
+}
+
+func newStatusModel(api ivsStatusAPI, arn string, interval time.Duration) statusModel {
+	return statusModel{
+		api:      api,
+		arn:      arn,
+		interval: interval,
+		now:      time.Now,
+		notify:   func(bool) {},
+	}
+}
+
+func (m statusModel) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m statusModel) poll() tea.Cmd {
+	api, arn, now := m.api, m.arn, m.now
+
+	return func() tea.Msg {
+		return streamStatusMsg{pollStreamStatus(api, arn, now())}
+	}
+}
+
+func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			m.quitting = true
+
+			return m, tea.Quit
+		case "r":
+			if !m.polling {
+				m.polling = true
+
+				return m, m.poll()
+			}
+		}
+	case statusPollMsg:
+		if msg.seq == m.seq && !m.polling {
+			m.polling = true
+
+			return m, m.poll()
+		}
+	case streamStatusMsg:
+		m.polling = false
+
+		for _, text := range statusAlerts(m.status, msg.status) {
+			m.alerts = append(m.alerts, statusAlert{at: msg.status.at, text: text})
+		}
+		if len(m.alerts) > maxStatusAlerts {
+			m.alerts = m.alerts[len(m.alerts)-maxStatusAlerts:]
+		}
+
+		if !m.polled || msg.status.unhealthy() != m.status.unhealthy() {
+			m.notify(msg.status.unhealthy())
+		}
+		m.status = msg.status
+		m.polled = true
+
+		m.seq++
+		seq := m.seq
+
+		return m, tea.Tick(m.interval, func(time.Time) tea.Msg { return statusPollMsg{seq: seq} })
+	}
+
+	return m, nil
+}
+
+var (
+	statusBadStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFDF5")).Background(lipgloss.Color("#C0392B")).Padding(0, 1)
+	statusGoodStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFDF5")).Background(lipgloss.Color("#25A065")).Padding(0, 1)
+)
+
+func (m statusModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	title := "IVS status"
+	if m.status.name != "" {
+		title += ": " + m.status.name
+	}
+	s := titleStyle().Render(title) + "\n\n"
+
+	if !m.polled {
+		return appStyle.Render(s + showMutedStyle.Render("Checking "+m.arn+"…"))
+	}
+
+	st := m.status
+	row := func(label, value string) {
+		s += lipgloss.JoinHorizontal(lipgloss.Top, showLabelStyle.Width(10).Render(label), value) + "\n"
+	}
+
+	if st.err != nil {
+		row("IVS", statusBadStyle.Render("UNREACHABLE"))
+		row("", st.err.Error())
+	} else {
+		state := statusGoodStyle.Render(st.state)
+		if st.unhealthy() {
+			state = statusBadStyle.Render(st.state)
+		}
+		row("State", state)
+
+		if st.state == ivs.StreamStateLive {
+			row("Health", st.health)
+			row("Viewers", fmt.Sprint(st.viewers))
+			if !st.started.IsZero() {
+				row("Uptime", formatTimecode(st.at.Sub(st.started).Seconds()))
+			}
+			row("Ingest", ingestSummary(st))
+		}
+		if st.latency != "" {
+			row("Latency", strings.ToLower(st.latency))
+		}
+	}
+	row("Updated", st.at.Local().Format("15:04:05"))
+
+	if len(m.alerts) > 0 {
+		s += "\n"
+		for i := len(m.alerts) - 1; i >= 0; i-- {
+			a := m.alerts[i]
+			s += showMutedStyle.Render(a.at.Local().Format("15:04:05")) + " " + a.text + "\n"
+		}
+	}
+
+	s += "\n" + showMutedStyle.Render("r refresh • q quit")
+
+	return appStyle.Render(s)
+}
+
+// ingestSummary renders the ingest video settings, e.g.
+// "1920x1080 30fps 6.0 Mbps H.264".
+func ingestSummary(st streamStatus) string {
+	var parts []string
+	if st.width > 0 && st.height > 0 {
+		parts = append(parts, fmt.Sprintf("%dx%d", st.width, st.height))
+	}
+	if st.framerate > 0 {
+		parts = append(parts, fmt.Sprintf("%dfps", st.framerate))
+	}
+	if st.bitrate > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f Mbps", float64(st.bitrate)/1e6))
+	}
+	if st.codec != "" {
+		parts = append(parts, st.codec)
+	}
+	if len(parts) == 0 {
+		return "unknown"
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// companionHealthNotifier sends address to Companion with a red or green
+// r g b color as the stream's health changes.
+func companionHealthNotifier(address string) func(bool) {
+	return func(unhealthy bool) {
+		msg := osc.NewMessage(address, int32(0), int32(204), int32(0)) //nolint:gomnd // green
+		if unhealthy {
+			msg = osc.NewMessage(address, int32(255), int32(0), int32(0)) //nolint:gomnd // red
+		}
+
+		if err := sendCompanion(msg); err != nil {
+			log.Error().Err(err).Msgf("Could not send %s to Companion", address)
+		}
+	}
+}
+
+func ivsStatus(cmd *coral.Command, args []string) {
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if interval < time.Second {
+		log.Fatal().Msgf("--interval %v is too short; IVS allows a few calls per second", interval)
+	}
+
+	arn, _, err := channelARN(cmd, args)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not find channel")
+	}
+
+	s := session.Must(session.NewSession())
+	m := newStatusModel(ivs.New(s), arn, interval)
+
+	if address := viper.GetString("ivs_status.alert_osc"); address != "" {
+		m.notify = companionHealthNotifier(address)
+	}
+
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		log.Fatal().Err(err).Msg("Could not run status monitor")
+	}
+}
+
+func init() {
+	ivsStatusCmd.Flags().Duration("interval", 5*time.Second, "How often to poll IVS") //nolint:gomnd // default poll
+	ivsStatusCmd.Flags().String("alert-osc", "", "OSC address to send Companion a red or green color as stream health changes")
+	viper.BindPFlag("ivs_status.alert_osc", ivsStatusCmd.Flags().Lookup("alert-osc"))
+
+	addChannelFlag(ivsStatusCmd)
+
+	rootCmd.AddCommand(ivsStatusCmd)
+}
@@ -0,0 +1,213 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ivs"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeIVSStatus answers the monitor's IVS calls from canned values.
+type fakeIVSStatus struct {
+	channelErr error
+	stream     *ivs.Stream
+	streamErr  error
+	video      *ivs.VideoConfiguration
+	calls      []string
+}
+
+func (f *fakeIVSStatus) GetChannel(in *ivs.GetChannelInput) (*ivs.GetChannelOutput, error) {
+	f.calls = append(f.calls, "GetChannel "+aws.StringValue(in.Arn))
+	if f.channelErr != nil {
+		return nil, f.channelErr
+	}
+
+	return &ivs.GetChannelOutput{Channel: &ivs.Channel{
+		Name:        aws.String("sunday"),
+		LatencyMode: aws.String(ivs.ChannelLatencyModeLow),
+	}}, nil
+}
+
+func (f *fakeIVSStatus) GetStream(*ivs.GetStreamInput) (*ivs.GetStreamOutput, error) {
+	f.calls = append(f.calls, "GetStream")
+	if f.streamErr != nil {
+		return nil, f.streamErr
+	}
+
+	return &ivs.GetStreamOutput{Stream: f.stream}, nil
+}
+
+func (f *fakeIVSStatus) GetStreamSession(in *ivs.GetStreamSessionInput) (*ivs.GetStreamSessionOutput, error) {
+	f.calls = append(f.calls, "GetStreamSession "+aws.StringValue(in.StreamId))
+	if f.video == nil {
+		return nil, errors.New("no session")
+	}
+
+	return &ivs.GetStreamSessionOutput{StreamSession: &ivs.StreamSession{
+		IngestConfiguration: &ivs.IngestConfiguration{Video: f.video},
+	}}, nil
+}
+
+func liveStream(health string) *ivs.Stream {
+	return &ivs.Stream{
+		State:       aws.String(ivs.StreamStateLive),
+		Health:      aws.String(health),
+		ViewerCount: aws.Int64(42),
+		StreamId:    aws.String("st-1"),
+		StartTime:   aws.Time(time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)),
+	}
+}
+
+func TestPollStreamStatus(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	api := &fakeIVSStatus{
+		stream: liveStream(ivs.StreamHealthHealthy),
+		video: &ivs.VideoConfiguration{
+			VideoWidth:      aws.Int64(1920),
+			VideoHeight:     aws.Int64(1080),
+			TargetFramerate: aws.Int64(30),
+			TargetBitrate:   aws.Int64(6000000),
+			Codec:           aws.String("avc1.64002A"),
+		},
+	}
+
+	st := pollStreamStatus(api, "arn:c", now)
+	if st.err != nil || st.name != "sunday" || st.state != ivs.StreamStateLive || st.viewers != 42 || st.unhealthy() {
+		t.Errorf("live status = %+v", st)
+	}
+	if got := ingestSummary(st); got != "1920x1080 30fps 6.0 Mbps avc1.64002A" {
+		t.Errorf("ingest = %q", got)
+	}
+	if want := []string{"GetChannel arn:c", "GetStream", "GetStreamSession st-1"}; !reflect.DeepEqual(api.calls, want) {
+		t.Errorf("calls = %v", api.calls)
+	}
+
+	api.video = nil
+	if st := pollStreamStatus(api, "arn:c", now); st.err != nil || ingestSummary(st) != "unknown" {
+		t.Errorf("a missing session should only lose ingest details, got %+v", st)
+	}
+
+	api.streamErr = awserr.New(ivs.ErrCodeChannelNotBroadcasting, "not live", nil)
+	if st := pollStreamStatus(api, "arn:c", now); st.err != nil || st.state != streamOffline || !st.unhealthy() {
+		t.Errorf("offline status = %+v", st)
+	}
+
+	api.streamErr = errors.New("network down")
+	if st := pollStreamStatus(api, "arn:c", now); st.err == nil || !st.unhealthy() {
+		t.Errorf("failed poll status = %+v", st)
+	}
+
+	api.channelErr = errors.New("access denied")
+	if st := pollStreamStatus(api, "arn:c", now); st.err == nil || !strings.Contains(st.err.Error(), "could not get channel") {
+		t.Errorf("channel error = %v", st.err)
+	}
+}
+
+func TestStatusAlerts(t *testing.T) {
+	live := streamStatus{state: ivs.StreamStateLive, health: ivs.StreamHealthHealthy}
+	starving := streamStatus{state: ivs.StreamStateLive, health: ivs.StreamHealthStarving}
+	offline := streamStatus{state: streamOffline}
+	failed := streamStatus{err: errors.New("boom")}
+
+	cases := []struct {
+		name      string
+		prev, cur streamStatus
+		want      []string
+	}{
+		{"first poll", streamStatus{}, live, nil},
+		{"unchanged", live, live, nil},
+		{"dropped", live, offline, []string{"Stream dropped, now OFFLINE"}},
+		{"back live", offline, live, []string{"Stream is live"}},
+		{"degraded", live, starving, []string{"Stream health degraded: ingest is starving"}},
+		{"recovered", starving, live, []string{"Stream health is now HEALTHY"}},
+		{"lost contact", live, failed, []string{"Lost contact with IVS: boom"}},
+		{"still failing", failed, failed, nil},
+		{"restored", failed, live, []string{"Contact with IVS restored"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := statusAlerts(tc.prev, tc.cur); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStatusModel(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	api := &fakeIVSStatus{stream: liveStream(ivs.StreamHealthHealthy)}
+
+	var notified []bool
+	m := newStatusModel(api, "arn:c", time.Second)
+	m.now = func() time.Time { return now }
+	m.notify = func(unhealthy bool) { notified = append(notified, unhealthy) }
+
+	step := func(msg tea.Msg) tea.Cmd {
+		t.Helper()
+		next, cmd := m.Update(msg)
+		m = next.(statusModel)
+
+		return cmd
+	}
+
+	if !strings.Contains(m.View(), "Checking arn:c") {
+		t.Errorf("view before the first poll:\n%s", m.View())
+	}
+
+	if cmd := step(m.Init()()); cmd == nil {
+		t.Fatal("a poll result should schedule the next poll")
+	}
+	view := m.View()
+	for _, want := range []string{"IVS status: sunday", "LIVE", "HEALTHY", "42", "1:00:00.0"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view is missing %q:\n%s", want, view)
+		}
+	}
+
+	api.stream = liveStream(ivs.StreamHealthStarving)
+	step(m.poll()())
+	api.stream = liveStream(ivs.StreamHealthStarving)
+	step(m.poll()())
+	api.stream, api.streamErr = nil, awserr.New(ivs.ErrCodeChannelNotBroadcasting, "not live", nil)
+	step(m.poll()())
+
+	if want := []bool{false, true}; !reflect.DeepEqual(notified, want) {
+		t.Errorf("notified %v, want %v: once at start and once when health first dropped", notified, want)
+	}
+	if len(m.alerts) != 2 || !strings.Contains(m.View(), "Stream dropped, now OFFLINE") {
+		t.Errorf("alerts = %+v", m.alerts)
+	}
+
+	// a tick scheduled before the latest poll is stale
+	if cmd := step(statusPollMsg{seq: m.seq - 1}); cmd != nil {
+		t.Error("a stale tick should not poll")
+	}
+	if cmd := step(statusPollMsg{seq: m.seq}); cmd == nil {
+		t.Error("the current tick should poll")
+	}
+	if cmd := step(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}); cmd != nil {
+		t.Error("refresh while a poll is running should wait for it")
+	}
+}
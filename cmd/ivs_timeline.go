@@ -0,0 +1,452 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/muesli/coral"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+var ivsTimelineCmd = &coral.Command{
+	Use:   "ivs-timeline <ivs-stream-arn | --channel name> <timeline.json|timeline.csv|video.mp4>",
+	Short: "Send IVS metadata on a schedule.",
+	Long: `Send PutMetadata payloads at offsets from a start trigger, for lyrics,
+speaker names or polls in pre-recorded segments.
+
+The timeline is a JSON file holding a list of {"at": "0:01:15", "payload": ...},
+a CSV file of at,payload rows, or a video file whose chapters become
+{"type":"chapter","title":...} payloads. Offsets are h:mm:ss.f, m:ss.f or
+seconds; JSON payloads that are not strings are sent as compact JSON.
+
+The timeline starts at once, or with --wait when /vbs/timeline/start arrives
+over OSC. While it runs these OSC messages keep it in sync with playback:
+
+  /vbs/timeline/start [seconds]  start, or restart, from an offset
+  /vbs/timeline/pause
+  /vbs/timeline/resume
+  /vbs/timeline/seek <seconds>   jump to an absolute offset
+  /vbs/timeline/stop             stop and wait for the next start
+
+After starting or seeking, the payload in effect at the new offset is sent
+again so viewers catch up. Metadata is delivered like ivs-bridge, through
+--sink at up to ivs_rate payloads per second, and journaled with --journal.`,
+	Example: `vbs ivs-timeline arn:aws:ivs:us-west-2:123456789012:channel/abcd lyrics.json
+vbs ivs-timeline --wait --port 4429 arn:aws:ivs:us-west-2:123456789012:channel/abcd sermon.mp4`,
+	Run:  ivsTimeline,
+	Args: channelArgs(2), //nolint:gomnd // channel and timeline
+}
+
+// timelineEntry is one payload to send at an offset in seconds.
+type timelineEntry struct {
+	at      float64
+	payload string
+}
+
+// loadTimeline reads a timeline from JSON, CSV, or the chapters of a media
+// file, sorted by offset.
+func loadTimeline(path string) ([]timelineEntry, error) {
+	var (
+		entries []timelineEntry
+		err     error
+	)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var data []byte
+		if data, err = os.ReadFile(path); err == nil {
+			entries, err = parseTimelineJSON(data)
+		}
+	case ".csv":
+		var f *os.File
+		if f, err = os.Open(path); err == nil {
+			entries, err = parseTimelineCSV(f)
+			f.Close()
+		}
+	default:
+		var data ffmprobeResponse
+		if data, err = getChapters(path); err == nil {
+			entries = chapterTimeline(toPlayChapters(data))
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read timeline %s: %w", path, err)
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("timeline %s has nothing to send", path)
+	}
+
+	for _, e := range entries {
+		if err := validateIVSPayload(e.payload); err != nil {
+			return nil, fmt.Errorf("timeline entry at %s: %w", formatTimecode(e.at), err)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].at < entries[j].at })
+
+	return entries, nil
+}
+
+// parseTimelineJSON reads [{"at": "1:15" or 75, "payload": ...}, ...].
+func parseTimelineJSON(data []byte) ([]timelineEntry, error) {
+	var raw []struct {
+		At      json.RawMessage `json:"at"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	entries := make([]timelineEntry, 0, len(raw))
+	for i, r := range raw {
+		var at float64
+		if err := json.Unmarshal(r.At, &at); err != nil {
+			var tc string
+			if err := json.Unmarshal(r.At, &tc); err != nil {
+				return nil, fmt.Errorf("entry %d: at must be a timecode or seconds", i+1)
+			}
+			if at, err = parseTimecode(tc); err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+		}
+
+		var payload string
+		if err := json.Unmarshal(r.Payload, &payload); err != nil {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, r.Payload); err != nil {
+				return nil, fmt.Errorf("entry %d: missing payload", i+1)
+			}
+			payload = compact.String()
+		}
+
+		entries = append(entries, timelineEntry{at: at, payload: payload})
+	}
+
+	return entries, nil
+}
+
+// parseTimelineCSV reads at,payload rows. A first row whose offset is not a
+// timecode is taken as a header.
+func parseTimelineCSV(r io.Reader) ([]timelineEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []timelineEntry
+	for i, record := range records {
+		at, err := parseTimecode(record[0])
+		if err != nil {
+			if i == 0 {
+				continue
+			}
+
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		entries = append(entries, timelineEntry{at: at, payload: record[1]})
+	}
+
+	return entries, nil
+}
+
+// chapterTimeline sends each chapter title as the chapter starts.
+func chapterTimeline(chapters []playChapter) []timelineEntry {
+	entries := make([]timelineEntry, 0, len(chapters))
+	for _, c := range chapters {
+		payload, _ := json.Marshal(struct {
+			Type  string `json:"type"`
+			Title string `json:"title"`
+		}{"chapter", c.title})
+		entries = append(entries, timelineEntry{at: c.start, payload: string(payload)})
+	}
+
+	return entries
+}
+
+// timelineCursor tracks which entries have been sent.
+type timelineCursor struct {
+	entries []timelineEntry
+	next    int
+}
+
+// due returns the unsent entries at or before pos and marks them sent.
+func (c *timelineCursor) due(pos float64) []timelineEntry {
+	start := c.next
+	for c.next < len(c.entries) && c.entries[c.next].at <= pos {
+		c.next++
+	}
+
+	return c.entries[start:c.next]
+}
+
+// jump moves to pos so entries from pos on are sent next. It returns the
+// entry in effect before pos, if any, so it can be sent again.
+func (c *timelineCursor) jump(pos float64) (timelineEntry, bool) {
+	c.next = sort.Search(len(c.entries), func(i int) bool { return c.entries[i].at >= pos })
+	if c.next == 0 {
+		return timelineEntry{}, false
+	}
+
+	return c.entries[c.next-1], true
+}
+
+// wait is how long from pos until the next entry is due.
+func (c *timelineCursor) wait(pos float64) (time.Duration, bool) {
+	if c.finished() {
+		return 0, false
+	}
+
+	return time.Duration((c.entries[c.next].at - pos) * float64(time.Second)), true
+}
+
+func (c *timelineCursor) finished() bool {
+	return c.next >= len(c.entries)
+}
+
+// timelineClock is the timeline position, which runs with wall time while
+// started and not paused.
+type timelineClock struct {
+	now     func() time.Time
+	running bool
+	base    float64
+	since   time.Time
+}
+
+func (k *timelineClock) position() float64 {
+	if !k.running {
+		return k.base
+	}
+
+	return k.base + k.now().Sub(k.since).Seconds()
+}
+
+func (k *timelineClock) set(pos float64, running bool) {
+	k.base = pos
+	k.since = k.now()
+	k.running = running
+}
+
+// timelineAction is an OSC control for a running timeline.
+type timelineAction string
+
+const (
+	timelineStart  timelineAction = "start"
+	timelinePause  timelineAction = "pause"
+	timelineResume timelineAction = "resume"
+	timelineSeek   timelineAction = "seek"
+	timelineStop   timelineAction = "stop"
+)
+
+type timelineControl struct {
+	action  timelineAction
+	seconds float64
+}
+
+// timelineRunner sends a timeline's entries as its clock reaches them.
+type timelineRunner struct {
+	cursor  timelineCursor
+	clock   timelineClock
+	send    func(timelineEntry)
+	control chan timelineControl
+	// started is false until the first start; repeat keeps the runner
+	// waiting for another start once the timeline is finished.
+	started bool
+	repeat  bool
+}
+
+func newTimelineRunner(entries []timelineEntry, send func(timelineEntry)) *timelineRunner {
+	return &timelineRunner{
+		cursor:  timelineCursor{entries: entries},
+		clock:   timelineClock{now: time.Now},
+		send:    send,
+		control: make(chan timelineControl, 16), //nolint:gomnd // room for a burst of OSC
+	}
+}
+
+// apply handles one control message.
+func (r *timelineRunner) apply(c timelineControl) {
+	switch c.action {
+	case timelineStart:
+		r.started = true
+		r.jump(c.seconds, true)
+		log.Info().Msgf("Timeline started at %s", formatTimecode(c.seconds))
+	case timelinePause:
+		if r.started && r.clock.running {
+			r.clock.set(r.clock.position(), false)
+			log.Info().Msgf("Timeline paused at %s", formatTimecode(r.clock.base))
+		}
+	case timelineResume:
+		if r.started && !r.clock.running {
+			r.clock.set(r.clock.base, true)
+			log.Info().Msgf("Timeline resumed at %s", formatTimecode(r.clock.base))
+		}
+	case timelineSeek:
+		if r.started {
+			r.jump(c.seconds, r.clock.running)
+			log.Info().Msgf("Timeline moved to %s", formatTimecode(c.seconds))
+		}
+	case timelineStop:
+		r.started = false
+		r.clock.set(0, false)
+		log.Info().Msg("Timeline stopped, waiting for /vbs/timeline/start")
+	}
+}
+
+func (r *timelineRunner) jump(pos float64, running bool) {
+	if pos < 0 {
+		pos = 0
+	}
+	r.clock.set(pos, running)
+
+	if current, ok := r.cursor.jump(pos); ok {
+		r.send(current)
+	}
+}
+
+// run sends entries until ctx ends or, unless repeat is set, the last entry
+// has been sent.
+func (r *timelineRunner) run(ctx context.Context) {
+	for {
+		var timer <-chan time.Time
+
+		if r.started && r.clock.running {
+			for _, e := range r.cursor.due(r.clock.position()) {
+				r.send(e)
+			}
+
+			if wait, ok := r.cursor.wait(r.clock.position()); ok {
+				timer = time.After(wait)
+			} else if !r.repeat {
+				log.Info().Msg("Timeline finished")
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer:
+		case c := <-r.control:
+			r.apply(c)
+		}
+	}
+}
+
+// handleOSC registers the /vbs/timeline controls on s.
+func (r *timelineRunner) handleOSC(s *oscServer) {
+	for _, action := range []timelineAction{timelineStart, timelinePause, timelineResume, timelineSeek, timelineStop} {
+		action := action
+		s.Handle("/vbs/timeline/"+string(action), func(msg *osc.Message, _ oscReplyFunc) {
+			seconds, ok := oscFloatArg(msg, 0)
+			if action == timelineSeek && !ok {
+				log.Error().Msgf("%s needs an offset in seconds", msg.Address)
+				return
+			}
+
+			select {
+			case r.control <- timelineControl{action: action, seconds: seconds}:
+			default:
+				log.Warn().Msgf("Timeline is busy, ignored %s", msg.Address)
+			}
+		})
+	}
+}
+
+func ivsTimeline(cmd *coral.Command, args []string) {
+	arn, rest, err := channelARN(cmd, args)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not find channel")
+	}
+
+	entries, err := loadTimeline(resolveInputPath(rest[0]))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not load timeline")
+	}
+
+	sink, err := openMetadataSink(cmd)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not configure metadata sink")
+	}
+	defer sink.Close()
+
+	queue := newMetadataQueue(sink.Put, viper.GetFloat64("ivs_rate"), ivsDefaultQueue)
+
+	runner := newTimelineRunner(entries, func(e timelineEntry) {
+		log.Info().Msgf("%s %s", formatTimecode(e.at), e.payload)
+
+		if err := queue.Send(arn, e.payload); err != nil {
+			log.Error().Err(err).Msgf("Could not queue metadata for %s", formatTimecode(e.at))
+		}
+	})
+
+	wait, _ := cmd.Flags().GetBool("wait")
+	runner.repeat = wait
+
+	server := newOSCServer("127.0.0.1:" + viper.GetString("ivs_timeline_port"))
+	runner.handleOSC(server)
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, net.ErrClosed) {
+			log.Error().Err(err).Msg("error from server.ListenAndServe")
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if wait {
+		log.Info().Msgf("%d entries loaded, waiting for /vbs/timeline/start", len(entries))
+	} else {
+		runner.apply(timelineControl{action: timelineStart})
+	}
+
+	runner.run(ctx)
+
+	_ = server.Close()
+	queue.Close(ivsDrainTimeout)
+}
+
+func init() {
+	ivsTimelineCmd.Flags().Bool("wait", false, "Wait for /vbs/timeline/start instead of starting at once")
+	ivsTimelineCmd.Flags().String("port", "4429", "Port to listen for OSC timeline controls")
+	viper.BindPFlag("ivs_timeline_port", ivsTimelineCmd.Flags().Lookup("port"))
+	addSinkFlag(ivsTimelineCmd)
+	addJournalFlag(ivsTimelineCmd)
+	addChannelFlag(ivsTimelineCmd)
+
+	rootCmd.AddCommand(ivsTimelineCmd)
+}
@@ -0,0 +1,229 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseTimelineJSON(t *testing.T) {
+	got, err := parseTimelineJSON([]byte(`[
+		{"at": "1:15", "payload": "Amazing Grace"},
+		{"at": 5.5, "payload": {"type": "speaker", "name": "Ana"}},
+		{"at": "0:00:02.5", "payload": [1, 2]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []timelineEntry{
+		{at: 75, payload: "Amazing Grace"},
+		{at: 5.5, payload: `{"type":"speaker","name":"Ana"}`},
+		{at: 2.5, payload: "[1,2]"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	for _, bad := range []string{
+		`[{"at": "soon", "payload": "x"}]`,
+		`[{"at": true, "payload": "x"}]`,
+		`[{"at": 1}]`,
+		`{"at": 1}`,
+	} {
+		if _, err := parseTimelineJSON([]byte(bad)); err == nil {
+			t.Errorf("%s should fail", bad)
+		}
+	}
+}
+
+func TestParseTimelineCSV(t *testing.T) {
+	got, err := parseTimelineCSV(strings.NewReader("at,payload\n0:10,\"{\"\"n\"\":1}\"\n20,chorus\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []timelineEntry{{at: 10, payload: `{"n":1}`}, {at: 20, payload: "chorus"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := parseTimelineCSV(strings.NewReader("0:10,a\nlater,b\n")); err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("a bad offset after the first row should fail, got %v", err)
+	}
+}
+
+func TestLoadTimeline(t *testing.T) {
+	dir := t.TempDir()
+
+	sorted := filepath.Join(dir, "lyrics.json")
+	if err := os.WriteFile(sorted, []byte(`[{"at": 20, "payload": "b"}, {"at": 10, "payload": "a"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadTimeline(sorted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].payload != "a" || got[1].payload != "b" {
+		t.Errorf("timeline should be sorted by offset, got %+v", got)
+	}
+
+	oversized := filepath.Join(dir, "big.csv")
+	if err := os.WriteFile(oversized, []byte("1,"+strings.Repeat("x", ivsMaxPayload+1)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadTimeline(oversized); err == nil || !strings.Contains(err.Error(), "0:01.0") {
+		t.Errorf("oversized payload should fail with its offset, got %v", err)
+	}
+
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, []byte(`[]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadTimeline(empty); err == nil {
+		t.Error("an empty timeline should fail")
+	}
+}
+
+func TestChapterTimeline(t *testing.T) {
+	got := chapterTimeline([]playChapter{{title: "Welcome", start: 0}, {title: `"Grace"`, start: 61.5}})
+
+	want := []timelineEntry{
+		{at: 0, payload: `{"type":"chapter","title":"Welcome"}`},
+		{at: 61.5, payload: `{"type":"chapter","title":"\"Grace\""}`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestTimelineCursor(t *testing.T) {
+	c := timelineCursor{entries: []timelineEntry{{0, "a"}, {10, "b"}, {10, "c"}, {30, "d"}}}
+
+	if due := c.due(0); len(due) != 1 || due[0].payload != "a" {
+		t.Errorf("due(0) = %+v", due)
+	}
+	if wait, ok := c.wait(4); !ok || wait != 6*time.Second {
+		t.Errorf("wait(4) = %v %v", wait, ok)
+	}
+	if due := c.due(10); len(due) != 2 {
+		t.Errorf("both entries at 10 should be due, got %+v", due)
+	}
+	if due := c.due(10); len(due) != 0 {
+		t.Errorf("entries should only be due once, got %+v", due)
+	}
+
+	current, ok := c.jump(20)
+	if !ok || current.payload != "c" {
+		t.Errorf("jump(20) should return the entry in effect, got %+v %v", current, ok)
+	}
+	if due := c.due(30); len(due) != 1 || due[0].payload != "d" {
+		t.Errorf("after jump, due(30) = %+v", due)
+	}
+	if !c.finished() {
+		t.Error("cursor should be finished")
+	}
+	if _, ok := c.wait(30); ok {
+		t.Error("a finished cursor has nothing to wait for")
+	}
+
+	if _, ok := c.jump(0); ok {
+		t.Error("nothing is in effect before the first entry")
+	}
+	if due := c.due(0); len(due) != 1 || due[0].payload != "a" {
+		t.Errorf("jumping back should resend from the start, got %+v", due)
+	}
+}
+
+func TestTimelineRunnerControls(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	var sent []string
+	r := newTimelineRunner([]timelineEntry{{0, "a"}, {10, "b"}, {20, "c"}}, func(e timelineEntry) {
+		sent = append(sent, e.payload)
+	})
+	r.clock.now = func() time.Time { return now }
+
+	r.apply(timelineControl{action: timelinePause})
+	if r.started || r.clock.running {
+		t.Fatal("controls before start should be ignored")
+	}
+
+	r.apply(timelineControl{action: timelineStart, seconds: 12})
+	if !reflect.DeepEqual(sent, []string{"b"}) {
+		t.Errorf("starting mid-way should send the entry in effect, sent %v", sent)
+	}
+
+	now = now.Add(3 * time.Second)
+	r.apply(timelineControl{action: timelinePause})
+	now = now.Add(time.Minute)
+	if pos := r.clock.position(); pos != 15 {
+		t.Errorf("paused position = %v, want 15", pos)
+	}
+
+	r.apply(timelineControl{action: timelineResume})
+	now = now.Add(2 * time.Second)
+	if pos := r.clock.position(); pos != 17 {
+		t.Errorf("resumed position = %v, want 17", pos)
+	}
+
+	r.apply(timelineControl{action: timelineSeek, seconds: 1})
+	if !reflect.DeepEqual(sent, []string{"b", "a"}) {
+		t.Errorf("seeking should send the entry in effect, sent %v", sent)
+	}
+	if pos := r.clock.position(); pos != 1 || !r.clock.running {
+		t.Errorf("after seek position = %v running = %v", pos, r.clock.running)
+	}
+
+	r.apply(timelineControl{action: timelineStop})
+	if r.started || r.clock.position() != 0 {
+		t.Error("stop should reset the timeline")
+	}
+}
+
+func TestTimelineRunnerSends(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		sent []string
+	)
+	r := newTimelineRunner([]timelineEntry{{0, "a"}, {0.02, "b"}, {0.04, "c"}}, func(e timelineEntry) {
+		mu.Lock()
+		sent = append(sent, e.payload)
+		mu.Unlock()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	r.control <- timelineControl{action: timelineStart}
+	r.run(ctx)
+
+	if ctx.Err() != nil {
+		t.Fatal("runner should finish after the last entry")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(sent, []string{"a", "b", "c"}) {
+		t.Errorf("sent %v", sent)
+	}
+}
@@ -15,11 +15,15 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
+	"os"
 	"strings"
+	"syscall"
 
-	"github.com/hypebeast/go-osc/osc"
 	"github.com/kindlyops/vbs/embeddy"
 	"github.com/labstack/echo/v5"
 	"github.com/muesli/coral"
@@ -28,15 +32,84 @@ import (
 )
 
 var lightingBridgeCmd = &coral.Command{
-	Use:   "lighting-bridge <companion-ip>",
+	Use:   "lighting-bridge",
 	Short: "Serve embedded lighting control page",
-	Long:  `Use OSC to send messages to Companion API for lighting control.`,
-	Run:   lightingBridge,
-	Args:  coral.NoArgs,
+	Long: `Use OSC to send messages to Companion API for lighting control.
+
+Buttons on the page are mapped to Companion with the companion_buttons config
+key. Each is a button location "<page>/<row>/<col>", optionally followed by
+an action: press (the default), down, up, rotate-left, rotate-right or
+"step <n>". Companion 3 is sent /location/<page>/<row>/<col>/<action>; with
+--companion-version 2 presses are sent as /press/bank/<page>/<bank>. A value
+starting with / is sent as an OSC address unchanged.
+
+The page lays the buttons out from GET /api/buttons, grouped, labelled and
+colored by the companion_layout config key; buttons it leaves out are listed
+last. companion_macros names sequences of presses, with waits and delays,
+that are pressed like buttons and run on the bridge. These keys are checked
+when the bridge starts.
+
+Pages are kept up to date over a WebSocket at /api/buttons/live with who
+last pressed each button. With --feedback-port, Companion triggers can send
+"/vbs/button <name> <on>" to light buttons up and "/vbs/variable <name>
+<value>" to show values on them.
+
+Buttons only work for paired devices. --pair <name> prints a QR code with a
+one-time link that pairs a phone for that user; --users lists paired devices
+and --revoke <name> removes them. With the lighting_pin config key set,
+anyone with the PIN can log in from the page instead. Scripts send a paired
+token as "Authorization: Bearer <token>". --auth none turns this off.`,
+	Example: `vbs lighting-bridge --listen 0.0.0.0 --pair alice
+vbs lighting-bridge --revoke alice`,
+	Run:  lightingBridge,
+	Args: coral.NoArgs,
 }
 
 func lightingBridge(cmd *coral.Command, args []string) {
-	listenAddr := "127.0.0.1:" + viper.GetString("lighting_port")
+	auth, err := openLightingAuth()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid lighting auth configuration")
+	}
+
+	if manageLightingUsers(cmd, auth) {
+		return
+	}
+
+	if err := checkButtonConfig(); err != nil {
+		log.Fatal().Err(err).Msg("Invalid button configuration")
+	}
+
+	listenAddr := net.JoinHostPort(viper.GetString("lighting_listen"), viper.GetString("lighting_port"))
+
+	if name, _ := cmd.Flags().GetString("pair"); name != "" {
+		code, err := auth.Pair(name)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Could not start pairing")
+		}
+
+		url := pairingURL(viper.GetString("lighting_url"), viper.GetString("lighting_listen"), viper.GetString("lighting_port"), code)
+		if err := printPairing(os.Stdout, name, url); err != nil {
+			log.Fatal().Err(err).Msg("Could not show pairing code")
+		}
+
+		// a bridge that is already running reads the same users file
+		if l, err := net.Listen("tcp", listenAddr); errors.Is(err, syscall.EADDRINUSE) {
+			log.Info().Msgf("A bridge is already running on %s, pair with it", listenAddr)
+
+			return
+		} else if err == nil {
+			l.Close()
+		}
+	}
+
+	if _, err := listenButtonFeedback(); err != nil {
+		log.Fatal().Err(err).Msg("Could not listen for Companion feedback")
+	}
+
+	if err := startDMX(); err != nil {
+		log.Fatal().Err(err).Msg("Could not start DMX output")
+	}
+
 	public, err := fs.Sub(embeddy.GetNextFS(), "public")
 	if err != nil {
 		log.Fatal().Err(err).Msg("Could not access embedded public directory")
@@ -55,8 +128,14 @@ func lightingBridge(cmd *coral.Command, args []string) {
 	log.Debug().Msgf("Starting HTTP server at: http://%s\n", listenAddr)
 	e := echo.New()
 	e.GET("/*", echo.WrapHandler(assetHandler))
-	e.POST("/api/switcher/*", echo.WrapHandler(&Switcher{}))
-	e.POST("/api/light/*", echo.WrapHandler(&Lighting{}))
+	e.GET("/pair", echo.WrapHandler(auth))
+	e.GET("/api/auth", echo.WrapHandler(auth))
+	e.POST("/api/auth/login", echo.WrapHandler(auth))
+	e.POST("/api/switcher/*", echo.WrapHandler(&Switcher{}), auth.Middleware)
+	e.POST("/api/light/*", echo.WrapHandler(&Lighting{}), auth.Middleware)
+	e.GET(buttonsPath, echo.WrapHandler(&Buttons{}), auth.Middleware)
+	e.GET(buttonLivePath, echo.WrapHandler(liveButtons), auth.Middleware)
+	e.POST(buttonsPath+"/*", echo.WrapHandler(&Buttons{}), auth.Middleware)
 	err = e.Start(listenAddr)
 
 	if err != nil {
@@ -64,6 +143,34 @@ func lightingBridge(cmd *coral.Command, args []string) {
 	}
 }
 
+// manageLightingUsers handles --users and --revoke, returning true when
+// one of them was given.
+func manageLightingUsers(cmd *coral.Command, auth *lightingAuth) bool {
+	if list, _ := cmd.Flags().GetBool("users"); list {
+		if err := listLightingUsers(os.Stdout, auth); err != nil {
+			log.Fatal().Err(err).Msg("Could not list users")
+		}
+
+		return true
+	}
+
+	name, _ := cmd.Flags().GetString("revoke")
+	if name == "" {
+		return false
+	}
+
+	removed, err := auth.Revoke(name)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not revoke user")
+	}
+	if removed == 0 {
+		log.Fatal().Msgf("No device is paired for %s; see vbs lighting-bridge --users", name)
+	}
+	fmt.Printf("Revoked %d device(s) for %s\n", removed, name)
+
+	return true
+}
+
 type Switcher struct{}
 
 func (s *Switcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -79,13 +186,8 @@ func (l *Lighting) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	handleOSC(w, r, "/api/light/", buttons)
 }
 
-func sendOSC(path string) {
-	// This port could be made configurable in the future once companion
-	// ships user-visible OSC port configuration
-	companionOSCPort := 12321
-	client := osc.NewClient(viper.GetString("companion"), companionOSCPort)
-	msg := osc.NewMessage(path)
-	client.Send(msg)
+func sendOSC(button companionButton) error {
+	return sendCompanion(button.message())
 }
 
 func handleOSC(w http.ResponseWriter, r *http.Request, prefix string, buttons map[string]string) {
@@ -95,14 +197,49 @@ func handleOSC(w http.ResponseWriter, r *http.Request, prefix string, buttons ma
 	}
 
 	command := strings.TrimPrefix(r.URL.Path, prefix)
-	if button, found := buttons[command]; found {
-		log.Debug().Msgf("handleOSC for %s, mapped to %s", command, button)
-		sendOSC(button)
-		sendOKResponse(w, r)
-	} else {
+	spec, found := buttons[command]
+	if !found {
+		if scene, ok := dmxSceneNamed(command); ok {
+			handleScene(w, r, command, scene)
+
+			return
+		}
+
+		if actions, ok := companionMacro(command); ok {
+			handleMacro(w, r, command, actions)
+
+			return
+		}
+
 		log.Debug().Msgf("handleOSC couldn't find mapping for %s in %v", command, buttons)
+		auditPress(r, command, "", pressUnknown, nil)
+		sendFailureResponse(w, r)
+
+		return
+	}
+
+	button, err := parseCompanionButton(spec, viper.GetInt("companion_version"))
+	if err != nil {
+		log.Error().Err(err).Msgf("handleOSC mapping for %s is invalid", command)
+		auditPress(r, command, "", pressInvalid, err)
 		sendFailureResponse(w, r)
+
+		return
 	}
+
+	log.Debug().Msgf("handleOSC for %s, mapped to %s", command, button.address)
+	liveMacros.CancelConflicting(button, fmt.Errorf("%s pressed %s", pressedBy(lightingUserFrom(r)), command))
+	if err := sendOSC(button); err != nil {
+		log.Error().Err(err).Msgf("Could not send %s to Companion", button.address)
+		auditPress(r, command, button.address, pressFailed, err)
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": "could not send to Companion: " + err.Error()})
+
+		return
+	}
+
+	liveButtons.Pressed(command, lightingUserFrom(r))
+	auditPress(r, command, button.address, pressSent, nil)
+	sendOKResponse(w, r)
 }
 
 func sendFailureResponse(w http.ResponseWriter, _ *http.Request) {
@@ -124,6 +261,15 @@ var CompanionAddr string
 func init() {
 	lightingBridgeCmd.Flags().StringVarP(&ServerPort, "port", "p", "7007", "Port to serve website on")
 	viper.BindPFlag("lighting_port", lightingBridgeCmd.Flags().Lookup("port"))
+	lightingBridgeCmd.Flags().String("listen", "127.0.0.1", "Address to serve on; 0.0.0.0 for other devices on the network")
+	viper.BindPFlag("lighting_listen", lightingBridgeCmd.Flags().Lookup("listen"))
+	lightingBridgeCmd.Flags().String("url", "", "Address devices reach the bridge at, for pairing links")
+	viper.BindPFlag("lighting_url", lightingBridgeCmd.Flags().Lookup("url"))
+	lightingBridgeCmd.Flags().String("auth", "token", "token to require a paired device or the PIN, none to allow anyone")
+	viper.BindPFlag("lighting_auth", lightingBridgeCmd.Flags().Lookup("auth"))
+	lightingBridgeCmd.Flags().String("pair", "", "Print a QR code with a one-time link that pairs a device for this user")
+	lightingBridgeCmd.Flags().String("revoke", "", "Remove every device paired for this user")
+	lightingBridgeCmd.Flags().Bool("users", false, "List paired devices")
 
 	lightingBridgeCmd.Flags().StringVarP(
 		&CompanionAddr,
@@ -131,20 +277,30 @@ func init() {
 		"127.0.0.1",
 		"Address to send companion OSC commands")
 	viper.BindPFlag("companion", lightingBridgeCmd.Flags().Lookup("companion"))
+	lightingBridgeCmd.Flags().Int("companion-port", companionDefaultPort, "Port Companion listens for OSC on")
+	viper.BindPFlag("companion_port", lightingBridgeCmd.Flags().Lookup("companion-port"))
+	lightingBridgeCmd.Flags().Int("companion-version", companionDefaultVersion, "Companion version, 2 for /press/bank or 3 for /location button paths")
+	viper.BindPFlag("companion_version", lightingBridgeCmd.Flags().Lookup("companion-version"))
+	lightingBridgeCmd.Flags().Int("feedback-port", 0, "UDP port to receive Companion feedback on, as /vbs/button and /vbs/variable")
+	viper.BindPFlag("companion_feedback_port", lightingBridgeCmd.Flags().Lookup("feedback-port"))
+	lightingBridgeCmd.Flags().String("dmx", "", "Send dmx_scenes directly as sacn or artnet, instead of through Companion")
+	viper.BindPFlag("dmx_protocol", lightingBridgeCmd.Flags().Lookup("dmx"))
+	lightingBridgeCmd.Flags().String("dmx-target", "", "Address to send DMX to; sACN defaults to multicast, Art-Net needs the node's address")
+	viper.BindPFlag("dmx_target", lightingBridgeCmd.Flags().Lookup("dmx-target"))
 
 	// These defaults will be written to the config file generated by the
 	// save-config command. They can then be easily customized for a local
 	// companion button layout.
 	viper.SetDefault("companion_buttons", map[string]string{
-		"green":       "/press/bank/20/10",
-		"blue":        "/press/bank/20/11",
-		"red":         "/press/bank/20/12",
-		"yellow":      "/press/bank/20/13",
-		"off":         "/press/bank/20/14",
-		"ftb":         "/press/bank/20/4",
-		"dsk":         "/press/bank/20/5",
-		"keylighton":  "/press/bank/20/18",
-		"keylightoff": "/press/bank/20/19",
+		"green":       "20/1/1",
+		"blue":        "20/1/2",
+		"red":         "20/1/3",
+		"yellow":      "20/1/4",
+		"off":         "20/1/5",
+		"ftb":         "20/0/3",
+		"dsk":         "20/0/4",
+		"keylighton":  "20/2/1",
+		"keylightoff": "20/2/2",
 	})
 
 	rootCmd.AddCommand(lightingBridgeCmd)
@@ -0,0 +1,619 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/skip2/go-qrcode"
+	"github.com/spf13/viper"
+)
+
+const (
+	// lightingTokenCookie holds a device's token once it has paired or
+	// logged in with the PIN.
+	lightingTokenCookie = "vbs_token"
+	// pairingCodeTTL is how long a pairing URL works for.
+	pairingCodeTTL = 15 * time.Minute
+	// pinAttempts wrong PINs from one address lock it out for pinLockout.
+	pinAttempts = 5
+	pinLockout  = time.Minute
+	minPINLen   = 4
+	// pinTokensPerName caps the PIN logins kept for one name; the oldest
+	// are dropped as new ones log in.
+	pinTokensPerName = 5
+)
+
+var (
+	errUnauthorized = errors.New("pair this device or log in with the PIN")
+	errBadPairing   = errors.New("this pairing link is unknown, used or expired")
+	errBadPIN       = errors.New("wrong name or PIN")
+	errPINLocked    = errors.New("too many wrong PINs, try again in a minute")
+	errPairedName   = errors.New("that name belongs to a paired device, use its pairing link")
+)
+
+// lightingUser is a device allowed to press buttons. Only a hash of its
+// token is kept. PIN is set for tokens from a PIN login rather than pairing.
+type lightingUser struct {
+	Name      string    `json:"name"`
+	TokenHash string    `json:"token_hash"`
+	Created   time.Time `json:"created"`
+	PIN       bool      `json:"pin,omitempty"`
+}
+
+// pairingCode is a one-time code for a pairing URL.
+type pairingCode struct {
+	Name     string    `json:"name"`
+	CodeHash string    `json:"code_hash"`
+	Expires  time.Time `json:"expires"`
+}
+
+// lightingAuthState is the users file.
+type lightingAuthState struct {
+	Users   []lightingUser `json:"users"`
+	Pairing []pairingCode  `json:"pairing,omitempty"`
+}
+
+// clone copies the state, so callers can change it without touching the
+// cached copy.
+func (s lightingAuthState) clone() lightingAuthState {
+	return lightingAuthState{
+		Users:   append(make([]lightingUser, 0, len(s.Users)), s.Users...),
+		Pairing: append([]pairingCode(nil), s.Pairing...),
+	}
+}
+
+type pinFailures struct {
+	count int
+	until time.Time
+}
+
+// lightingAuth checks requests to the lighting bridge against per-user
+// tokens kept in a file, so a token can be revoked, or paired, from another
+// vbs process while the bridge runs. The file is parsed again only when its
+// modification time or size changes.
+type lightingAuth struct {
+	mu       sync.Mutex
+	path     string
+	pin      string
+	disabled bool
+	now      func() time.Time
+	failures map[string]pinFailures
+	// clientIP finds the address PIN lockouts apply to.
+	clientIP echo.IPExtractor
+
+	cacheMu   sync.Mutex
+	cached    *lightingAuthState
+	cachedMod time.Time
+	cachedLen int64
+}
+
+func newLightingAuth(path, pin string) *lightingAuth {
+	return &lightingAuth{
+		path:     path,
+		pin:      pin,
+		now:      time.Now,
+		failures: map[string]pinFailures{},
+		clientIP: echo.ExtractIPDirect(),
+	}
+}
+
+// trustProxies keys PIN lockouts on the client address in X-Forwarded-For
+// when a request comes through one of the proxies in cidrs.
+func (a *lightingAuth) trustProxies(cidrs []string) error {
+	if len(cidrs) == 0 {
+		return nil
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range cidrs {
+		_, ipRange, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("lighting_trusted_proxies: %w", err)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+	a.clientIP = echo.ExtractIPFromXFFHeader(options...)
+
+	return nil
+}
+
+// openLightingAuth configures auth from the lighting_auth, lighting_pin and
+// lighting_users config keys.
+func openLightingAuth() (*lightingAuth, error) {
+	path := resolveInputPath(viper.GetString("lighting_users"))
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("could not find config dir for lighting users: %w", err)
+		}
+		path = filepath.Join(dir, "vbs", "lighting_users.json")
+	}
+
+	pin := viper.GetString("lighting_pin")
+	if pin != "" && len(pin) < minPINLen {
+		return nil, fmt.Errorf("lighting_pin must be at least %d characters", minPINLen)
+	}
+
+	a := newLightingAuth(path, pin)
+	if err := a.trustProxies(viper.GetStringSlice("lighting_trusted_proxies")); err != nil {
+		return nil, err
+	}
+
+	switch mode := viper.GetString("lighting_auth"); mode {
+	case "", "token":
+	case "none":
+		a.disabled = true
+	default:
+		return nil, fmt.Errorf("unknown lighting_auth %q, use token or none", mode)
+	}
+
+	return a, nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 24) //nolint:gomnd // 192 bits
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// load reads the users file; a missing file has no users.
+func (a *lightingAuth) load() (lightingAuthState, error) {
+	var state lightingAuthState
+
+	info, err := os.Stat(a.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("could not read lighting users: %w", err)
+	}
+
+	a.cacheMu.Lock()
+	defer a.cacheMu.Unlock()
+
+	if a.cached != nil && info.ModTime().Equal(a.cachedMod) && info.Size() == a.cachedLen {
+		return a.cached.clone(), nil
+	}
+
+	data, err := os.ReadFile(a.path)
+	if err != nil {
+		return state, fmt.Errorf("could not read lighting users: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("could not parse lighting users %s: %w", a.path, err)
+	}
+
+	a.cached, a.cachedMod, a.cachedLen = &state, info.ModTime(), info.Size()
+
+	return state.clone(), nil
+}
+
+// save writes the users file, readable only by the owner, dropping expired
+// pairing codes.
+func (a *lightingAuth) save(state lightingAuthState) error {
+	now := a.now()
+	live := state.Pairing[:0]
+	for _, p := range state.Pairing {
+		if now.Before(p.Expires) {
+			live = append(live, p)
+		}
+	}
+	state.Pairing = live
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode lighting users: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o700); err != nil {
+		return fmt.Errorf("could not create config dir: %w", err)
+	}
+
+	tmp := a.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("could not write lighting users: %w", err)
+	}
+
+	if err := os.Rename(tmp, a.path); err != nil {
+		return fmt.Errorf("could not write lighting users: %w", err)
+	}
+
+	return nil
+}
+
+// Pair makes a one-time pairing code for name.
+func (a *lightingAuth) Pair(name string) (string, error) {
+	if err := checkProfileName(name); err != nil {
+		return "", err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, err := a.load()
+	if err != nil {
+		return "", err
+	}
+
+	code, err := newSecret()
+	if err != nil {
+		return "", err
+	}
+
+	state.Pairing = append(state.Pairing, pairingCode{Name: name, CodeHash: hashSecret(code), Expires: a.now().Add(pairingCodeTTL)})
+
+	return code, a.save(state)
+}
+
+// addUser issues a token for name, from a PIN login when pin is set. a.mu
+// must be held.
+func (a *lightingAuth) addUser(state lightingAuthState, name string, pin bool) (string, error) {
+	token, err := newSecret()
+	if err != nil {
+		return "", err
+	}
+
+	state.Users = append(state.Users, lightingUser{Name: name, TokenHash: hashSecret(token), Created: a.now().UTC(), PIN: pin})
+	if pin {
+		state.Users = capPINTokens(state.Users, name)
+	}
+
+	return token, a.save(state)
+}
+
+// capPINTokens drops the oldest PIN logins for name beyond pinTokensPerName.
+func capPINTokens(users []lightingUser, name string) []lightingUser {
+	extra := -pinTokensPerName
+	for _, u := range users {
+		if u.PIN && u.Name == name {
+			extra++
+		}
+	}
+
+	kept := users[:0]
+	for _, u := range users {
+		if u.PIN && u.Name == name && extra > 0 {
+			extra--
+
+			continue
+		}
+		kept = append(kept, u)
+	}
+
+	return kept
+}
+
+// Redeem uses up a pairing code, returning a new token for its user.
+func (a *lightingAuth) Redeem(code string) (string, string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, err := a.load()
+	if err != nil {
+		return "", "", err
+	}
+
+	hash := hashSecret(code)
+	for i, p := range state.Pairing {
+		if subtle.ConstantTimeCompare([]byte(p.CodeHash), []byte(hash)) != 1 || !a.now().Before(p.Expires) {
+			continue
+		}
+
+		state.Pairing = append(state.Pairing[:i], state.Pairing[i+1:]...)
+		token, err := a.addUser(state, p.Name, false)
+
+		return token, p.Name, err
+	}
+
+	return "", "", errBadPairing
+}
+
+// Login checks the shared PIN, returning a new token for name. Names of
+// paired devices are refused, so the PIN cannot be used to press buttons as
+// someone else. Repeated wrong PINs from remote lock it out for a while.
+func (a *lightingAuth) Login(name, pin, remote string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	if f := a.failures[remote]; f.count >= pinAttempts && now.Before(f.until) {
+		return "", errPINLocked
+	}
+
+	if a.pin == "" || checkProfileName(name) != nil || subtle.ConstantTimeCompare([]byte(pin), []byte(a.pin)) != 1 {
+		f := a.failures[remote]
+		if !now.Before(f.until) {
+			f.count = 0
+		}
+		f.count++
+		f.until = now.Add(pinLockout)
+		a.failures[remote] = f
+
+		return "", errBadPIN
+	}
+	delete(a.failures, remote)
+
+	state, err := a.load()
+	if err != nil {
+		return "", err
+	}
+
+	for _, u := range state.Users {
+		if u.Name == name && !u.PIN {
+			return "", errPairedName
+		}
+	}
+	for _, p := range state.Pairing {
+		if p.Name == name {
+			return "", errPairedName
+		}
+	}
+
+	return a.addUser(state, name, true)
+}
+
+// Check returns the user a token belongs to.
+func (a *lightingAuth) Check(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+
+	state, err := a.load()
+	if err != nil {
+		log.Error().Err(err).Msg("Could not check lighting token")
+
+		return "", false
+	}
+
+	hash := hashSecret(token)
+	for _, u := range state.Users {
+		if subtle.ConstantTimeCompare([]byte(u.TokenHash), []byte(hash)) == 1 {
+			return u.Name, true
+		}
+	}
+
+	return "", false
+}
+
+// Revoke removes every token and pending pairing for name.
+func (a *lightingAuth) Revoke(name string) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, err := a.load()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	users := state.Users[:0]
+	for _, u := range state.Users {
+		if u.Name == name {
+			removed++
+
+			continue
+		}
+		users = append(users, u)
+	}
+	state.Users = users
+
+	pairing := state.Pairing[:0]
+	for _, p := range state.Pairing {
+		if p.Name != name {
+			pairing = append(pairing, p)
+		}
+	}
+	state.Pairing = pairing
+
+	return removed, a.save(state)
+}
+
+// listLightingUsers writes the paired devices as a table.
+func listLightingUsers(w io.Writer, a *lightingAuth) error {
+	state, err := a.load()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0) //nolint:gomnd // column padding
+	fmt.Fprintln(tw, "NAME\tPAIRED\tVIA")
+	for _, u := range state.Users {
+		via := "pairing"
+		if u.PIN {
+			via = "pin"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", u.Name, u.Created.Local().Format("2006-01-02 15:04"), via)
+	}
+
+	return tw.Flush()
+}
+
+// requestToken reads a bearer token, or the token cookie.
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+
+	if c, err := r.Cookie(lightingTokenCookie); err == nil {
+		return c.Value
+	}
+
+	return ""
+}
+
+// Middleware rejects requests without a valid token, for the standalone
+// and PocketBase servers alike.
+func (a *lightingAuth) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if a.disabled {
+			return next(c)
+		}
+
+		name, ok := a.Check(requestToken(c.Request()))
+		if !ok {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": errUnauthorized.Error()})
+		}
+
+		log.Debug().Msgf("lighting request from %s: %s", name, c.Request().URL.Path)
+		c.SetRequest(withLightingUser(c.Request(), name))
+
+		return next(c)
+	}
+}
+
+func setTokenCookie(w http.ResponseWriter, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     lightingTokenCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int((365 * 24 * time.Hour).Seconds()), //nolint:gomnd // a year
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// ServeHTTP answers the auth endpoints:
+//
+//	GET  /pair?code=...      redeem a pairing code, set the cookie, go to the page
+//	GET  /api/auth           {"user": name or "", "pin": whether PIN login works}
+//	POST /api/auth/login     log in with {"name", "pin"}
+func (a *lightingAuth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/pair":
+		token, name, err := a.Redeem(r.URL.Query().Get("code"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		log.Info().Msgf("Paired a device for %s", name)
+		setTokenCookie(w, token)
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+
+	case r.Method == http.MethodGet && r.URL.Path == "/api/auth":
+		name, _ := a.Check(requestToken(r))
+		writeJSON(w, http.StatusOK, map[string]interface{}{"user": name, "pin": a.pin != "", "required": !a.disabled})
+
+	case r.Method == http.MethodPost && r.URL.Path == "/api/auth/login":
+		var login struct {
+			Name string `json:"name"`
+			PIN  string `json:"pin"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&login); err != nil { //nolint:gomnd // small body
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "want {\"name\", \"pin\"}"})
+
+			return
+		}
+
+		token, err := a.Login(strings.ToLower(strings.TrimSpace(login.Name)), login.PIN, a.clientIP(r))
+		switch {
+		case errors.Is(err, errPINLocked):
+			writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+		case errors.Is(err, errBadPIN):
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		case errors.Is(err, errPairedName):
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		case err != nil:
+			log.Error().Err(err).Msg("Could not log in")
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "could not log in"})
+		default:
+			setTokenCookie(w, token)
+			writeJSON(w, http.StatusOK, map[string]string{"user": login.Name})
+		}
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// pairingURL is where a phone should go to pair: --url, or the address the
+// bridge listens on, using this machine's LAN address when it listens on
+// every interface.
+func pairingURL(base, listen, port, code string) string {
+	if base == "" {
+		host := listen
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = lanAddress()
+		}
+		base = "http://" + net.JoinHostPort(host, port)
+	}
+
+	return strings.TrimSuffix(base, "/") + "/pair?code=" + code
+}
+
+// lanAddress guesses an address other devices can reach this one on.
+func lanAddress() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "127.0.0.1"
+	}
+
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			return ipnet.IP.String()
+		}
+	}
+
+	return "127.0.0.1"
+}
+
+// printPairing shows the pairing URL as a QR code to scan with a phone.
+func printPairing(w io.Writer, name, url string) error {
+	qr, err := qrcode.New(url, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("could not make QR code: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "%s\nScan to pair a device for %s, or open:\n%s\n\nThe link works once, for %s.\n",
+		qr.ToSmallString(false), name, url, pairingCodeTTL)
+
+	return err
+}
@@ -0,0 +1,386 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+func testLightingAuth(t *testing.T, pin string) (*lightingAuth, *time.Time) {
+	t.Helper()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	a := newLightingAuth(filepath.Join(t.TempDir(), "vbs", "lighting_users.json"), pin)
+	a.now = func() time.Time { return now }
+
+	return a, &now
+}
+
+func TestLightingAuth_Pairing(t *testing.T) {
+	a, now := testLightingAuth(t, "")
+
+	if _, err := a.Pair("Not a name"); err == nil {
+		t.Error("pairing should check the name")
+	}
+
+	code, err := a.Pair("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(a.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("users file mode = %v, want 0600", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(a.path); bytes.Contains(data, []byte(code)) {
+		t.Error("the users file should only keep a hash of the code")
+	}
+
+	token, name, err := a.Redeem(code)
+	if err != nil || name != "alice" {
+		t.Fatalf("Redeem = %q, %v", name, err)
+	}
+	if user, ok := a.Check(token); !ok || user != "alice" {
+		t.Errorf("Check = %q, %v", user, ok)
+	}
+	if _, ok := a.Check("forged"); ok {
+		t.Error("an unknown token should not pass")
+	}
+
+	if _, _, err := a.Redeem(code); !errors.Is(err, errBadPairing) {
+		t.Errorf("a code should only work once, got %v", err)
+	}
+
+	code, err = a.Pair("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(pairingCodeTTL)
+	if _, _, err := a.Redeem(code); !errors.Is(err, errBadPairing) {
+		t.Errorf("an expired code should not work, got %v", err)
+	}
+
+	removed, err := a.Revoke("alice")
+	if err != nil || removed != 1 {
+		t.Fatalf("Revoke = %d, %v", removed, err)
+	}
+	if _, ok := a.Check(token); ok {
+		t.Error("a revoked token should not pass")
+	}
+}
+
+func TestLightingAuth_Login(t *testing.T) {
+	a, now := testLightingAuth(t, "2468")
+
+	if _, err := a.Login("alice", "2468", "10.0.0.2"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < pinAttempts; i++ {
+		if _, err := a.Login("alice", "1111", "10.0.0.3"); !errors.Is(err, errBadPIN) {
+			t.Fatalf("attempt %d: %v", i, err)
+		}
+	}
+	if _, err := a.Login("alice", "2468", "10.0.0.3"); !errors.Is(err, errPINLocked) {
+		t.Errorf("after %d wrong PINs the right one should be locked out, got %v", pinAttempts, err)
+	}
+	if _, err := a.Login("bob", "2468", "10.0.0.4"); err != nil {
+		t.Errorf("the lockout should only apply to one address, got %v", err)
+	}
+
+	*now = now.Add(pinLockout)
+	token, err := a.Login("alice", "2468", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("the lockout should end, got %v", err)
+	}
+	if user, ok := a.Check(token); !ok || user != "alice" {
+		t.Errorf("Check = %q, %v", user, ok)
+	}
+
+	b, _ := testLightingAuth(t, "")
+	if _, err := b.Login("alice", "", "10.0.0.2"); !errors.Is(err, errBadPIN) {
+		t.Errorf("without lighting_pin nobody can log in, got %v", err)
+	}
+}
+
+func TestLightingAuth_LoginNames(t *testing.T) {
+	a, _ := testLightingAuth(t, "2468")
+
+	code, err := a.Pair("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Login("alice", "2468", "10.0.0.2"); !errors.Is(err, errPairedName) {
+		t.Errorf("a name waiting to pair should be refused, got %v", err)
+	}
+	if _, _, err := a.Redeem(code); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Login("alice", "2468", "10.0.0.2"); !errors.Is(err, errPairedName) {
+		t.Errorf("a paired name should be refused, got %v", err)
+	}
+
+	var tokens []string
+	for i := 0; i < pinTokensPerName+2; i++ {
+		token, err := a.Login("bob", "2468", "10.0.0.2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, token)
+	}
+	state, err := a.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Users) != 1+pinTokensPerName {
+		t.Errorf("users file keeps %d tokens, want alice plus %d for bob", len(state.Users), pinTokensPerName)
+	}
+	if _, ok := a.Check(tokens[0]); ok {
+		t.Error("the oldest PIN login should be dropped")
+	}
+	if user, ok := a.Check(tokens[len(tokens)-1]); !ok || user != "bob" {
+		t.Errorf("the newest PIN login should pass, got %q, %v", user, ok)
+	}
+}
+
+func TestLightingAuth_CachesUsersFile(t *testing.T) {
+	a, _ := testLightingAuth(t, "2468")
+	token, err := a.Login("alice", "2468", "10.0.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.Check(token); !ok {
+		t.Fatal("token should pass")
+	}
+
+	// a change that keeps the modification time and size is not read
+	info, err := os.Stat(a.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(a.path)
+	if err := os.WriteFile(a.path, bytes.Repeat([]byte{' '}, len(data)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(a.path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.Check(token); !ok {
+		t.Error("an unchanged users file should come from the cache")
+	}
+
+	// another process revoking the user is seen
+	if err := os.WriteFile(a.path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := newLightingAuth(a.path, "").Revoke("alice"); err != nil || removed != 1 {
+		t.Fatalf("Revoke = %d, %v", removed, err)
+	}
+	if _, ok := a.Check(token); ok {
+		t.Error("a token revoked by another process should not pass")
+	}
+}
+
+func TestLightingAuth_LockoutBehindProxy(t *testing.T) {
+	a, _ := testLightingAuth(t, "2468")
+	if err := a.trustProxies([]string{"fdaa::/16"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.trustProxies([]string{"not a range"}); err == nil {
+		t.Error("a bad range should be reported")
+	}
+
+	login := func(remote, forwarded, pin string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"name": "bob", "pin": "`+pin+`"}`))
+		req.RemoteAddr = remote
+		if forwarded != "" {
+			req.Header.Set(echo.HeaderXForwardedFor, forwarded)
+		}
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	proxy := "[fdaa::3]:4000"
+	for i := 0; i < pinAttempts; i++ {
+		login(proxy, "203.0.113.7", "1111")
+	}
+	if code := login(proxy, "203.0.113.7", "2468"); code != http.StatusTooManyRequests {
+		t.Errorf("the guessing client should be locked out, got %d", code)
+	}
+	if code := login(proxy, "198.51.100.2", "2468"); code != http.StatusOK {
+		t.Errorf("other clients behind the proxy should still log in, got %d", code)
+	}
+
+	// only a trusted proxy may name the client
+	for i := 0; i < pinAttempts; i++ {
+		login("192.0.2.9:5000", "198.51.100.2", "1111")
+	}
+	if code := login(proxy, "198.51.100.2", "2468"); code != http.StatusOK {
+		t.Errorf("a forged X-Forwarded-For should not lock out another client, got %d", code)
+	}
+}
+
+func TestLightingAuth_Middleware(t *testing.T) {
+	a, _ := testLightingAuth(t, "")
+	code, err := a.Pair("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := a.Redeem(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	e.GET(buttonsPath, func(c echo.Context) error { return c.String(http.StatusOK, "ok") }, a.Middleware)
+
+	cases := []struct {
+		name   string
+		header string
+		cookie string
+		want   int
+	}{
+		{"nothing", "", "", http.StatusUnauthorized},
+		{"bearer", "Bearer " + token, "", http.StatusOK},
+		{"cookie", "", token, http.StatusOK},
+		{"wrong bearer", "Bearer nope", "", http.StatusUnauthorized},
+		{"basic", "Basic " + token, "", http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, buttonsPath, nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: lightingTokenCookie, Value: tc.cookie})
+			}
+
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Errorf("status %d, want %d", w.Code, tc.want)
+			}
+		})
+	}
+
+	a.disabled = true
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, buttonsPath, nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("with lighting_auth none, status %d", w.Code)
+	}
+}
+
+func TestLightingAuth_ServeHTTP(t *testing.T) {
+	a, _ := testLightingAuth(t, "2468")
+	code, err := a.Pair("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pair?code="+code, nil))
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/" {
+		t.Fatalf("pairing answered %d to %q", w.Code, w.Header().Get("Location"))
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != lightingTokenCookie || !cookies[0].HttpOnly {
+		t.Fatalf("cookies = %+v", cookies)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+	if body := w.Body.String(); !strings.Contains(body, `"user":"alice"`) || !strings.Contains(body, `"pin":true`) {
+		t.Errorf("/api/auth = %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	a.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pair?code="+code, nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("reusing a pairing link answered %d", w.Code)
+	}
+
+	logins := []struct {
+		body string
+		want int
+	}{
+		{`{"name": "Bob", "pin": "2468"}`, http.StatusOK},
+		{`{"name": "bob", "pin": "1357"}`, http.StatusUnauthorized},
+		{`{"name": "Alice", "pin": "2468"}`, http.StatusForbidden},
+		{`not json`, http.StatusBadRequest},
+	}
+	for _, tc := range logins {
+		w = httptest.NewRecorder()
+		a.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(tc.body)))
+		if w.Code != tc.want {
+			t.Errorf("login %s answered %d, want %d", tc.body, w.Code, tc.want)
+		}
+	}
+
+	var list bytes.Buffer
+	if err := listLightingUsers(&list, a); err != nil {
+		t.Fatal(err)
+	}
+	if got := list.String(); !strings.HasPrefix(got, "NAME") || !strings.Contains(got, "alice") || !strings.Contains(got, "bob") {
+		t.Errorf("users:\n%s", got)
+	}
+}
+
+func TestPairingURL(t *testing.T) {
+	cases := []struct {
+		base, listen, want string
+	}{
+		{"", "127.0.0.1", "http://127.0.0.1:7007/pair?code=abc"},
+		{"", "::1", "http://[::1]:7007/pair?code=abc"},
+		{"https://lights.example.com/", "0.0.0.0", "https://lights.example.com/pair?code=abc"},
+	}
+
+	for _, tc := range cases {
+		if got := pairingURL(tc.base, tc.listen, "7007", "abc"); got != tc.want {
+			t.Errorf("pairingURL(%q, %q) = %q, want %q", tc.base, tc.listen, got, tc.want)
+		}
+	}
+
+	if got := pairingURL("", "0.0.0.0", "7007", "abc"); strings.Contains(got, "0.0.0.0") {
+		t.Errorf("a bridge on every interface should link to a reachable address, got %q", got)
+	}
+
+	var out bytes.Buffer
+	if err := printPairing(&out, "alice", "http://127.0.0.1:7007/pair?code=abc"); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); !strings.Contains(got, "http://127.0.0.1:7007/pair?code=abc") || !strings.Contains(got, "█") {
+		t.Errorf("pairing output:\n%s", got)
+	}
+}
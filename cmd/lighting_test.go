@@ -17,6 +17,7 @@ package cmd
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -93,6 +94,27 @@ func TestHandleOSC_ValidButton(t *testing.T) {
 	}
 }
 
+func TestHandleOSC_SendFails(t *testing.T) {
+	viper.Set("companion", "127.0.0.1")
+	// an impossible port makes every send fail
+	viper.Set("companion_port", 70000)
+	viper.Set("companion_buttons", map[string]string{"green": "/press/bank/20/10"})
+	t.Cleanup(func() {
+		viper.Set("companion_port", nil)
+		viper.Set("companion_buttons", nil)
+	})
+
+	w := httptest.NewRecorder()
+	handleOSC(w, httptest.NewRequest(http.MethodPost, "/api/light/green", nil), "/api/light/", viper.GetStringMapString("companion_buttons"))
+
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("Expected status %d, got %d", http.StatusBadGateway, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "could not send to Companion") {
+		t.Errorf("Expected the error in the body, got %s", w.Body)
+	}
+}
+
 func TestHandleOSC_UnknownButton(t *testing.T) {
 	// Setup test configuration
 	viper.Set("companion_buttons", map[string]string{
@@ -0,0 +1,437 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// macroDefaultTimeout is how long a wait_for step waits for feedback.
+const macroDefaultTimeout = 5 * time.Second
+
+// States of a macro run.
+const (
+	macroRunning   = "running"
+	macroDone      = "done"
+	macroCancelled = "cancelled"
+	macroFailed    = "failed"
+)
+
+// macroStep is a companion_macros step as configured: a button from
+// companion_buttons or a button spec, then an optional wait and delay.
+type macroStep struct {
+	Button  string        `mapstructure:"button"`
+	OSC     string        `mapstructure:"osc"`
+	Arg     interface{}   `mapstructure:"arg"`
+	WaitFor string        `mapstructure:"wait_for"`
+	Timeout time.Duration `mapstructure:"timeout"`
+	Delay   time.Duration `mapstructure:"delay"`
+}
+
+// macroAction is a checked step, ready to send.
+type macroAction struct {
+	label   string
+	button  companionButton
+	waitFor string
+	waitOn  bool
+	timeout time.Duration
+	delay   time.Duration
+}
+
+// macroProgress reports a macro run to pages and callers.
+type macroProgress struct {
+	Run   uint64 `json:"run"`
+	State string `json:"state"`
+	Step  int    `json:"step"`
+	Steps int    `json:"steps"`
+	Label string `json:"label,omitempty"`
+	By    string `json:"by,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// oscArgument converts a YAML value to an OSC argument.
+func oscArgument(v interface{}) (interface{}, error) {
+	switch a := v.(type) {
+	case int:
+		if a < math.MinInt32 || a > math.MaxInt32 {
+			return nil, fmt.Errorf("arg %d does not fit in an OSC integer", a)
+		}
+
+		return int32(a), nil
+	case float64:
+		return float32(a), nil
+	case string, bool:
+		return a, nil
+	default:
+		return nil, fmt.Errorf("arg %v should be a number, string or true/false", v)
+	}
+}
+
+// parseMacroSteps checks a macro's steps against the buttons.
+func parseMacroSteps(steps []macroStep, buttons map[string]companionButton, version int) ([]macroAction, error) {
+	if len(steps) == 0 {
+		return nil, errors.New("has no steps")
+	}
+
+	actions := make([]macroAction, 0, len(steps))
+	for i, step := range steps {
+		var a macroAction
+
+		switch {
+		case (step.Button == "") == (step.OSC == ""):
+			return nil, fmt.Errorf("step %d needs one of button or osc", i+1)
+		case step.Button != "":
+			name := strings.ToLower(step.Button)
+			button, found := buttons[name]
+			if !found {
+				return nil, fmt.Errorf("step %d: button %q is not in companion_buttons", i+1, step.Button)
+			}
+			a.label, a.button = name, button
+		default:
+			button, err := parseCompanionButton(step.OSC, version)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			a.label, a.button = step.OSC, button
+		}
+
+		if step.Arg != nil {
+			arg, err := oscArgument(step.Arg)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			a.button.args = append(append([]interface{}{}, a.button.args...), arg)
+		}
+
+		if step.WaitFor != "" {
+			fields := strings.Fields(strings.ToLower(step.WaitFor))
+			a.waitFor, a.waitOn = fields[0], true
+			switch {
+			case len(fields) == 2 && fields[1] == "off": //nolint:gomnd // button and state
+				a.waitOn = false
+			case len(fields) == 2 && fields[1] == "on": //nolint:gomnd // button and state
+			case len(fields) != 1:
+				return nil, fmt.Errorf("step %d: wait_for %q should be a button, then on or off", i+1, step.WaitFor)
+			}
+
+			a.timeout = step.Timeout
+			if a.timeout == 0 {
+				a.timeout = macroDefaultTimeout
+			}
+		} else if step.Timeout != 0 {
+			return nil, fmt.Errorf("step %d: timeout is only used with wait_for", i+1)
+		}
+
+		if step.Delay < 0 || step.Timeout < 0 {
+			return nil, fmt.Errorf("step %d: delay and timeout cannot be negative", i+1)
+		}
+		a.delay = step.Delay
+
+		actions = append(actions, a)
+	}
+
+	return actions, nil
+}
+
+// loadCompanionMacros parses every companion_macros entry. Macros are
+// pressed like buttons, so they cannot share a name with one.
+func loadCompanionMacros(buttons map[string]companionButton) (map[string][]macroAction, error) {
+	var raw map[string][]macroStep
+	strict := func(c *mapstructure.DecoderConfig) { c.ErrorUnused = true }
+	if err := viper.UnmarshalKey("companion_macros", &raw, strict); err != nil {
+		return nil, fmt.Errorf("could not read companion_macros: %w", err)
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	version := viper.GetInt("companion_version")
+	macros := make(map[string][]macroAction, len(raw))
+	var errs []error
+	for _, name := range names {
+		if _, clash := buttons[name]; clash {
+			errs = append(errs, fmt.Errorf("companion_macros.%s: companion_buttons has a button with the same name", name))
+
+			continue
+		}
+
+		actions, err := parseMacroSteps(raw[name], buttons, version)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("companion_macros.%s: %w", name, err))
+
+			continue
+		}
+		macros[name] = actions
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return macros, nil
+}
+
+// companionMacro looks up a macro by name.
+func companionMacro(name string) ([]macroAction, bool) {
+	buttons, err := loadCompanionButtons()
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid companion_buttons")
+
+		return nil, false
+	}
+
+	macros, err := loadCompanionMacros(buttons)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid companion_macros")
+
+		return nil, false
+	}
+
+	actions, found := macros[name]
+
+	return actions, found
+}
+
+// buttonKey identifies the Companion button a message acts on, so pressing
+// and releasing the same location conflict.
+func buttonKey(b companionButton) string {
+	if strings.HasPrefix(b.address, "/location/") {
+		return b.address[:strings.LastIndex(b.address, "/")]
+	}
+
+	return b.address
+}
+
+// macroRun is one run of a macro.
+type macroRun struct {
+	id       uint64
+	keys     map[string]bool
+	cancel   context.CancelCauseFunc
+	done     chan struct{}
+	progress macroProgress
+}
+
+// macroRunner runs macros in the background. A command cancels running
+// macros that press any of the same Companion buttons, and starting a
+// macro again restarts it.
+type macroRunner struct {
+	mu      sync.Mutex
+	nextRun uint64
+	running map[string]*macroRun
+	send    func(companionButton) error
+	states  *buttonStates
+}
+
+func newMacroRunner(send func(companionButton) error, states *buttonStates) *macroRunner {
+	return &macroRunner{running: map[string]*macroRun{}, send: send, states: states}
+}
+
+// liveMacros runs macros for the lighting bridge and vbs serve.
+var liveMacros = newMacroRunner(sendOSC, liveButtons)
+
+func pressedBy(user string) string {
+	if user == "" {
+		return "someone"
+	}
+
+	return user
+}
+
+// Start cancels conflicting macros and runs name in the background.
+func (r *macroRunner) Start(name string, actions []macroAction, user string) *macroRun {
+	keys := map[string]bool{}
+	for _, a := range actions {
+		keys[buttonKey(a.button)] = true
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	r.mu.Lock()
+	r.cancelLocked(name, keys, fmt.Errorf("%s started %s", pressedBy(user), name))
+	r.nextRun++
+	run := &macroRun{id: r.nextRun, keys: keys, cancel: cancel, done: make(chan struct{})}
+	r.running[name] = run
+	r.mu.Unlock()
+
+	go r.run(ctx, name, run, actions, user)
+
+	return run
+}
+
+// CancelConflicting cancels running macros that press button.
+func (r *macroRunner) CancelConflicting(button companionButton, cause error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cancelLocked("", map[string]bool{buttonKey(button): true}, cause)
+}
+
+func (r *macroRunner) cancelLocked(name string, keys map[string]bool, cause error) {
+	for running, run := range r.running {
+		conflict := running == name
+		for key := range keys {
+			conflict = conflict || run.keys[key]
+		}
+
+		if conflict {
+			log.Info().Msgf("Cancelling macro %s: %v", running, cause)
+			run.cancel(cause)
+		}
+	}
+}
+
+func (r *macroRunner) run(ctx context.Context, name string, run *macroRun, actions []macroAction, user string) {
+	progress := macroProgress{Run: run.id, State: macroRunning, Steps: len(actions), By: user}
+
+	defer func() {
+		r.mu.Lock()
+		if r.running[name] == run {
+			delete(r.running, name)
+		}
+		r.mu.Unlock()
+
+		run.cancel(nil)
+		run.progress = progress
+		r.states.SetMacro(name, progress)
+		close(run.done)
+	}()
+
+	stop := func(state string, err error) {
+		progress.State = state
+		if err != nil {
+			progress.Error = err.Error()
+		}
+	}
+
+	for i, a := range actions {
+		progress.Step, progress.Label = i+1, a.label
+		r.states.SetMacro(name, progress)
+
+		if ctx.Err() != nil {
+			stop(macroCancelled, context.Cause(ctx))
+
+			return
+		}
+
+		// feedback from before the send is not an ack, even if the button
+		// is already in the state waited for
+		seen := r.states.Feedback(a.waitFor)
+		if err := r.send(a.button); err != nil {
+			stop(macroFailed, fmt.Errorf("could not send %s: %w", a.button.address, err))
+
+			return
+		}
+
+		if a.waitFor != "" {
+			state := map[bool]string{true: "on", false: "off"}[a.waitOn]
+			wait, cancel := context.WithTimeoutCause(ctx, a.timeout,
+				fmt.Errorf("no feedback that %s is %s within %s", a.waitFor, state, a.timeout))
+			err := r.states.WaitActive(wait, a.waitFor, a.waitOn, seen)
+			cancel()
+
+			switch {
+			case ctx.Err() != nil:
+				stop(macroCancelled, context.Cause(ctx))
+
+				return
+			case err != nil:
+				stop(macroFailed, err)
+
+				return
+			}
+		}
+
+		if a.delay > 0 {
+			timer := time.NewTimer(a.delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				stop(macroCancelled, context.Cause(ctx))
+
+				return
+			case <-timer.C:
+			}
+		}
+	}
+
+	stop(macroDone, nil)
+}
+
+// handleMacro starts a macro for a request. It answers 202 at once, or with
+// ?wait=true once the macro finishes: 200 when done, 409 when cancelled and
+// 502 when a step failed. Progress is also sent to /api/buttons/live, and
+// the press is audited with the macro's outcome once it finishes.
+func handleMacro(w http.ResponseWriter, r *http.Request, name string, actions []macroAction) {
+	user := lightingUserFrom(r)
+	run := liveMacros.Start(name, actions, user)
+
+	addresses := make([]string, 0, len(actions))
+	for _, a := range actions {
+		addresses = append(addresses, a.button.address)
+	}
+	liveButtons.Pressed(name, user)
+
+	press := newButtonPress(r, name, strings.Join(addresses, " "))
+	audited := make(chan struct{})
+	go func() {
+		defer close(audited)
+		<-run.done
+
+		switch progress := run.progress; progress.State {
+		case macroDone:
+			press.record(pressSent, nil)
+		case macroCancelled:
+			press.record(pressFailed, fmt.Errorf("cancelled at step %d: %s", progress.Step, progress.Error))
+		default:
+			press.record(pressFailed, fmt.Errorf("step %d: %s", progress.Step, progress.Error))
+		}
+	}()
+
+	if r.URL.Query().Get("wait") == "" {
+		writeJSON(w, http.StatusAccepted, macroProgress{Run: run.id, State: macroRunning, Steps: len(actions), By: user})
+
+		return
+	}
+
+	select {
+	case <-audited:
+	case <-r.Context().Done():
+		return
+	}
+
+	status := http.StatusOK
+	switch run.progress.State {
+	case macroCancelled:
+		status = http.StatusConflict
+	case macroFailed:
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, run.progress)
+}
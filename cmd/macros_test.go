@@ -0,0 +1,337 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestParseMacroSteps(t *testing.T) {
+	buttons := map[string]companionButton{
+		"green":      {address: "/location/20/1/1/press"},
+		"keylighton": {address: "/location/20/2/1/press"},
+	}
+
+	actions, err := parseMacroSteps([]macroStep{
+		{Button: "Green", Delay: 500 * time.Millisecond},
+		{Button: "keylighton", WaitFor: "keylighton"},
+		{OSC: "20/0/4 up", WaitFor: "dsk off", Timeout: time.Second},
+		{OSC: "/custom/camera", Arg: 1},
+		{OSC: "1/2/3 step 2", Arg: "fast"},
+	}, buttons, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []macroAction{
+		{label: "green", button: companionButton{address: "/location/20/1/1/press"}, delay: 500 * time.Millisecond},
+		{label: "keylighton", button: companionButton{address: "/location/20/2/1/press"}, waitFor: "keylighton", waitOn: true, timeout: macroDefaultTimeout},
+		{label: "20/0/4 up", button: companionButton{address: "/location/20/0/4/up"}, waitFor: "dsk", timeout: time.Second},
+		{label: "/custom/camera", button: companionButton{address: "/custom/camera", args: []interface{}{int32(1)}}},
+		{label: "1/2/3 step 2", button: companionButton{address: "/location/1/2/3/step", args: []interface{}{int32(2), "fast"}}},
+	}
+	if !reflect.DeepEqual(actions, want) {
+		t.Errorf("got %+v\nwant %+v", actions, want)
+	}
+	if buttons["green"].args != nil {
+		t.Error("an arg should not change the button it is added to")
+	}
+
+	cases := []struct {
+		name    string
+		steps   []macroStep
+		wantErr string
+	}{
+		{"empty", nil, "no steps"},
+		{"neither", []macroStep{{Delay: time.Second}}, "one of button or osc"},
+		{"both", []macroStep{{Button: "green", OSC: "20/1/1"}}, "one of button or osc"},
+		{"unknown button", []macroStep{{Button: "purple"}}, `"purple" is not in companion_buttons`},
+		{"bad osc", []macroStep{{OSC: "20/1"}}, "step 1:"},
+		{"bad arg", []macroStep{{Button: "green", Arg: []interface{}{1}}}, "should be a number"},
+		{"bad wait", []macroStep{{Button: "green", WaitFor: "green maybe"}}, "then on or off"},
+		{"timeout alone", []macroStep{{Button: "green", Timeout: time.Second}}, "only used with wait_for"},
+		{"negative delay", []macroStep{{Button: "green"}, {Button: "green", Delay: -time.Second}}, "step 2: delay and timeout"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseMacroSteps(tc.steps, buttons, 3); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadCompanionMacros(t *testing.T) {
+	t.Cleanup(func() { viper.Set("companion_macros", nil) })
+	buttons := map[string]companionButton{"green": {address: "/location/20/1/1/press"}}
+
+	viper.Set("companion_macros", map[string]interface{}{
+		"startmeeting": []interface{}{
+			map[string]interface{}{"button": "green", "delay": "250ms"},
+			map[string]interface{}{"osc": "20/0/4", "wait_for": "dsk", "timeout": "2s"},
+		},
+	})
+	macros, err := loadCompanionMacros(buttons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if steps := macros["startmeeting"]; len(steps) != 2 || steps[0].delay != 250*time.Millisecond || steps[1].timeout != 2*time.Second {
+		t.Errorf("startmeeting = %+v", steps)
+	}
+
+	viper.Set("companion_macros", map[string]interface{}{
+		"green": []interface{}{map[string]interface{}{"osc": "/x"}},
+		"typo":  []interface{}{map[string]interface{}{"button": "green", "dealy": "1s"}},
+	})
+	_, err = loadCompanionMacros(buttons)
+	if err == nil || !strings.Contains(err.Error(), "dealy") {
+		t.Errorf("unknown step keys should be reported, got %v", err)
+	}
+
+	viper.Set("companion_macros", map[string]interface{}{
+		"green": []interface{}{map[string]interface{}{"osc": "/x"}},
+	})
+	if _, err = loadCompanionMacros(buttons); err == nil || !strings.Contains(err.Error(), "same name") {
+		t.Errorf("a macro named like a button should be reported, got %v", err)
+	}
+}
+
+// recordingSend collects the addresses a macroRunner sends.
+type recordingSend struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (r *recordingSend) send(b companionButton) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, b.address)
+
+	return nil
+}
+
+func (r *recordingSend) addresses() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.sent...)
+}
+
+func waitMacro(t *testing.T, run *macroRun) macroProgress {
+	t.Helper()
+
+	select {
+	case <-run.done:
+		return run.progress
+	case <-time.After(2 * time.Second):
+		t.Fatal("macro did not finish")
+
+		return macroProgress{}
+	}
+}
+
+func TestMacroRunner(t *testing.T) {
+	states := newButtonStates()
+	sender := &recordingSend{}
+	runner := newMacroRunner(sender.send, states)
+
+	steps := []macroAction{
+		{label: "lights", button: companionButton{address: "/location/20/1/1/press"}, delay: 10 * time.Millisecond},
+		{label: "key light", button: companionButton{address: "/location/20/2/1/press"}, waitFor: "keylight", waitOn: true, timeout: time.Second},
+		{label: "dsk", button: companionButton{address: "/location/20/0/4/up"}},
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		states.SetActive("keylight", true)
+	}()
+
+	progress := waitMacro(t, runner.Start("startmeeting", steps, "alice"))
+	if progress.State != macroDone || progress.Step != 3 || progress.Steps != 3 || progress.By != "alice" {
+		t.Errorf("progress = %+v", progress)
+	}
+	if got := sender.addresses(); !reflect.DeepEqual(got, []string{"/location/20/1/1/press", "/location/20/2/1/press", "/location/20/0/4/up"}) {
+		t.Errorf("sent %v", got)
+	}
+	if p := states.State().Macros["startmeeting"]; p.State != macroDone {
+		t.Errorf("pages were told %+v", p)
+	}
+
+	states.SetActive("keylight", false)
+	progress = waitMacro(t, runner.Start("startmeeting", steps[1:2], "alice"))
+	if progress.State != macroFailed || !strings.Contains(progress.Error, "no feedback that keylight is on") {
+		t.Errorf("a missing ack should fail the macro, got %+v", progress)
+	}
+
+	// a button already on is not acked until feedback arrives after the send
+	states.SetActive("keylight", true)
+	quick := []macroAction{{label: "key light", button: steps[1].button, waitFor: "keylight", waitOn: true, timeout: 50 * time.Millisecond}}
+	if progress = waitMacro(t, runner.Start("startmeeting", quick, "alice")); progress.State != macroFailed {
+		t.Errorf("feedback from before the send should not ack it, got %+v", progress)
+	}
+
+	run := runner.Start("startmeeting", steps[1:2], "alice")
+	time.Sleep(20 * time.Millisecond)
+	states.SetActive("keylight", true)
+	if progress = waitMacro(t, run); progress.State != macroDone {
+		t.Errorf("repeated feedback after the send should ack it, got %+v", progress)
+	}
+}
+
+func TestMacroRunner_Cancel(t *testing.T) {
+	states := newButtonStates()
+	sender := &recordingSend{}
+	runner := newMacroRunner(sender.send, states)
+
+	slow := []macroAction{
+		{label: "lights", button: companionButton{address: "/location/20/1/1/press"}, delay: time.Minute},
+		{label: "never", button: companionButton{address: "/location/20/1/5/press"}},
+	}
+	other := []macroAction{{label: "camera", button: companionButton{address: "/location/20/3/1/press"}, delay: time.Minute}}
+
+	first := runner.Start("startmeeting", slow, "alice")
+	unrelated := runner.Start("camera", other, "carol")
+
+	// pressing another action on the same location conflicts
+	runner.CancelConflicting(companionButton{address: "/location/20/1/1/down"}, errors.New("bob pressed off"))
+
+	progress := waitMacro(t, first)
+	if progress.State != macroCancelled || progress.Error != "bob pressed off" || progress.Step != 1 {
+		t.Errorf("progress = %+v", progress)
+	}
+	for _, address := range sender.addresses() {
+		if address == "/location/20/1/5/press" {
+			t.Error("a cancelled macro should not send more steps")
+		}
+	}
+
+	select {
+	case <-unrelated.done:
+		t.Fatal("a macro on other buttons should keep running")
+	default:
+	}
+
+	again := runner.Start("camera", other, "dave")
+	if progress := waitMacro(t, unrelated); progress.State != macroCancelled || !strings.Contains(progress.Error, "dave started camera") {
+		t.Errorf("starting a macro again should restart it, got %+v", progress)
+	}
+
+	runner.CancelConflicting(other[0].button, errors.New("done testing"))
+	waitMacro(t, again)
+}
+
+func TestHandleOSC_Macro(t *testing.T) {
+	companion := oscConn(t, nil)
+	viper.Set("companion", "127.0.0.1")
+	viper.Set("companion_port", companion.LocalAddr().(*net.UDPAddr).Port)
+	viper.Set("companion_buttons", map[string]string{"green": "20/1/1"})
+	viper.Set("companion_macros", map[string]interface{}{
+		"startmeeting": []interface{}{
+			map[string]interface{}{"button": "green"},
+			map[string]interface{}{"osc": "/custom/camera", "arg": 2},
+		},
+	})
+	t.Cleanup(func() {
+		viper.Set("companion_port", nil)
+		viper.Set("companion_buttons", nil)
+		viper.Set("companion_macros", nil)
+	})
+
+	if err := checkButtonConfig(); err != nil {
+		t.Fatal(err)
+	}
+	layout, err := loadButtonLayout()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other := layout[len(layout)-1]; other.Name != otherButtonsGroup || other.Buttons[len(other.Buttons)-1].Button != "startmeeting" {
+		t.Errorf("macros should be laid out like buttons, got %+v", other)
+	}
+	if _, isButton := viper.GetStringMapString("companion_buttons")["startmeeting"]; isButton {
+		t.Error("laying out macros should not change companion_buttons")
+	}
+
+	audits := make(chan buttonPress, 8)
+	pressAudit = func(p buttonPress) { audits <- p }
+	t.Cleanup(func() { pressAudit = nil })
+	audited := func() buttonPress {
+		t.Helper()
+
+		select {
+		case p := <-audits:
+			return p
+		case <-time.After(2 * time.Second):
+			t.Fatal("the macro was not audited")
+
+			return buttonPress{}
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, buttonsPath+"/startmeeting?wait=true", nil)
+	handleOSC(w, withLightingUser(req, "alice"), buttonsPath+"/", viper.GetStringMapString("companion_buttons"))
+
+	var progress macroProgress
+	if err := json.Unmarshal(w.Body.Bytes(), &progress); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || progress.State != macroDone || progress.By != "alice" {
+		t.Errorf("status %d, progress %+v", w.Code, progress)
+	}
+
+	if got := readOSCMessage(t, companion); got.Address != "/location/20/1/1/press" {
+		t.Errorf("first step sent %s", got)
+	}
+	if got := readOSCMessage(t, companion); got.Address != "/custom/camera" || !reflect.DeepEqual(got.Arguments, []interface{}{int32(2)}) {
+		t.Errorf("second step sent %s", got)
+	}
+	if p := audited(); p.Result != pressSent || p.User != "alice" {
+		t.Errorf("a finished macro should be audited as sent, got %+v", p)
+	}
+
+	failing := []macroAction{{label: "green", button: companionButton{address: "/location/20/1/1/press"}, waitFor: "green", waitOn: true, timeout: 10 * time.Millisecond}}
+	w = httptest.NewRecorder()
+	handleMacro(w, httptest.NewRequest(http.MethodPost, buttonsPath+"/checkgreen?wait=true", nil), "checkgreen", failing)
+	readOSCMessage(t, companion)
+	if p := audited(); w.Code != http.StatusBadGateway || p.Result != pressFailed || !strings.Contains(p.Error, "no feedback that green is on") {
+		t.Errorf("status %d, a failed macro should be audited as failed, got %+v", w.Code, p)
+	}
+
+	w = httptest.NewRecorder()
+	handleOSC(w, httptest.NewRequest(http.MethodPost, buttonsPath+"/startmeeting", nil), buttonsPath+"/", viper.GetStringMapString("companion_buttons"))
+	if w.Code != http.StatusAccepted {
+		t.Errorf("without wait, status %d", w.Code)
+	}
+
+	// let the run finish before the cleanup changes its config
+	liveMacros.mu.Lock()
+	run := liveMacros.running["startmeeting"]
+	liveMacros.mu.Unlock()
+	if run != nil {
+		waitMacro(t, run)
+	}
+	audited()
+}
@@ -0,0 +1,406 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFrameDuration is how far the fake moves on a frame step (25 fps).
+const fakeFrameDuration = 0.04
+
+// fakeMpv is an in-process stand-in for mpv that serves the JSON IPC protocol
+// on a unix socket. It implements mediaPlayer, keeps a property table that
+// commands act on, sends property-change events for observed properties, and
+// records every command it receives.
+type fakeMpv struct {
+	t *testing.T
+
+	mu        sync.Mutex
+	props     map[string]interface{}
+	observed  map[string]int
+	commands  [][]interface{}
+	playlist  []string
+	displays  []string
+	conns     []net.Conn
+	listener  net.Listener
+	exited    chan error
+	exitOnce  sync.Once
+	unknownOK bool
+}
+
+func newFakeMpv(t *testing.T) *fakeMpv {
+	t.Helper()
+
+	return &fakeMpv{
+		t: t,
+		props: map[string]interface{}{
+			"pause":          true,
+			"fullscreen":     false,
+			"time-pos":       0.0,
+			"duration":       60.0,
+			"time-remaining": 60.0,
+			"percent-pos":    0.0,
+			"eof-reached":    false,
+			"keep-open":      "always",
+			"screen":         0.0,
+			"fs-screen":      0.0,
+			"display-names":  []string{"eDP-1"},
+			"volume":         100.0,
+			"mute":           false,
+			"audio-device":   "auto",
+			"audio-device-list": []map[string]string{
+				{"name": "auto", "description": "Autoselect device"},
+				{"name": "coreaudio/BuiltIn", "description": "MacBook Pro Speakers"},
+				{"name": "coreaudio/Dante", "description": "Dante Virtual Soundcard"},
+			},
+			"af-metadata/meter": map[string]string{"lavfi.astats.Overall.RMS_level": "-18.5"},
+		},
+		observed: map[string]int{},
+		displays: []string{"eDP-1"},
+		exited:   make(chan error, 1),
+	}
+}
+
+// fakeIPCName returns a short socket path; unix socket paths are limited to
+// about 100 bytes, which t.TempDir() can exceed.
+func fakeIPCName(t *testing.T) string {
+	t.Helper()
+
+	return filepath.Join(t.TempDir(), "mpv.sock")
+}
+
+// Start listens on ipcName and loads items, like mpv --input-ipc-server.
+func (f *fakeMpv) Start(ipcName string, items ...string) error {
+	l, err := net.Listen("unix", ipcName)
+	if err != nil {
+		return fmt.Errorf("fake mpv could not listen: %w", err)
+	}
+
+	f.mu.Lock()
+	f.listener = l
+	f.playlist = append(f.playlist, items...)
+	if len(items) > 0 {
+		f.props["path"] = items[0]
+	}
+	f.mu.Unlock()
+
+	f.t.Cleanup(func() { f.exit(nil) })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			f.mu.Lock()
+			f.conns = append(f.conns, conn)
+			f.mu.Unlock()
+
+			go f.serve(conn)
+		}
+	}()
+
+	return nil
+}
+
+// Wait blocks until the fake is told to quit or Crash is called.
+func (f *fakeMpv) Wait() error {
+	return <-f.exited
+}
+
+// Crash makes the fake exit as if mpv died.
+func (f *fakeMpv) Crash() {
+	f.exit(fmt.Errorf("signal: killed"))
+}
+
+func (f *fakeMpv) exit(err error) {
+	f.exitOnce.Do(func() {
+		f.mu.Lock()
+		if f.listener != nil {
+			_ = f.listener.Close()
+		}
+		for _, c := range f.conns {
+			_ = c.Close()
+		}
+		f.mu.Unlock()
+
+		f.exited <- err
+	})
+}
+
+// Commands returns a copy of every command received so far.
+func (f *fakeMpv) Commands() [][]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([][]interface{}(nil), f.commands...)
+}
+
+// Property returns the fake's current value for name.
+func (f *fakeMpv) Property(name string) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.props[name]
+}
+
+// SetProperty changes a property as playback would, notifying observers.
+func (f *fakeMpv) SetProperty(name string, value interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.setLocked(name, value)
+}
+
+// Emit sends an arbitrary event to every connection.
+func (f *fakeMpv) Emit(event map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.broadcastLocked(event)
+}
+
+// WaitForCommand waits until a command named name has been received.
+func (f *fakeMpv) WaitForCommand(name string) []interface{} {
+	f.t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, c := range f.Commands() {
+			if len(c) > 0 && c[0] == name {
+				return c
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	f.t.Fatalf("fake mpv never received %q; got %v", name, f.Commands())
+
+	return nil
+}
+
+func (f *fakeMpv) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+
+		var req struct {
+			Command   []interface{} `json:"command"`
+			RequestID int64         `json:"request_id"`
+		}
+		if err := json.Unmarshal(line, &req); err != nil || len(req.Command) == 0 {
+			f.write(conn, map[string]interface{}{"error": "invalid parameter"})
+			continue
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, req.Command)
+		data, errText := f.applyLocked(req.Command)
+		f.mu.Unlock()
+
+		reply := map[string]interface{}{"request_id": req.RequestID, "error": errText}
+		if data != nil {
+			reply["data"] = data
+		}
+		f.write(conn, reply)
+
+		if req.Command[0] == "quit" {
+			f.exit(nil)
+			return
+		}
+	}
+}
+
+// applyLocked runs one command against the property table.
+func (f *fakeMpv) applyLocked(command []interface{}) (interface{}, string) {
+	name, _ := command[0].(string)
+	args := command[1:]
+
+	switch name {
+	case "get_property", "get_property_string":
+		prop, _ := args[0].(string)
+		value, ok := f.props[prop]
+		if !ok {
+			return nil, "property not found"
+		}
+
+		return value, "success"
+	case "set_property", "set_property_string":
+		prop, _ := args[0].(string)
+		if _, ok := f.props[prop]; !ok && !f.unknownOK {
+			return nil, "property not found"
+		}
+		f.setLocked(prop, args[1])
+		if prop == "screen" {
+			f.moveToScreenLocked(args[1])
+		}
+	case "observe_property", "observe_property_string":
+		id, _ := args[0].(float64)
+		prop, _ := args[1].(string)
+		f.observed[prop] = int(id)
+		// mpv reports the current value straight away
+		if value, ok := f.props[prop]; ok {
+			defer f.notifyLocked(prop, value)
+		}
+	case "seek":
+		offset, _ := args[0].(float64)
+		pos := f.props["time-pos"].(float64) + offset
+		if len(args) > 1 && args[1] == "absolute" {
+			pos = offset
+		}
+		// mpv announces the seek before the position changes
+		f.broadcastLocked(map[string]interface{}{"event": "seek"})
+		f.setPositionLocked(pos)
+	case "frame-step", "frame-back-step":
+		step := fakeFrameDuration
+		if name == "frame-back-step" {
+			step = -step
+		}
+		f.setLocked("pause", true)
+		f.setPositionLocked(f.props["time-pos"].(float64) + step)
+	case "loadfile":
+		file, _ := args[0].(string)
+		mode := "replace"
+		if len(args) > 1 {
+			mode, _ = args[1].(string)
+		}
+		if mode == "append" || mode == "append-play" {
+			f.playlist = append(f.playlist, file)
+			break
+		}
+		f.playlist = []string{file}
+		f.loadLocked(file)
+	case "playlist-clear":
+		if current, ok := f.props["path"].(string); ok {
+			f.playlist = []string{current}
+		}
+	case "playlist-next":
+		current, _ := f.props["path"].(string)
+		for i, item := range f.playlist {
+			if item == current && i+1 < len(f.playlist) {
+				f.loadLocked(f.playlist[i+1])
+				return nil, "success"
+			}
+		}
+
+		return nil, "error running command"
+	case "screenshot-to-file":
+		// the command is recorded; there is no frame to write
+	case "stop":
+		f.broadcastLocked(map[string]interface{}{"event": "end-file", "reason": "stop"})
+	case "quit":
+	default:
+		return nil, "invalid parameter"
+	}
+
+	return nil, "success"
+}
+
+// loadLocked switches the fake to file, as mpv does when a new entry starts.
+func (f *fakeMpv) loadLocked(file string) {
+	f.setLocked("path", file)
+	f.setPositionLocked(0)
+	f.broadcastLocked(map[string]interface{}{"event": "file-loaded"})
+}
+
+// SetDisplays changes the displays the fake window can move between.
+func (f *fakeMpv) SetDisplays(names ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.displays = names
+	f.moveToScreenLocked(f.props["screen"])
+}
+
+// moveToScreenLocked updates display-names as mpv does when the window moves;
+// a screen that does not exist falls back to the first display.
+func (f *fakeMpv) moveToScreenLocked(screen interface{}) {
+	i, _ := screen.(float64)
+	if int(i) < 0 || int(i) >= len(f.displays) {
+		i = 0
+	}
+
+	f.setLocked("display-names", []string{f.displays[int(i)]})
+}
+
+// Playlist returns a copy of the fake's playlist.
+func (f *fakeMpv) Playlist() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.playlist...)
+}
+
+func (f *fakeMpv) setPositionLocked(pos float64) {
+	duration := f.props["duration"].(float64)
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > duration {
+		pos = duration
+	}
+
+	f.setLocked("time-pos", pos)
+	f.setLocked("time-remaining", duration-pos)
+	f.setLocked("percent-pos", pos/duration*100)
+}
+
+func (f *fakeMpv) setLocked(name string, value interface{}) {
+	f.props[name] = value
+	f.notifyLocked(name, value)
+}
+
+func (f *fakeMpv) notifyLocked(name string, value interface{}) {
+	id, ok := f.observed[name]
+	if !ok {
+		return
+	}
+
+	f.broadcastLocked(map[string]interface{}{
+		"event": "property-change",
+		"id":    id,
+		"name":  name,
+		"data":  value,
+	})
+}
+
+func (f *fakeMpv) broadcastLocked(event map[string]interface{}) {
+	for _, c := range f.conns {
+		f.write(c, event)
+	}
+}
+
+func (f *fakeMpv) write(conn net.Conn, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		f.t.Errorf("fake mpv could not encode %v: %v", v, err)
+		return
+	}
+	_, _ = conn.Write(append(data, '\n'))
+}
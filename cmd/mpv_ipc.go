@@ -0,0 +1,354 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mpvReplyTimeout bounds how long a command waits for mpv to answer.
+const mpvReplyTimeout = 5 * time.Second
+
+// errMpvClosed is returned for commands issued after the connection is gone.
+var errMpvClosed = errors.New("mpv connection closed")
+
+// mpvError is a command mpv received but rejected, e.g. an unknown property.
+type mpvError struct {
+	Command []interface{}
+	Message string
+}
+
+func (e *mpvError) Error() string {
+	return fmt.Sprintf("mpv rejected %v: %s", e.Command, e.Message)
+}
+
+// mpvEvent is an asynchronous mpv IPC event. Name and Data are set for
+// property-change events, Reason for end-file.
+type mpvEvent struct {
+	Event  string          `json:"event"`
+	ID     int             `json:"id"`
+	Name   string          `json:"name"`
+	Data   json.RawMessage `json:"data"`
+	Reason string          `json:"reason"`
+	Raw    string          `json:"-"`
+}
+
+// mpvMessage is any line mpv sends: a reply carries request_id and error, an
+// event carries event.
+type mpvMessage struct {
+	mpvEvent
+	RequestID int64  `json:"request_id"`
+	Error     string `json:"error"`
+}
+
+type mpvReply struct {
+	data json.RawMessage
+	err  error
+}
+
+// mpvClient speaks mpv's JSON IPC protocol. Commands are marshalled (so any
+// string is escaped correctly) and tagged with a request_id that correlates
+// them with mpv's reply; everything else mpv sends is delivered, in order and
+// without loss, on Events.
+type mpvClient struct {
+	conn net.Conn
+
+	mu       sync.Mutex
+	nextID   int64
+	pending  map[int64]chan mpvReply
+	observed map[string]int
+	queue    []mpvEvent
+	closed   bool
+	wake     chan struct{}
+
+	events chan mpvEvent
+}
+
+// dialMpv connects to the IPC socket at ipcName, retrying until timeout while
+// mpv starts up.
+func dialMpv(ipcName string, timeout time.Duration) (*mpvClient, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		conn, err := ConnectIPC(ipcName)
+		if err == nil {
+			return newMpvClient(conn), nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("could not connect to mpv at %s: %w", ipcName, err)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// newMpvClient wraps an established IPC connection and starts reading it.
+func newMpvClient(conn net.Conn) *mpvClient {
+	c := &mpvClient{
+		conn:     conn,
+		pending:  map[int64]chan mpvReply{},
+		observed: map[string]int{},
+		wake:     make(chan struct{}, 1),
+		events:   make(chan mpvEvent),
+	}
+
+	go c.read()
+	go c.deliver()
+
+	return c
+}
+
+// Events returns the event stream. It is closed when the connection ends.
+func (c *mpvClient) Events() <-chan mpvEvent {
+	return c.events
+}
+
+// Command sends a command and waits for mpv's reply, returning its data.
+func (c *mpvClient) Command(args ...interface{}) (json.RawMessage, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errMpvClosed
+	}
+
+	c.nextID++
+	id := c.nextID
+	reply := make(chan mpvReply, 1)
+	c.pending[id] = reply
+
+	line, err := json.Marshal(map[string]interface{}{"command": args, "request_id": id})
+	if err == nil {
+		_, err = c.conn.Write(append(line, '\n'))
+	}
+	if err != nil {
+		delete(c.pending, id)
+		c.mu.Unlock()
+
+		return nil, fmt.Errorf("could not send mpv command %v: %w", args, err)
+	}
+	c.mu.Unlock()
+
+	select {
+	case r := <-reply:
+		var rejected *mpvError
+		if errors.As(r.err, &rejected) {
+			rejected.Command = args
+		}
+
+		return r.data, r.err
+	case <-time.After(mpvReplyTimeout):
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+
+		return nil, fmt.Errorf("no reply from mpv to %v", args)
+	}
+}
+
+// SetProperty sets an mpv property.
+func (c *mpvClient) SetProperty(name string, value interface{}) error {
+	_, err := c.Command("set_property", name, value)
+
+	return err
+}
+
+// GetProperty reads an mpv property into v.
+func (c *mpvClient) GetProperty(name string, v interface{}) error {
+	data, err := c.Command("get_property", name)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("could not decode mpv property %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// ObserveProperty asks mpv to report changes to a property. The resulting
+// property-change events carry the property name, so callers match on Name
+// rather than tracking observe IDs. Observing the same name twice is a no-op.
+func (c *mpvClient) ObserveProperty(name string) error {
+	c.mu.Lock()
+	if _, ok := c.observed[name]; ok {
+		c.mu.Unlock()
+		return nil
+	}
+
+	id := len(c.observed) + 1
+	c.observed[name] = id
+	c.mu.Unlock()
+
+	_, err := c.Command("observe_property", id, name)
+
+	return err
+}
+
+// Close ends the connection; pending commands fail and Events is closed.
+func (c *mpvClient) Close() error {
+	return c.conn.Close()
+}
+
+// read dispatches each line from mpv to a waiting command or the event queue.
+func (c *mpvClient) read() {
+	r := bufio.NewReader(c.conn)
+
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			c.dispatch(line)
+		}
+
+		if err != nil {
+			c.shutdown()
+			return
+		}
+	}
+}
+
+func (c *mpvClient) dispatch(line []byte) {
+	var msg mpvMessage
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if msg.Event != "" {
+		msg.mpvEvent.Raw = string(bytes.TrimRight(line, "\r\n"))
+		c.queue = append(c.queue, msg.mpvEvent)
+		c.signal()
+
+		return
+	}
+
+	reply, ok := c.pending[msg.RequestID]
+	if !ok {
+		return
+	}
+	delete(c.pending, msg.RequestID)
+
+	if msg.Error != "" && msg.Error != "success" {
+		reply <- mpvReply{err: &mpvError{Message: msg.Error}}
+	} else {
+		reply <- mpvReply{data: msg.Data}
+	}
+}
+
+// shutdown fails pending commands and lets deliver close Events once the
+// queued events are drained.
+func (c *mpvClient) shutdown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	for id, reply := range c.pending {
+		reply <- mpvReply{err: errMpvClosed}
+		delete(c.pending, id)
+	}
+	c.signal()
+}
+
+func (c *mpvClient) signal() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver feeds queued events to Events so a slow consumer never blocks the
+// reader (and with it, command replies).
+func (c *mpvClient) deliver() {
+	for range c.wake {
+		for {
+			c.mu.Lock()
+			if len(c.queue) == 0 {
+				closed := c.closed
+				c.mu.Unlock()
+
+				if closed {
+					close(c.events)
+					return
+				}
+
+				break
+			}
+
+			ev := c.queue[0]
+			c.queue = c.queue[1:]
+			c.mu.Unlock()
+
+			c.events <- ev
+		}
+	}
+}
+
+// mpvConnectedMsg reports a ready mpv client to a bubbletea model.
+type mpvConnectedMsg struct {
+	client *mpvClient
+}
+
+// mpvEventMsg carries one mpv event to a bubbletea model.
+type mpvEventMsg mpvEvent
+
+// mpvClosedMsg reports that the mpv connection ended.
+type mpvClosedMsg struct{}
+
+// mpvErrMsg surfaces a failed mpv command to a bubbletea model.
+type mpvErrMsg struct {
+	err error
+}
+
+// waitForMpvEvent returns a command that waits for the next mpv event.
+func waitForMpvEvent(c *mpvClient) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-c.Events()
+		if !ok {
+			return mpvClosedMsg{}
+		}
+
+		return mpvEventMsg(ev)
+	}
+}
+
+// mpvCommands returns a command that sends each command in order, stopping
+// at the first failure.
+func mpvCommands(c *mpvClient, commands ...[]interface{}) tea.Cmd {
+	return func() tea.Msg {
+		if c == nil {
+			return mpvErrMsg{errors.New("mpv is not connected")}
+		}
+
+		for _, command := range commands {
+			if _, err := c.Command(command...); err != nil {
+				return mpvErrMsg{err}
+			}
+		}
+
+		return nil
+	}
+}
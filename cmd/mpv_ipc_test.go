@@ -0,0 +1,162 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+// pipeMpv returns a client wired to the test's end of an in-memory pipe.
+func pipeMpv(t *testing.T) (*mpvClient, net.Conn, *bufio.Reader) {
+	t.Helper()
+	clientEnd, serverEnd := net.Pipe()
+	client := newMpvClient(clientEnd)
+	t.Cleanup(func() { _ = client.Close(); _ = serverEnd.Close() })
+
+	return client, serverEnd, bufio.NewReader(serverEnd)
+}
+
+type sentCommand struct {
+	Command   []interface{} `json:"command"`
+	RequestID int64         `json:"request_id"`
+}
+
+func readCommand(t *testing.T, r *bufio.Reader) sentCommand {
+	t.Helper()
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		t.Fatalf("read command: %v", err)
+	}
+	var cmd sentCommand
+	if err := json.Unmarshal(line, &cmd); err != nil {
+		t.Fatalf("command is not valid JSON: %q: %v", line, err)
+	}
+	return cmd
+}
+
+func TestMpvClient_EscapesArguments(t *testing.T) {
+	client, server, r := pipeMpv(t)
+	path := `/shows/the "big" one\n.mp4`
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Command("loadfile", path, "replace")
+		done <- err
+	}()
+
+	cmd := readCommand(t, r)
+	if cmd.Command[1] != path {
+		t.Errorf("path = %q, want %q", cmd.Command[1], path)
+	}
+	fmt.Fprintf(server, `{"request_id":%d,"error":"success"}`+"\n", cmd.RequestID)
+
+	if err := <-done; err != nil {
+		t.Errorf("Command: %v", err)
+	}
+}
+
+func TestMpvClient_CorrelatesRepliesAndErrors(t *testing.T) {
+	client, server, r := pipeMpv(t)
+
+	type result struct {
+		data json.RawMessage
+		err  error
+	}
+	volume := make(chan result, 1)
+	bogus := make(chan result, 1)
+
+	go func() {
+		data, err := client.Command("get_property", "volume")
+		volume <- result{data, err}
+	}()
+	first := readCommand(t, r)
+	go func() {
+		data, err := client.Command("get_property", "bogus")
+		bogus <- result{data, err}
+	}()
+	second := readCommand(t, r)
+
+	// answer out of order, with an event in between
+	fmt.Fprintf(server, `{"request_id":%d,"error":"property not found"}`+"\n"+
+		`{"event":"pause"}`+"\n"+
+		`{"request_id":%d,"error":"success","data":42.5}`+"\n",
+		second.RequestID, first.RequestID)
+
+	got := <-volume
+	if got.err != nil || string(got.data) != "42.5" {
+		t.Errorf("volume = %s, %v", got.data, got.err)
+	}
+
+	got = <-bogus
+	var rejected *mpvError
+	if !errors.As(got.err, &rejected) || rejected.Message != "property not found" {
+		t.Errorf("expected mpvError, got %v", got.err)
+	}
+
+	ev := <-client.Events()
+	if ev.Event != "pause" {
+		t.Errorf("event = %+v", ev)
+	}
+}
+
+func TestMpvClient_EventStreamIsLineBuffered(t *testing.T) {
+	client, server, r := pipeMpv(t)
+
+	go func() {
+		_ = client.ObserveProperty("time-pos")
+	}()
+	cmd := readCommand(t, r)
+	if cmd.Command[0] != "observe_property" || cmd.Command[2] != "time-pos" {
+		t.Fatalf("unexpected observe command %v", cmd.Command)
+	}
+
+	// one write holding a reply, several events, and a partial line
+	go func() {
+		fmt.Fprintf(server, `{"request_id":%d,"error":"success"}`+"\n"+
+			`{"event":"property-change","id":1,"name":"time-pos","data":1.5}`+"\n"+
+			`{"event":"property-change","id":1,"name":"time-pos","data":2.5}`+"\n"+
+			`{"event":"property-ch`, cmd.RequestID)
+		fmt.Fprint(server, `ange","id":1,"name":"time-pos","data":3.5}`+"\n")
+		_ = server.Close()
+	}()
+
+	var positions []string
+	for ev := range client.Events() {
+		positions = append(positions, string(ev.Data))
+	}
+	if fmt.Sprint(positions) != "[1.5 2.5 3.5]" {
+		t.Errorf("positions = %v", positions)
+	}
+
+	if _, err := client.Command("stop"); !errors.Is(err, errMpvClosed) {
+		t.Errorf("command after close = %v, want errMpvClosed", err)
+	}
+}
+
+func TestMpvClient_DialTimesOut(t *testing.T) {
+	start := time.Now()
+	if _, err := dialMpv(t.TempDir()+"/missing.sock", 200*time.Millisecond); err == nil {
+		t.Fatal("expected dial error")
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("dial should give up after its timeout")
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/rs/zerolog/log"
+)
+
+// oscReplyFunc sends a message back to the sender of the message being handled.
+type oscReplyFunc func(msg *osc.Message)
+
+// oscHandlerFunc handles one OSC message and may reply to its sender.
+type oscHandlerFunc func(msg *osc.Message, reply oscReplyFunc)
+
+// oscServer is a small OSC listener that, unlike osc.Server, tells handlers
+// who sent each message so they can answer on the same socket. Companion
+// listens for feedback on the port it sent from.
+type oscServer struct {
+	addr     string
+	mu       sync.Mutex
+	handlers map[string]oscHandlerFunc
+	conn     net.PacketConn
+	// peers remembers when each sender was last heard from
+	peers map[string]oscPeer
+}
+
+type oscPeer struct {
+	addr net.Addr
+	seen time.Time
+}
+
+func newOSCServer(addr string) *oscServer {
+	return &oscServer{addr: addr, handlers: map[string]oscHandlerFunc{}, peers: map[string]oscPeer{}}
+}
+
+// Handle registers h for messages sent to the exact OSC address.
+func (s *oscServer) Handle(address string, h oscHandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[address] = h
+}
+
+// Listen binds the UDP socket; Serve must be called to process messages.
+func (s *oscServer) Listen() error {
+	conn, err := net.ListenPacket("udp", s.addr)
+	if err != nil {
+		return fmt.Errorf("could not listen for OSC on %s: %w", s.addr, err)
+	}
+	s.conn = conn
+
+	return nil
+}
+
+// LocalAddr reports the bound address, useful when listening on port 0.
+func (s *oscServer) LocalAddr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// ListenAndServe binds the UDP socket and dispatches messages until Close.
+func (s *oscServer) ListenAndServe() error {
+	if err := s.Listen(); err != nil {
+		return err
+	}
+
+	return s.Serve()
+}
+
+// Serve reads packets from the bound socket and dispatches each message.
+func (s *oscServer) Serve() error {
+	buf := make([]byte, 65535)
+	for {
+		n, from, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		packet, err := osc.ParsePacket(string(buf[:n]))
+		if err != nil {
+			log.Debug().Err(err).Msgf("ignoring malformed OSC packet from %s", from)
+			continue
+		}
+
+		s.mu.Lock()
+		s.peers[from.String()] = oscPeer{addr: from, seen: time.Now()}
+		s.mu.Unlock()
+
+		s.dispatch(packet, from)
+	}
+}
+
+// Peers lists the senders heard from within window, forgetting older ones.
+func (s *oscServer) Peers(window time.Duration) []net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var peers []net.Addr
+	for key, p := range s.peers {
+		if time.Since(p.seen) > window {
+			delete(s.peers, key)
+			continue
+		}
+		peers = append(peers, p.addr)
+	}
+
+	return peers
+}
+
+// Close stops Serve by closing the socket.
+func (s *oscServer) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+
+	return s.conn.Close()
+}
+
+func (s *oscServer) dispatch(packet osc.Packet, from net.Addr) {
+	switch p := packet.(type) {
+	case *osc.Message:
+		s.mu.Lock()
+		h, found := s.handlers[p.Address]
+		s.mu.Unlock()
+
+		if !found {
+			log.Debug().Msgf("no OSC handler for %s", p.Address)
+			return
+		}
+
+		h(p, func(reply *osc.Message) { s.SendTo(reply, from) })
+	case *osc.Bundle:
+		for _, m := range p.Messages {
+			s.dispatch(m, from)
+		}
+		for _, b := range p.Bundles {
+			s.dispatch(b, from)
+		}
+	}
+}
+
+// SendTo sends msg from the listening socket, so replies come from the port
+// controllers send to.
+func (s *oscServer) SendTo(msg *osc.Message, to net.Addr) {
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		log.Error().Err(err).Msgf("could not encode OSC reply %s", msg.Address)
+		return
+	}
+
+	if _, err := s.conn.WriteTo(data, to); err != nil {
+		log.Debug().Err(err).Msgf("could not send OSC reply %s to %s", msg.Address, to)
+	}
+}
+
+// oscFloatArg reads a numeric OSC argument as float64.
+func oscFloatArg(msg *osc.Message, i int) (float64, bool) {
+	if i >= len(msg.Arguments) {
+		return 0, false
+	}
+
+	switch v := msg.Arguments[i].(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// oscStringArg reads a string OSC argument.
+func oscStringArg(msg *osc.Message, i int) (string, bool) {
+	if i >= len(msg.Arguments) {
+		return "", false
+	}
+
+	s, ok := msg.Arguments[i].(string)
+
+	return s, ok
+}
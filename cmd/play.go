@@ -15,16 +15,13 @@
 package cmd
 
 import (
+	"bytes"
 	"container/list"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/help"
@@ -35,14 +32,26 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/coral"
 	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
 )
 
 var playCmd = &coral.Command{
-	Use:   "play <videofile.mp4>",
-	Short: "Play a videofile fullscreen with mpv player.",
-	Long:  `Use mpv video player to play a video file fullscreen on the designated display.`,
-	Run:   play,
-	Args:  coral.ExactArgs(1),
+	Use:   "play <videofile.mp4|directory|playlist.m3u>...",
+	Short: "Play video files fullscreen with mpv player.",
+	Long: `Use mpv video player to play a queue of video files fullscreen on the
+designated display. Directories add their media files in name order and m3u
+playlists add their entries. The next item is preloaded so transitions are
+gapless.`,
+	Example: `vbs play --screen 1 opener.mp4 ./segments/
+vbs play --list-screens`,
+	Run: play,
+	Args: func(cmd *coral.Command, args []string) error {
+		if playListScreens {
+			return nil
+		}
+
+		return coral.MinimumNArgs(1)(cmd, args)
+	},
 }
 
 // keyMap defines a set of keybindings. To work for help it must satisfy
@@ -57,6 +66,18 @@ type keyMap struct {
 	Fullscreen key.Binding
 	Play       key.Binding
 	Debug      key.Binding
+	Next       key.Binding
+	Previous   key.Binding
+	Auto       key.Binding
+	Screen     key.Binding
+	NextChap   key.Binding
+	PrevChap   key.Binding
+	GoTo       key.Binding
+	VolumeUp   key.Binding
+	VolumeDown key.Binding
+	Mute       key.Binding
+	Audio      key.Binding
+	Grab       key.Binding
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view. It's part
@@ -69,9 +90,12 @@ func (k keyMap) ShortHelp() []key.Binding {
 // key.Map interface.
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.Down, k.Left, k.Right}, // first column
-		{k.Help, k.Quit, k.Debug},       // second column
-		{k.Play, k.Fullscreen},          // third column
+		{k.Up, k.Down, k.Left, k.Right},             // first column
+		{k.Help, k.Quit, k.Debug},                   // second column
+		{k.Play, k.Fullscreen, k.Screen, k.Grab},    // third column
+		{k.Next, k.Previous, k.Auto},                // queue
+		{k.NextChap, k.PrevChap, k.GoTo},            // chapters
+		{k.VolumeUp, k.VolumeDown, k.Mute, k.Audio}, // audio
 	}
 }
 
@@ -104,6 +128,54 @@ var keys = keyMap{
 		key.WithKeys("right", "l"),
 		key.WithHelp("→/l", "forward one frame"),
 	),
+	Next: key.NewBinding(
+		key.WithKeys("n", "pgdown"),
+		key.WithHelp("n", "next item"),
+	),
+	Previous: key.NewBinding(
+		key.WithKeys("b", "pgup"),
+		key.WithHelp("b", "previous item"),
+	),
+	NextChap: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next chapter"),
+	),
+	PrevChap: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous chapter"),
+	),
+	GoTo: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "go to timecode"),
+	),
+	VolumeUp: key.NewBinding(
+		key.WithKeys("+", "="),
+		key.WithHelp("+", "volume up"),
+	),
+	VolumeDown: key.NewBinding(
+		key.WithKeys("-", "_"),
+		key.WithHelp("-", "volume down"),
+	),
+	Mute: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "mute"),
+	),
+	Audio: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "audio output"),
+	),
+	Grab: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "grab still"),
+	),
+	Screen: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "next screen"),
+	),
+	Auto: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "toggle auto-advance"),
+	),
 	Help: key.NewBinding(
 		key.WithKeys("?"),
 		key.WithHelp("?", "toggle help"),
@@ -118,15 +190,33 @@ type model struct {
 	terminalWidth  int
 	terminalHeight int
 	currentItem    string
+	queue          []queueItem
+	queueIndex     int
+	autoAdvance    bool
 	remainingTime  float64
-	outputScreen   int8
+	position       float64
+	outputScreen   int
+	displays       []string // names by screen index, probed at startup
+	chapters       []playChapter
+	chapterSource  func(string) (ffmprobeResponse, error) // nil without ffprobe
+	entering       bool                                   // go-to-timecode prompt is open
+	entry          string
+	entryErr       string
+	asRun          *asRunTracker // nil unless an as-run directory is configured
+	volume         float64
+	muted          bool
+	level          float64 // audio level in dB from the meter filter
+	audioDevice    string
+	audioDevices   []audioDevice
+	picking        bool // audio device picker is open
+	pickIndex      int
+	lastGrab       string
 	fullScreen     bool
 	playing        bool
 	debug          *list.List
 	showDebug      bool
-	controlSocket  net.Conn
-	sub            chan responseMsg // event channel
-	responses      int              // how many responses we've received
+	mpv            *mpvClient
+	responses      int // how many responses we've received
 	spinner        spinner.Model
 	keys           keyMap
 	help           help.Model
@@ -135,11 +225,16 @@ type model struct {
 	percent        float64
 	progress       progress.Model
 	ipcName        string
+	status         *playerStatus // shared with remote control listeners
+	exitErr        error         // set when mpv exits without being asked to
 }
 
-func initialModel(file string) model {
+func initialModel(files ...string) model {
 	m := model{
-		currentItem:    file,
+		currentItem:    files[0],
+		queue:          newQueue(files, nil),
+		queueIndex:     0,
+		autoAdvance:    false,
 		terminalWidth:  0,
 		terminalHeight: 0,
 		fullScreen:     false,
@@ -148,8 +243,7 @@ func initialModel(file string) model {
 		playing:        false,
 		debug:          list.New(),
 		showDebug:      false,
-		controlSocket:  nil,
-		sub:            make(chan responseMsg),
+		mpv:            nil,
 		responses:      0,
 		spinner:        spinner.New(),
 		keys:           keys,
@@ -158,6 +252,10 @@ func initialModel(file string) model {
 		quitting:       false,
 		percent:        0,
 		ipcName:        "",
+		status:         &playerStatus{},
+		volume:         100,
+		level:          meterFloor,
+		audioDevice:    "auto",
 		progress:       progress.New(progress.WithScaledGradient("#FF7CCB", "#FDFF8C")),
 	}
 	m.spinner.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("69"))
@@ -209,46 +307,45 @@ func (m model) Init() tea.Cmd {
 	return tea.Batch(
 		tea.EnterAltScreen,
 		spinner.Tick,
-		cmdInitializeControlSocket(&m),
-		waitForActivity(m.sub), // wait for mpv event to be sent on channel
+		cmdInitializeControlSocket(m.ipcName),
+		meterTick(),
 	)
 }
 
 type vbsQuit int
 
-func writeMpvSocket(m model, msg string) {
-	_, err := m.controlSocket.Write([]byte(msg))
-
-	if err != nil {
-		log.Fatal().Err(err).Msgf("Could not send %s command", msg)
-	}
-}
+// genericMpvCommand sends one command to mpv and then delivers msg.
+func genericMpvCommand(m model, msg tea.Msg, args ...interface{}) tea.Cmd {
+	client := m.mpv
 
-func genericMpvCommand(m model, command string, id int, msg tea.Msg, args ...string) tea.Cmd {
 	return func() tea.Msg {
-		cmd := fmt.Sprintf("{ \"command\": [\"%s\"", command)
-		if id != 0 {
-			cmd += fmt.Sprintf(", %d", id)
+		if client == nil {
+			return mpvErrMsg{fmt.Errorf("mpv is not connected, dropped %v", args)}
 		}
 
-		for _, arg := range args {
-			cmd += fmt.Sprintf(", \"%s\"", arg)
+		if _, err := client.Command(args...); err != nil {
+			return mpvErrMsg{err}
 		}
 
-		cmd += "] }\n"
-		pushDebugList(&m, cmd)
-		writeMpvSocket(m, cmd)
-
 		return msg
 	}
 }
 
 func cmdQuitMpv(m model) tea.Cmd {
-	return genericMpvCommand(m, "quit", 0, vbsQuit(0))
+	return quitMpv(m.mpv)
 }
 
-func cmdObserveTimeRemainingMpv(m model) tea.Cmd {
-	return genericMpvCommand(m, "observe_property_string", 1, nil, "percent-pos")
+// quitMpv asks mpv to quit off the UI goroutine, so a hung mpv cannot
+// freeze the screen, then delivers vbsQuit.
+func quitMpv(client *mpvClient) tea.Cmd {
+	return func() tea.Msg {
+		if client != nil {
+			// mpv may exit before it answers, so there is no reply to wait for
+			_, _ = client.Command("quit")
+		}
+
+		return vbsQuit(0)
+	}
 }
 
 func cmdBackOneFrameMpv(m model) tea.Cmd {
@@ -257,7 +354,7 @@ func cmdBackOneFrameMpv(m model) tea.Cmd {
 		return nil
 	}
 
-	return genericMpvCommand(m, "frame-back-step", 0, nil)
+	return genericMpvCommand(m, nil, "frame-back-step")
 }
 
 func cmdForwardOneFrameMpv(m model) tea.Cmd {
@@ -266,137 +363,120 @@ func cmdForwardOneFrameMpv(m model) tea.Cmd {
 		return nil
 	}
 
-	return genericMpvCommand(m, "frame-step", 0, nil)
+	return genericMpvCommand(m, nil, "frame-step")
 }
 
 func cmdForwardFiveSecondsMpv(m model) tea.Cmd {
-	return genericMpvCommand(m, "seek", 5, nil)
+	return cmdSeekMpv(m, 5)
 }
 
 func cmdBackwardFiveSecondsMpv(m model) tea.Cmd {
-	return genericMpvCommand(m, "seek", -5, nil)
+	return cmdSeekMpv(m, -5)
 }
 
-func cmdPlayMpv(m model) tea.Cmd {
-	return func() tea.Msg {
-		cmd := fmt.Sprintf("{ \"command\": [\"set_property\", \"pause\", %t] }\n", m.playing)
-		writeMpvSocket(m, cmd)
-
-		return nil
-	}
+func cmdSeekMpv(m model, seconds float64) tea.Cmd {
+	return genericMpvCommand(m, nil, "seek", seconds)
 }
 
-type MpvCommandId int
+func cmdPlayMpv(m model) tea.Cmd {
+	return genericMpvCommand(m, nil, "set_property", "pause", m.playing)
+}
 
-const (
-	Position MpvCommandId = iota
-	PlaytimeRemaining
-)
+// playObservedProperties are the mpv properties the player view tracks.
+var playObservedProperties = []string{
+	"percent-pos", "time-remaining", "time-pos", "pause", "path", "eof-reached",
+	"volume", "mute", "audio-device",
+}
 
-func cmdInitializeControlSocket(m *model) tea.Cmd {
+func cmdInitializeControlSocket(ipcName string) tea.Cmd {
 	return func() tea.Msg {
-		i := 0
-		c, err := ConnectIPC(m.ipcName)
-
-		for ; err != nil; i++ {
-			time.Sleep(100 * time.Millisecond)
-
-			c, err = ConnectIPC(m.ipcName)
-			if err == nil {
-				break
-			}
-		}
-
+		client, err := dialMpv(ipcName, 10*time.Second)
 		if err != nil {
-			pushDebugList(m, fmt.Sprintf("Could not connect to mpv: %s", err))
-		} else {
-			pushDebugList(m, "Connected to mpv")
-			m.controlSocket = c
+			return mpvErrMsg{err}
 		}
 
-		go func() {
-			for {
-				bufferSize := 4096
-				response := make([]byte, bufferSize)
-				count, err := c.Read(response)
-
-				if err != nil {
-					log.Error().Err(err).Msg("Could not read response")
-				}
-
-				//log.Debug().Msgf("Got %v byte response: %s", count, response[:count])
-				responseEvent := string(response[:count])
-				m.sub <- responseMsg{
-					event: strings.Split(responseEvent, "\n")[0],
-				}
+		for _, name := range playObservedProperties {
+			if err := client.ObserveProperty(name); err != nil {
+				return mpvErrMsg{err}
 			}
-		}()
-
-		// request notification of percent remaining events
-		cmd := fmt.Sprintf("{ \"command\": [\"observe_property_string\", %d, \"percent-pos\"]}\n", Position)
-		writeMpvSocket(*m, cmd)
-		// request notification of time-remaining events
-		cmd = fmt.Sprintf("{ \"command\": [\"observe_property_string\", %d, \"time-remaining\"]}\n", PlaytimeRemaining)
-		writeMpvSocket(*m, cmd)
+		}
 
-		return vbsSetControlSocket{Socket: c}
+		return mpvConnectedMsg{client: client}
 	}
 }
 
 func cmdFullscreenMpv(m model) tea.Cmd {
-	fullScreen := "no"
-	if m.fullScreen {
-		fullScreen = "yes"
-	}
-
-	return genericMpvCommand(m, "set_property", 0, nil, "fullscreen", fullScreen)
-}
-
-type progressMessage struct {
-	Event string
-	Id    MpvCommandId
-	Name  string
-	Data  string
-}
-
-type vbsSetControlSocket struct {
-	Socket net.Conn
+	return genericMpvCommand(m, nil, "set_property", "fullscreen", m.fullScreen)
 }
 
-func updateModelFromEvent(m *model, event string) {
+func updateModelFromEvent(m *model, event mpvEvent) {
 	scaleFactor := float64(100.0)
 
-	// parse the json unstructured
-	// check if this is an event
-	// else does it have a request_id?
+	m.asRun.Observe(event)
 
-	var p progressMessage
-	err := json.Unmarshal([]byte(event), &p)
+	if event.Event != "property-change" {
+		pushDebugList(m, event.Raw)
+		return
+	}
 
-	if err != nil {
-		// if not, lets log the message
-		pushDebugList(m, event)
-	} else {
-		switch p.Id {
-		case Position:
-			if p.Name == "percent-pos" {
-				position, _ := strconv.ParseFloat(p.Data, 64)
-				m.percent = position / scaleFactor
-				pushDebugList(m, event)
-			} else {
-				pushDebugList(m, fmt.Sprintf("Bad data match %s", event))
-			}
+	switch event.Name {
+	case "pause":
+		var paused bool
+		if json.Unmarshal(event.Data, &paused) == nil {
+			// keep-open pauses at the end of a file, so follow mpv
+			m.playing = !paused
+		}
 
-		case PlaytimeRemaining:
-			remaining, _ := strconv.ParseFloat(p.Data, 64)
-			m.remainingTime = remaining
-		default:
-			pushDebugList(m, event)
+		return
+	case "path":
+		var path string
+		if json.Unmarshal(event.Data, &path) == nil && path != "" {
+			syncQueue(m, path)
 		}
+
+		return
+	case "mute":
+		_ = json.Unmarshal(event.Data, &m.muted)
+
+		return
+	case "audio-device":
+		_ = json.Unmarshal(event.Data, &m.audioDevice)
+
+		return
+	}
+
+	var value float64
+	if err := json.Unmarshal(event.Data, &value); err != nil {
+		// properties are null while no file is loaded
+		return
+	}
+
+	switch event.Name {
+	case "percent-pos":
+		m.percent = value / scaleFactor
+		pushDebugList(m, event.Raw)
+	case "time-remaining":
+		m.remainingTime = value
+	case "time-pos":
+		m.position = value
+	case "volume":
+		m.volume = value
+	default:
+		pushDebugList(m, event.Raw)
 	}
 }
 
+// Update applies msg and publishes the resulting state for remote controllers.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if nm, ok := next.(model); ok {
+		nm.publishStatus()
+	}
+
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.terminalHeight = msg.Height
@@ -414,28 +494,79 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case vbsQuit:
 		// we finished our cleanup, exit bubbletea
 		return m, tea.Quit
+	case mpvExitedMsg:
+		// the mpv window is gone, so there is nothing left to control
+		if !m.quitting {
+			m.exitErr = msg.err
+		}
+
+		return m, tea.Quit
 
-	case vbsSetControlSocket:
-		m.controlSocket = msg.Socket
+	case mpvConnectedMsg:
+		m.mpv = msg.client
+		pushDebugList(&m, "Connected to mpv")
+
+		return m, waitForMpvEvent(m.mpv)
+	case mpvErrMsg:
+		pushDebugList(&m, msg.err.Error())
+	case mpvClosedMsg:
+		pushDebugList(&m, "mpv connection closed")
+	case meterTickMsg:
+		return m, tea.Batch(cmdReadMeterMpv(m), meterTick())
+	case meterMsg:
+		m.level = msg.level
+	case audioDevicesMsg:
+		m = m.openAudioPicker(msg.devices)
+	case stillGrabbedMsg:
+		m.lastGrab = msg.path
+		pushDebugList(&m, "Grabbed "+msg.path)
+	case audioDeviceChangedMsg:
+		m.audioDevice = msg.device
+		pushDebugList(&m, "Audio output "+msg.device)
+	case chaptersMsg:
+		if msg.path == m.currentItem {
+			m.chapters = msg.chapters
+		}
+	case screenChangedMsg:
+		m.outputScreen = msg.screen
 
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
 
 		return m, cmd
-	case responseMsg:
+	case mpvEventMsg:
 		m.responses++ // record external activity
-		updateModelFromEvent(&m, msg.event)
+		previous := m.currentItem
+		updateModelFromEvent(&m, mpvEvent(msg))
+
+		if msg.Name == "path" {
+			// a new file is on screen; queue up the one after it
+			if m.currentItem != previous {
+				m.chapters = nil
+			}
 
-		return m, waitForActivity(m.sub) // wait for next event
+			return m, tea.Batch(cmdPreloadNextMpv(m), waitForMpvEvent(m.mpv), cmdLoadChapters(m, m.currentItem))
+		}
+
+		return m, waitForMpvEvent(m.mpv) // wait for next event
+	case remoteCommandMsg:
+		return m.handleRemote(msg)
 	// Is it a key press?
 	case tea.KeyMsg:
+		m.asRun.Operator(msg.String())
+
+		if m.entering {
+			return m.updateTimecodeEntry(msg)
+		}
+
+		if m.picking {
+			return m.updateAudioPicker(msg)
+		}
+
 		switch {
 		case key.Matches(msg, m.keys.Play):
-			playCmdClosure := cmdPlayMpv(m)
-			m.playing = !m.playing
-
-			return m, playCmdClosure
+			return m.togglePlay()
 		case key.Matches(msg, m.keys.Fullscreen):
 			m.fullScreen = !m.fullScreen
 
@@ -448,6 +579,32 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmdBackOneFrameMpv(m)
 		case key.Matches(msg, m.keys.Right):
 			return m, cmdForwardOneFrameMpv(m)
+		case key.Matches(msg, m.keys.Next):
+			return m, cmdJumpMpv(m, m.queueIndex+1)
+		case key.Matches(msg, m.keys.Previous):
+			return m, cmdJumpMpv(m, m.queueIndex-1)
+		case key.Matches(msg, m.keys.Screen):
+			return m, cmdCycleScreenMpv(m)
+		case key.Matches(msg, m.keys.NextChap):
+			return m, cmdNextChapterMpv(m)
+		case key.Matches(msg, m.keys.PrevChap):
+			return m, cmdPreviousChapterMpv(m)
+		case key.Matches(msg, m.keys.VolumeUp):
+			return m, cmdVolumeMpv(m, volumeStep)
+		case key.Matches(msg, m.keys.VolumeDown):
+			return m, cmdVolumeMpv(m, -volumeStep)
+		case key.Matches(msg, m.keys.Mute):
+			return m, cmdMuteMpv(m)
+		case key.Matches(msg, m.keys.Grab):
+			return m, cmdGrabStillMpv(m)
+		case key.Matches(msg, m.keys.Audio):
+			return m, cmdListAudioDevicesMpv(m)
+		case key.Matches(msg, m.keys.GoTo):
+			m.entering = true
+		case key.Matches(msg, m.keys.Auto):
+			m.autoAdvance = !m.autoAdvance
+
+			return m, cmdAutoAdvanceMpv(m)
 		case key.Matches(msg, m.keys.Help):
 			m.help.ShowAll = !m.help.ShowAll
 		case key.Matches(msg, m.keys.Quit):
@@ -463,6 +620,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// togglePlay flips between playing and paused.
+func (m model) togglePlay() (tea.Model, tea.Cmd) {
+	playCmdClosure := cmdPlayMpv(m)
+	m.playing = !m.playing
+
+	return m, playCmdClosure
+}
+
 func (m model) View() string {
 	// The header
 	h := titleStyle().Render("VBS player")
@@ -470,10 +635,14 @@ func (m model) View() string {
 
 	// Render the row
 	s := fmt.Sprintf("\nCurrent item: %v\n", m.currentItem)
-	s += fmt.Sprintf("Remaining time: %v\n", m.remainingTime)
-	s += fmt.Sprintf("Output screen: %v\n", m.outputScreen)
+	s += fmt.Sprintf("Position: %s\n", formatTimecode(m.position))
+	s += fmt.Sprintf("Remaining time: %s\n", formatTimecode(m.remainingTime))
+	s += fmt.Sprintf("Output screen: %v\n", m.screenName())
 	s += fmt.Sprintf("Fullscreen: %v\n", m.fullScreen)
 	s += fmt.Sprintf("Playing: %v\n", m.playing)
+	if m.lastGrab != "" {
+		s += fmt.Sprintf("Last still: %v\n", filepath.Base(m.lastGrab))
+	}
 
 	infoStyle := lipgloss.NewStyle().
 		//BorderStyle(lipgloss.HiddenBorder()).
@@ -483,6 +652,18 @@ func (m model) View() string {
 	s = lipgloss.JoinHorizontal(lipgloss.Top, infoStyle.Render(s), h)
 	s += fmt.Sprintf("\n%s Events received: %d\n\n", m.spinner.View(), m.responses)
 	s += "\n" + m.progress.ViewAs(m.percent) + "\n\n"
+	s += m.audioView() + "\n"
+	s += m.queueView() + "\n"
+	if chapters := m.chapterView(); chapters != "" {
+		s += chapters + "\n"
+	}
+	if m.entering {
+		s += m.inputStyle.Render("Go to (h:mm:ss.f): "+m.entry+"█") + "\n"
+		if m.entryErr != "" {
+			s += m.entryErr + "\n"
+		}
+		s += "\n"
+	}
 	// render debug messages
 	if m.showDebug {
 		debugHeaderStyle := lipgloss.NewStyle().
@@ -532,18 +713,38 @@ func play(cmd *coral.Command, args []string) {
 		log.Fatal().Err(err).Msg("Could not find mpv. Please install mpv player.")
 	}
 
-	target, err := filepath.Abs(args[0])
+	if playListScreens {
+		if err := listScreens(os.Stdout); err != nil {
+			log.Fatal().Err(err).Msg("Could not list screens")
+		}
+
+		return
+	}
+
+	files, err := expandPlayArgs(args)
 	if err != nil {
-		log.Fatal().Err(err).Msgf("Could not resolve path %s", args[0])
+		log.Fatal().Err(err).Msg("Could not build play queue")
 	}
 
-	_, err = os.Stat(target)
+	m := initialModel(files...)
+	m.outputScreen = viper.GetInt("play.screen")
 
+	m.asRun, err = openAsRunTracker(cmd)
 	if err != nil {
-		log.Fatal().Err(err).Msgf("Could not access video %s", target)
+		log.Fatal().Err(err).Msg("Could not open as-run log")
 	}
+	defer m.asRun.Close()
 
-	m := initialModel(target)
+	if _, err := exec.LookPath("ffprobe"); err == nil {
+		m.queue = newQueue(files, probeDuration)
+		m.chapterSource = getChapters
+	}
+
+	// probe before the show starts, while nothing is on screen to disturb
+	m.displays, err = findDisplays()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not list displays, s will not change screen")
+	}
 
 	m.ipcName = GetIPCName()
 
@@ -551,54 +752,66 @@ func play(cmd *coral.Command, args []string) {
 
 	pushDebugList(&m, m.ipcName)
 
-	go runMpvPlayer(m.outputScreen, m.ipcName, m.currentItem)
+	player := &mpvProcess{outputScreen: m.outputScreen, audioDevice: viper.GetString("play.audio_device")}
+	if err := player.Start(m.ipcName, m.currentItem); err != nil {
+		log.Fatal().Err(err).Msg("Could not start player")
+	}
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
-	if err := p.Start(); err != nil {
+	watchPlayer(p, player)
+
+	remote := &playRemote{send: p.Send, status: m.status}
+	remote.start(viper.GetString("play.osc_port"), viper.GetString("play.http_port"))
+
+	final, err := p.Run()
+	if err != nil {
 		log.Fatal().Err(err).Msg("BUBBLETEA BROKED")
 	}
-}
 
-func listenForMpvEvents(sub chan responseMsg, c net.Conn) tea.Cmd {
-	return func() tea.Msg {
-		for {
-			bufferSize := 4096
-			response := make([]byte, bufferSize)
-			count, err := c.Read(response)
+	if fm, ok := final.(model); ok && fm.exitErr != nil {
+		log.Error().Err(fm.exitErr).Msg("Player stopped unexpectedly")
+	}
+}
 
-			if err != nil {
-				log.Error().Err(err).Msg("Could not read response")
-			}
+// mediaPlayer is the process a player model drives over mpv's JSON IPC.
+// mpvProcess runs the real mpv; tests substitute an in-process fake that
+// speaks the same protocol.
+type mediaPlayer interface {
+	// Start launches the player with its IPC server at ipcName.
+	Start(ipcName string, items ...string) error
+	// Wait blocks until the player exits.
+	Wait() error
+}
 
-			responseEvent := string(response[:count])
-			for _, eventLine := range strings.Split(responseEvent, "\n") {
-				sub <- responseMsg{
-					event: eventLine,
-				}
-			}
-		}
-	}
+// mpvExitedMsg reports that the player process ended, with its error if it
+// did not exit cleanly.
+type mpvExitedMsg struct {
+	err error
 }
 
-type responseMsg struct {
-	event string
+// watchPlayer forwards the player's exit to the bubbletea program.
+func watchPlayer(p *tea.Program, player mediaPlayer) {
+	go func() {
+		p.Send(mpvExitedMsg{err: player.Wait()})
+	}()
 }
 
-// A command that waits for mpv responses.
-func waitForActivity(sub chan responseMsg) tea.Cmd {
-	// TODO: This extra indirection of using a channel is probably unneeded.
-	// refactor this to use the socket directly.
-	return func() tea.Msg {
-		return responseMsg(<-sub)
-	}
+// mpvProcess runs mpv as a borderless, always-on-top output window.
+type mpvProcess struct {
+	outputScreen int
+	audioDevice  string // empty leaves mpv's default
+	cmd          *exec.Cmd
+	output       bytes.Buffer
 }
 
-func runMpvPlayer(outputScreen int8, controlSocket string, currentItem string) {
-	whichScreen := fmt.Sprintf("--fs-screen=%v", outputScreen)
-	ipcArgument := fmt.Sprintf("--input-ipc-server=%v", controlSocket)
-	mpv := exec.Command("mpv",
+func (p *mpvProcess) Start(ipcName string, items ...string) error {
+	whichScreen := fmt.Sprintf("--fs-screen=%v", p.outputScreen)
+	ipcArgument := fmt.Sprintf("--input-ipc-server=%v", ipcName)
+	args := []string{
 		"--pause",
 		"--keep-open=always",
+		"--prefetch-playlist=yes",
+		"--gapless-audio=weak",
 		"--keepaspect-window=no",
 		whichScreen,
 		"--autofit=30%",
@@ -619,38 +832,58 @@ func runMpvPlayer(outputScreen int8, controlSocket string, currentItem string) {
 		"--no-resume-playback",
 		"--force-window=yes",
 		"--idle=yes",
+		"--af=" + meterFilter,
 		ipcArgument,
-		currentItem,
-	)
-
-	stdout, err := mpv.StdoutPipe()
-	if err != nil {
-		log.Fatal().Err(err).Msg("Could not get stdout pipe")
 	}
 
-	stderr, err := mpv.StderrPipe()
-	if err != nil {
-		log.Fatal().Err(err).Msg("Could not get stdout pipe")
+	if p.audioDevice != "" {
+		args = append(args, "--audio-device="+p.audioDevice)
 	}
 
-	_, err = mpv.StdinPipe()
-	if err != nil {
-		log.Fatal().Err(err).Msg("Could not get stdin pipe")
-	}
+	p.cmd = exec.Command("mpv", append(args, items...)...)
+	// the same writer for both keeps mpv's output in order
+	p.cmd.Stdout = &p.output
+	p.cmd.Stderr = &p.output
 
-	if err = mpv.Start(); err != nil {
-		log.Fatal().Err(err).Msg("Could not start mpv")
+	if err := p.cmd.Start(); err != nil {
+		return fmt.Errorf("could not start mpv: %w", err)
 	}
 
-	errSlurp, _ := io.ReadAll(stderr)
-	outSlurp, _ := io.ReadAll(stdout)
+	return nil
+}
 
-	if err := mpv.Wait(); err != nil {
-		message := fmt.Sprintf("PROBLEMS\n\nstdout: %s\nstderr: %s\n\n", outSlurp, errSlurp)
-		log.Fatal().Err(err).Msg(message)
+func (p *mpvProcess) Wait() error {
+	if err := p.cmd.Wait(); err != nil {
+		return fmt.Errorf("mpv exited: %w\n%s", err, p.output.String())
 	}
+
+	return nil
 }
 
+// Ports for remote control of the player; empty disables the listener.
+var playOSCPort, playHTTPPort string
+
+var (
+	playScreen      int
+	playListScreens bool
+	playAudioDevice string
+	playGrabDir     string
+)
+
 func init() {
+	playCmd.Flags().StringVar(&playOSCPort, "osc-port", "", "Port to listen for OSC remote control")
+	viper.BindPFlag("play.osc_port", playCmd.Flags().Lookup("osc-port"))
+	playCmd.Flags().StringVar(&playHTTPPort, "http-port", "", "Port to listen for HTTP remote control")
+	viper.BindPFlag("play.http_port", playCmd.Flags().Lookup("http-port"))
+
+	playCmd.Flags().IntVar(&playScreen, "screen", 0, "Display to play on, as numbered by --list-screens")
+	viper.BindPFlag("play.screen", playCmd.Flags().Lookup("screen"))
+	playCmd.Flags().StringVar(&playAudioDevice, "audio-device", "", "mpv audio device to play sound on, e.g. coreaudio/<uid>; see the o key in the player")
+	viper.BindPFlag("play.audio_device", playCmd.Flags().Lookup("audio-device"))
+	playCmd.Flags().StringVar(&playGrabDir, "grab-dir", ".", "Directory for stills grabbed with the c key")
+	viper.BindPFlag("play.grab_dir", playCmd.Flags().Lookup("grab-dir"))
+	addAsRunFlag(playCmd)
+	playCmd.Flags().BoolVar(&playListScreens, "list-screens", false, "List the displays mpv can play on and exit")
+
 	rootCmd.AddCommand(playCmd)
 }
@@ -0,0 +1,201 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// meterFilter is the mpv audio filter that publishes levels as
+	// af-metadata/meter.
+	meterFilter   = "@meter:lavfi=[astats=metadata=1:reset=1]"
+	meterLevelKey = "lavfi.astats.Overall.RMS_level"
+	meterFloor    = -60.0 // dB shown as an empty meter
+	meterWidth    = 30
+	meterInterval = 100 * time.Millisecond
+
+	volumeStep = 5.0
+	volumeMax  = 130.0 // mpv's default volume-max
+)
+
+// audioDevice is one entry of mpv's audio-device-list.
+type audioDevice struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type audioDevicesMsg struct {
+	devices []audioDevice
+}
+
+type audioDeviceChangedMsg struct {
+	device string
+}
+
+type meterTickMsg struct{}
+
+type meterMsg struct {
+	level float64 // dB
+}
+
+func meterTick() tea.Cmd {
+	return tea.Tick(meterInterval, func(time.Time) tea.Msg { return meterTickMsg{} })
+}
+
+// cmdReadMeterMpv samples the level published by the meter filter. Silence,
+// and files without audio, read as the meter floor.
+func cmdReadMeterMpv(m model) tea.Cmd {
+	client := m.mpv
+	if client == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		var metadata map[string]string
+		if err := client.GetProperty("af-metadata/meter", &metadata); err != nil {
+			return meterMsg{level: meterFloor}
+		}
+
+		return meterMsg{level: parseMeterLevel(metadata[meterLevelKey])}
+	}
+}
+
+// parseMeterLevel reads an astats dB value, which is "-inf" for silence.
+func parseMeterLevel(s string) float64 {
+	level, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(level, -1) || level < meterFloor {
+		return meterFloor
+	}
+
+	return math.Min(level, 0)
+}
+
+// cmdListAudioDevicesMpv asks mpv which audio outputs it can use.
+func cmdListAudioDevicesMpv(m model) tea.Cmd {
+	client := m.mpv
+
+	return func() tea.Msg {
+		if client == nil {
+			return mpvErrMsg{fmt.Errorf("mpv is not connected, could not list audio devices")}
+		}
+
+		var devices []audioDevice
+		if err := client.GetProperty("audio-device-list", &devices); err != nil {
+			return mpvErrMsg{err}
+		}
+
+		return audioDevicesMsg{devices: devices}
+	}
+}
+
+func cmdSetAudioDeviceMpv(m model, device string) tea.Cmd {
+	return genericMpvCommand(m, audioDeviceChangedMsg{device: device}, "set_property", "audio-device", device)
+}
+
+func cmdVolumeMpv(m model, delta float64) tea.Cmd {
+	volume := math.Max(0, math.Min(volumeMax, m.volume+delta))
+
+	return genericMpvCommand(m, nil, "set_property", "volume", volume)
+}
+
+func cmdMuteMpv(m model) tea.Cmd {
+	return genericMpvCommand(m, nil, "set_property", "mute", !m.muted)
+}
+
+// updateAudioPicker handles keys while the audio device picker is open.
+func (m model) updateAudioPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "o", "q":
+		m.picking = false
+	case "up", "k":
+		if m.pickIndex > 0 {
+			m.pickIndex--
+		}
+	case "down", "j":
+		if m.pickIndex < len(m.audioDevices)-1 {
+			m.pickIndex++
+		}
+	case "enter", " ":
+		m.picking = false
+		if m.pickIndex < len(m.audioDevices) {
+			return m, cmdSetAudioDeviceMpv(m, m.audioDevices[m.pickIndex].Name)
+		}
+	}
+
+	return m, nil
+}
+
+// openAudioPicker shows the device list with the current device selected.
+func (m model) openAudioPicker(devices []audioDevice) model {
+	m.audioDevices = devices
+	m.picking = true
+	m.pickIndex = 0
+	for i, d := range devices {
+		if d.Name == m.audioDevice {
+			m.pickIndex = i
+		}
+	}
+
+	return m
+}
+
+// audioView shows the output device, volume and level meter, or the device
+// picker while it is open.
+func (m model) audioView() string {
+	var b strings.Builder
+
+	if m.picking {
+		b.WriteString("Audio output (enter to select, esc to cancel)\n")
+		for i, d := range m.audioDevices {
+			line := fmt.Sprintf("%s  %s", d.Description, d.Name)
+			if i == m.pickIndex {
+				b.WriteString(m.inputStyle.Render("▶ "+line) + "\n")
+				continue
+			}
+			b.WriteString("  " + line + "\n")
+		}
+
+		return b.String()
+	}
+
+	volume := fmt.Sprintf("%.0f%%", m.volume)
+	if m.muted {
+		volume += " (muted)"
+	}
+	fmt.Fprintf(&b, "Audio: %s  Volume: %s\n", m.audioDevice, volume)
+	fmt.Fprintf(&b, "Level: %s %5.1f dB\n", renderMeter(m.level, m.muted), m.level)
+
+	return b.String()
+}
+
+// renderMeter draws level between meterFloor and 0 dB as a bar.
+func renderMeter(level float64, muted bool) string {
+	filled := int(math.Round((level - meterFloor) / -meterFloor * meterWidth))
+	if muted || filled < 0 {
+		filled = 0
+	}
+	if filled > meterWidth {
+		filled = meterWidth
+	}
+
+	return strings.Repeat("█", filled) + strings.Repeat("░", meterWidth-filled)
+}
@@ -0,0 +1,59 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMeterLevel(t *testing.T) {
+	cases := map[string]float64{
+		"-12.25": -12.25,
+		"-inf":   meterFloor,
+		"-95":    meterFloor,
+		"1.5":    0,
+		"":       meterFloor,
+	}
+
+	for in, want := range cases {
+		if got := parseMeterLevel(in); got != want {
+			t.Errorf("parseMeterLevel(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestRenderMeter(t *testing.T) {
+	cases := []struct {
+		level  float64
+		muted  bool
+		filled int
+	}{
+		{meterFloor, false, 0},
+		{0, false, meterWidth},
+		{meterFloor / 2, false, meterWidth / 2},
+		{0, true, 0},
+	}
+
+	for _, tc := range cases {
+		bar := renderMeter(tc.level, tc.muted)
+		if got := strings.Count(bar, "█"); got != tc.filled {
+			t.Errorf("renderMeter(%v, %v) filled %d, want %d", tc.level, tc.muted, got, tc.filled)
+		}
+		if n := len([]rune(bar)); n != meterWidth {
+			t.Errorf("meter is %d cells, want %d", n, meterWidth)
+		}
+	}
+}
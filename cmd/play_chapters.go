@@ -0,0 +1,201 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// chapterRestartWindow is how far into a chapter "previous chapter" restarts
+// the current one instead of going back another.
+const chapterRestartWindow = 2.0
+
+// playChapter is a chapter of the current item, in seconds.
+type playChapter struct {
+	title string
+	start float64
+}
+
+// chaptersMsg carries the chapters probed for path.
+type chaptersMsg struct {
+	path     string
+	chapters []playChapter
+}
+
+// toPlayChapters converts ffprobe's chapter list, skipping malformed entries.
+func toPlayChapters(resp ffmprobeResponse) []playChapter {
+	var chapters []playChapter
+	for i, c := range resp.Chapters {
+		start, err := strconv.ParseFloat(c.StartTime, 64)
+		if err != nil {
+			continue
+		}
+
+		title := c.Tags.Title
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", i+1)
+		}
+		chapters = append(chapters, playChapter{title: title, start: start})
+	}
+
+	return chapters
+}
+
+// cmdLoadChapters probes path for chapters. Files without chapters, or a
+// failed probe, give an empty list.
+func cmdLoadChapters(m model, path string) tea.Cmd {
+	probe := m.chapterSource
+	if probe == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		resp, err := probe(path)
+		if err != nil {
+			return mpvErrMsg{err}
+		}
+
+		return chaptersMsg{path: path, chapters: toPlayChapters(resp)}
+	}
+}
+
+// currentChapter is the index of the chapter playing at position, or -1 when
+// position is before the first chapter.
+func (m model) currentChapter() int {
+	current := -1
+	for i, c := range m.chapters {
+		// allow for mpv landing a hair before the chapter mark after a seek
+		if c.start <= m.position+0.001 {
+			current = i
+		}
+	}
+
+	return current
+}
+
+func cmdSeekAbsoluteMpv(m model, seconds float64) tea.Cmd {
+	return genericMpvCommand(m, nil, "seek", seconds, "absolute")
+}
+
+func cmdNextChapterMpv(m model) tea.Cmd {
+	next := m.currentChapter() + 1
+	if next >= len(m.chapters) {
+		return nil
+	}
+
+	return cmdSeekAbsoluteMpv(m, m.chapters[next].start)
+}
+
+// cmdPreviousChapterMpv restarts the current chapter, or goes back one when
+// already near its start.
+func cmdPreviousChapterMpv(m model) tea.Cmd {
+	i := m.currentChapter()
+	if i < 0 {
+		return nil
+	}
+
+	if m.position-m.chapters[i].start < chapterRestartWindow && i > 0 {
+		i--
+	}
+
+	return cmdSeekAbsoluteMpv(m, m.chapters[i].start)
+}
+
+// parseTimecode reads h:mm:ss.f, m:ss.f or plain seconds.
+func parseTimecode(s string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) > 3 || parts[0] == "" {
+		return 0, fmt.Errorf("could not parse timecode %q, want h:mm:ss.f", s)
+	}
+
+	seconds := 0.0
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("could not parse timecode %q, want h:mm:ss.f", s)
+		}
+		// only the last field may be fractional, and only it may pass 59
+		if i < len(parts)-1 && value != float64(int(value)) {
+			return 0, fmt.Errorf("could not parse timecode %q, want h:mm:ss.f", s)
+		}
+		if i > 0 && value >= 60 {
+			return 0, fmt.Errorf("timecode %q has a field over 59", s)
+		}
+		seconds = seconds*60 + value
+	}
+
+	return seconds, nil
+}
+
+// updateTimecodeEntry handles keys while the go-to-timecode prompt is open.
+func (m model) updateTimecodeEntry(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.entering = false
+		m.entry = ""
+		m.entryErr = ""
+	case tea.KeyEnter:
+		seconds, err := parseTimecode(m.entry)
+		if err != nil {
+			m.entryErr = err.Error()
+
+			return m, nil
+		}
+
+		m.entering = false
+		m.entry = ""
+		m.entryErr = ""
+
+		return m, cmdSeekAbsoluteMpv(m, seconds)
+	case tea.KeyBackspace:
+		if len(m.entry) > 0 {
+			m.entry = m.entry[:len(m.entry)-1]
+		}
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if (r >= '0' && r <= '9') || r == ':' || r == '.' {
+				m.entry += string(r)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// chapterView lists the chapters, highlighting the one playing.
+func (m model) chapterView() string {
+	if len(m.chapters) == 0 {
+		return ""
+	}
+
+	current := m.currentChapter()
+
+	var b strings.Builder
+	b.WriteString("Chapters\n")
+	for i, c := range m.chapters {
+		line := fmt.Sprintf("%2d  %s  %s", i+1, formatTimecode(c.start), c.title)
+		if i == current {
+			b.WriteString(m.inputStyle.Render("▶ "+line) + "\n")
+			continue
+		}
+		b.WriteString("  " + line + "\n")
+	}
+
+	return b.String()
+}
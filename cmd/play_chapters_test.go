@@ -0,0 +1,77 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestParseTimecode(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"1:02:03.5", 3723.5, false},
+		{"2:03.5", 123.5, false},
+		{"90", 90, false},
+		{" 0:00:07 ", 7, false},
+		{"1:60:00", 0, true},
+		{"1.5:00", 0, true},
+		{"1:2:3:4", 0, true},
+		{"", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := parseTimecode(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestToPlayChapters(t *testing.T) {
+	resp := ffmprobeResponse{Chapters: []chapter{
+		{StartTime: "0.000000", Tags: tags{Title: "Opening"}},
+		{StartTime: "bogus"},
+		{StartTime: "95.5"},
+	}}
+
+	chapters := toPlayChapters(resp)
+	if len(chapters) != 2 {
+		t.Fatalf("chapters = %v, want 2", chapters)
+	}
+	if chapters[1].start != 95.5 || chapters[1].title != "Chapter 3" {
+		t.Errorf("untitled chapter = %+v", chapters[1])
+	}
+}
+
+func TestCurrentChapter(t *testing.T) {
+	m := initialModel("/shows/a.mp4")
+	m.chapters = []playChapter{{"One", 10}, {"Two", 20}, {"Three", 30}}
+
+	for pos, want := range map[float64]int{5: -1, 10: 0, 19.9: 0, 29.9995: 2, 45: 2} {
+		m.position = pos
+		if got := m.currentChapter(); got != want {
+			t.Errorf("position %v: chapter %d, want %d", pos, got, want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// playableExts are the file types picked up when a directory is queued.
+var playableExts = map[string]bool{
+	".mp4": true, ".m4v": true, ".mov": true, ".mkv": true, ".webm": true,
+	".avi": true, ".mpg": true, ".mpeg": true, ".ts": true,
+	".mp3": true, ".m4a": true, ".wav": true, ".aac": true, ".flac": true,
+	".jpg": true, ".jpeg": true, ".png": true,
+}
+
+// queueItem is one entry in the play queue.
+type queueItem struct {
+	path     string
+	duration float64 // seconds; 0 when unknown
+}
+
+// expandPlayArgs turns play's arguments into an ordered list of absolute file
+// paths. A directory contributes its playable files in name order, and an
+// .m3u/.m3u8 playlist its entries, resolved relative to the playlist.
+func expandPlayArgs(args []string) ([]string, error) {
+	var files []string
+
+	for _, arg := range args {
+		target, err := filepath.Abs(resolveInputPath(arg))
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve path %s: %w", arg, err)
+		}
+
+		info, err := os.Stat(target)
+		if err != nil {
+			return nil, fmt.Errorf("could not access %s: %w", target, err)
+		}
+
+		switch ext := strings.ToLower(filepath.Ext(target)); {
+		case info.IsDir():
+			dirFiles, err := playableFilesIn(target)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, dirFiles...)
+		case ext == ".m3u" || ext == ".m3u8":
+			listFiles, err := readM3U(target)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, listFiles...)
+		default:
+			files = append(files, target)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("nothing to play in %s", strings.Join(args, ", "))
+	}
+
+	return files, nil
+}
+
+// playableFilesIn lists the playable files directly inside dir, sorted by name.
+func playableFilesIn(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !playableExts[strings.ToLower(filepath.Ext(e.Name()))] {
+			continue
+		}
+		files = append(files, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// readM3U returns the entries of an m3u playlist, skipping comments and
+// directives. Relative entries are resolved against the playlist's directory;
+// URLs are passed through for mpv to open.
+func readM3U(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open playlist %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var files []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.Contains(line, "://") || filepath.IsAbs(line):
+			files = append(files, line)
+		default:
+			files = append(files, filepath.Join(filepath.Dir(path), filepath.FromSlash(line)))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read playlist %s: %w", path, err)
+	}
+
+	return files, nil
+}
+
+// newQueue builds queue items for files, probing durations when ffprobe is
+// available.
+func newQueue(files []string, probe func(string) (float64, error)) []queueItem {
+	queue := make([]queueItem, 0, len(files))
+	for _, f := range files {
+		item := queueItem{path: f}
+		if probe != nil {
+			if d, err := probe(f); err == nil {
+				item.duration = d
+			}
+		}
+		queue = append(queue, item)
+	}
+
+	return queue
+}
+
+// keepOpenMode is mpv's keep-open setting for the auto-advance toggle: "yes"
+// rolls on to the next playlist entry, "always" holds every file's last frame.
+func keepOpenMode(autoAdvance bool) string {
+	if autoAdvance {
+		return "yes"
+	}
+
+	return "always"
+}
+
+// syncQueue points the queue at the file mpv reports as loaded. The entry
+// after the current one is preferred so repeated files advance in order.
+func syncQueue(m *model, path string) {
+	m.currentItem = path
+
+	if next := m.queueIndex + 1; next < len(m.queue) && m.queue[next].path == path {
+		m.queueIndex = next
+		return
+	}
+
+	for i, item := range m.queue {
+		if item.path == path {
+			m.queueIndex = i
+			return
+		}
+	}
+}
+
+// cmdPreloadNextMpv leaves only the current file and the next queue entry in
+// mpv's playlist. With prefetch-playlist on, mpv opens the next file before
+// the current one ends, so the transition has no black frame.
+func cmdPreloadNextMpv(m model) tea.Cmd {
+	commands := [][]interface{}{{"playlist-clear"}}
+	if next := m.queueIndex + 1; next < len(m.queue) {
+		commands = append(commands, []interface{}{"loadfile", m.queue[next].path, "append"})
+	}
+
+	return mpvCommands(m.mpv, commands...)
+}
+
+// cmdJumpMpv plays queue entry i. Stepping forward uses the preloaded entry;
+// anything else replaces the current file.
+func cmdJumpMpv(m model, i int) tea.Cmd {
+	if i < 0 || i >= len(m.queue) {
+		return nil
+	}
+
+	if i == m.queueIndex+1 {
+		return genericMpvCommand(m, nil, "playlist-next", "force")
+	}
+
+	return genericMpvCommand(m, nil, "loadfile", m.queue[i].path, "replace")
+}
+
+func cmdAutoAdvanceMpv(m model) tea.Cmd {
+	return genericMpvCommand(m, nil, "set_property", "keep-open", keepOpenMode(m.autoAdvance))
+}
+
+// queueView renders the queue with durations, marking the current entry.
+func (m model) queueView() string {
+	var b strings.Builder
+
+	total := 0.0
+	for i, item := range m.queue {
+		marker := "  "
+		if i == m.queueIndex {
+			marker = "▶ "
+		}
+
+		duration := "--:--"
+		if item.duration > 0 {
+			duration = formatTimecode(item.duration)
+			total += item.duration
+		}
+
+		fmt.Fprintf(&b, "%s%2d  %-50.50s %8s\n", marker, i+1, filepath.Base(item.path), duration)
+	}
+
+	auto := "off"
+	if m.autoAdvance {
+		auto = "on"
+	}
+	fmt.Fprintf(&b, "%d items, %s total · auto-advance %s\n", len(m.queue), formatTimecode(total), auto)
+
+	return b.String()
+}
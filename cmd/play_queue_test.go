@@ -0,0 +1,98 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func touch(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExpandPlayArgs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.mp4", "a.MOV", "notes.txt", "sub/c.mp4"} {
+		touch(t, filepath.Join(dir, name))
+	}
+	single := filepath.Join(dir, "sub", "c.mp4")
+
+	playlist := filepath.Join(dir, "show.m3u")
+	m3u := "#EXTM3U\n#EXTINF:10,Intro\nsub/c.mp4\n\nhttps://example.com/live.m3u8\n"
+	if err := os.WriteFile(playlist, []byte(m3u), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"file", []string{single}, []string{single}},
+		{"directory in name order", []string{dir}, []string{filepath.Join(dir, "a.MOV"), filepath.Join(dir, "b.mp4")}},
+		{"m3u", []string{playlist}, []string{single, "https://example.com/live.m3u8"}},
+		{"mixed keeps order", []string{single, filepath.Join(dir, "sub")}, []string{single, single}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := expandPlayArgs(tc.args)
+			if err != nil {
+				t.Fatalf("expandPlayArgs: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExpandPlayArgs_Errors(t *testing.T) {
+	if _, err := expandPlayArgs([]string{filepath.Join(t.TempDir(), "missing.mp4")}); err == nil {
+		t.Error("expected error for a missing file")
+	}
+
+	empty := t.TempDir()
+	if _, err := expandPlayArgs([]string{empty}); err == nil || !strings.Contains(err.Error(), "nothing to play") {
+		t.Errorf("expected nothing-to-play error, got %v", err)
+	}
+}
+
+func TestQueueView(t *testing.T) {
+	m := initialModel("/shows/a.mp4", "/shows/b.mp4")
+	m.queue[0].duration = 90
+	m.queueIndex = 1
+
+	view := m.queueView()
+	if !strings.Contains(view, "▶  2  b.mp4") {
+		t.Errorf("current entry should be marked:\n%s", view)
+	}
+	if !strings.Contains(view, "--:--") {
+		t.Errorf("unknown durations should show a placeholder:\n%s", view)
+	}
+	if !strings.Contains(view, "2 items") || !strings.Contains(view, "auto-advance off") {
+		t.Errorf("missing queue summary:\n%s", view)
+	}
+}
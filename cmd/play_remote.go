@@ -0,0 +1,346 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/labstack/echo/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// remoteAction is a player command received from a remote controller such as
+// Companion.
+type remoteAction string
+
+const (
+	remotePlay       remoteAction = "play"
+	remotePause      remoteAction = "pause"
+	remoteSeek       remoteAction = "seek"
+	remoteFullscreen remoteAction = "fullscreen"
+	remoteFrameStep  remoteAction = "frame-step"
+)
+
+// oscRemoteActions maps OSC addresses onto player commands.
+var oscRemoteActions = map[string]remoteAction{
+	"/vbs/play":       remotePlay,
+	"/vbs/pause":      remotePause,
+	"/vbs/seek":       remoteSeek,
+	"/vbs/fullscreen": remoteFullscreen,
+	"/vbs/frame-step": remoteFrameStep,
+}
+
+// remoteReplyTimeout bounds how long a controller waits for the player to
+// apply a command.
+const remoteReplyTimeout = 2 * time.Second
+
+// remoteCommandMsg delivers a remote command to the bubbletea model. seconds
+// is the relative seek offset for remoteSeek. When reply is set the model
+// answers on it once mpv has applied the command.
+type remoteCommandMsg struct {
+	action  remoteAction
+	seconds float64
+	reply   chan<- remoteReply
+}
+
+// remoteReply is the player state after a remote command, or why it failed.
+type remoteReply struct {
+	state playerState
+	err   error
+}
+
+// playerState is the player state reported back to remote controllers.
+type playerState struct {
+	File       string  `json:"file"`
+	Position   float64 `json:"position"`
+	Remaining  float64 `json:"remaining"`
+	Playing    bool    `json:"playing"`
+	Fullscreen bool    `json:"fullscreen"`
+}
+
+// playerStatus holds the latest playerState. The model publishes it after
+// every update; remote handlers read it from their own goroutines.
+type playerStatus struct {
+	mu    sync.RWMutex
+	state playerState
+}
+
+func (s *playerStatus) set(state playerState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+}
+
+func (s *playerStatus) get() playerState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.state
+}
+
+func (m model) playerState() playerState {
+	return playerState{
+		File:       m.currentItem,
+		Position:   m.position,
+		Remaining:  m.remainingTime,
+		Playing:    m.playing,
+		Fullscreen: m.fullScreen,
+	}
+}
+
+// publishStatus records the model's state for remote controllers.
+func (m model) publishStatus() {
+	if m.status == nil {
+		return
+	}
+
+	m.status.set(m.playerState())
+}
+
+// handleRemote maps a remote command onto the same mpv commands the keyboard
+// uses, and answers msg.reply once mpv has applied it. play and pause are
+// idempotent so a controller can send them blindly.
+func (m model) handleRemote(msg remoteCommandMsg) (tea.Model, tea.Cmd) {
+	m.asRun.Operator("remote:" + string(msg.action))
+
+	m, cmd := m.applyRemote(msg)
+	if msg.reply == nil {
+		return m, cmd
+	}
+
+	// mpv reports the new position later, so a seek answers with where it
+	// is headed
+	state := m.playerState()
+	if msg.action == remoteSeek {
+		state.Position = math.Max(0, state.Position+msg.seconds)
+		state.Remaining = math.Max(0, state.Remaining-msg.seconds)
+	}
+
+	if cmd == nil {
+		msg.reply <- remoteReply{state: state}
+
+		return m, nil
+	}
+
+	return m, func() tea.Msg {
+		result := cmd()
+		if failed, ok := result.(mpvErrMsg); ok {
+			msg.reply <- remoteReply{err: failed.err}
+		} else {
+			msg.reply <- remoteReply{state: state}
+		}
+
+		return result
+	}
+}
+
+func (m model) applyRemote(msg remoteCommandMsg) (model, tea.Cmd) {
+	switch msg.action {
+	case remotePlay:
+		if !m.playing {
+			next, cmd := m.togglePlay()
+
+			return next.(model), cmd
+		}
+	case remotePause:
+		if m.playing {
+			next, cmd := m.togglePlay()
+
+			return next.(model), cmd
+		}
+	case remoteSeek:
+		return m, cmdSeekMpv(m, msg.seconds)
+	case remoteFullscreen:
+		m.fullScreen = !m.fullScreen
+
+		return m, cmdFullscreenMpv(m)
+	case remoteFrameStep:
+		// unlike the keyboard, a remote can't see the step was dropped, so
+		// pause first
+		m.playing = false
+
+		return m, cmdPauseAndStepMpv(m)
+	}
+
+	return m, nil
+}
+
+// cmdPauseAndStepMpv pauses, then steps forward one frame.
+func cmdPauseAndStepMpv(m model) tea.Cmd {
+	client := m.mpv
+
+	return func() tea.Msg {
+		if client == nil {
+			return mpvErrMsg{errors.New("mpv is not connected, dropped frame-step")}
+		}
+
+		if err := client.SetProperty("pause", true); err != nil {
+			return mpvErrMsg{err}
+		}
+
+		if _, err := client.Command("frame-step"); err != nil {
+			return mpvErrMsg{err}
+		}
+
+		return nil
+	}
+}
+
+// command delivers cmd to the player and waits for its reply.
+func (r *playRemote) command(cmd remoteCommandMsg) remoteReply {
+	reply := make(chan remoteReply, 1)
+	cmd.reply = reply
+	r.send(cmd)
+
+	select {
+	case result := <-reply:
+		return result
+	case <-time.After(remoteReplyTimeout):
+		return remoteReply{err: errRemoteTimeout}
+	}
+}
+
+var errRemoteTimeout = errors.New("the player did not answer in time")
+
+// playRemote connects OSC and HTTP controllers to a running player.
+type playRemote struct {
+	send   func(tea.Msg)
+	status *playerStatus
+}
+
+// start launches the OSC and HTTP listeners for any port that is set.
+func (r *playRemote) start(oscPort, httpPort string) {
+	if oscPort != "" {
+		s := newOSCServer("127.0.0.1:" + oscPort)
+		r.registerOSC(s)
+
+		go func() {
+			if err := s.ListenAndServe(); err != nil {
+				log.Error().Err(err).Msg("OSC remote control stopped")
+			}
+		}()
+	}
+
+	if httpPort != "" {
+		e := echo.New()
+		r.registerHTTP(e)
+
+		// use http.Server directly so echo's banner doesn't scribble on the TUI
+		server := &http.Server{Addr: "127.0.0.1:" + httpPort, Handler: e}
+
+		go func() {
+			if err := server.ListenAndServe(); err != nil {
+				log.Error().Err(err).Msg("HTTP remote control stopped")
+			}
+		}()
+	}
+}
+
+// registerOSC adds a handler per remote action plus /vbs/state. Every message
+// is answered with /vbs/state once the player has applied it, so the sender
+// can show the player state, or with /vbs/error when it failed.
+func (r *playRemote) registerOSC(s *oscServer) {
+	for address, action := range oscRemoteActions {
+		s.Handle(address, r.oscHandler(action))
+	}
+
+	s.Handle("/vbs/state", func(_ *osc.Message, reply oscReplyFunc) {
+		reply(stateMessage(r.status.get()))
+	})
+}
+
+func (r *playRemote) oscHandler(action remoteAction) oscHandlerFunc {
+	return func(msg *osc.Message, reply oscReplyFunc) {
+		cmd := remoteCommandMsg{action: action}
+
+		if action == remoteSeek {
+			seconds, ok := oscFloatArg(msg, 0)
+			if !ok {
+				log.Error().Msgf("%s needs a numeric seconds argument, got %v", msg.Address, msg.Arguments)
+				return
+			}
+
+			cmd.seconds = seconds
+		}
+
+		// the server runs handlers in its receive loop, so wait for the
+		// player elsewhere and keep answering other controllers meanwhile
+		go func() {
+			result := r.command(cmd)
+			if result.err != nil {
+				log.Error().Err(result.err).Msgf("%s failed", msg.Address)
+				reply(osc.NewMessage("/vbs/error", msg.Address, result.err.Error()))
+
+				return
+			}
+
+			reply(stateMessage(result.state))
+		}()
+	}
+}
+
+// stateMessage renders the player state as /vbs/state position remaining
+// playing, with playing as 1 or 0 since not every controller reads OSC bools.
+func stateMessage(state playerState) *osc.Message {
+	playing := int32(0)
+	if state.Playing {
+		playing = 1
+	}
+
+	return osc.NewMessage("/vbs/state", float32(state.Position), float32(state.Remaining), playing)
+}
+
+// registerHTTP adds POST /api/play/<action> and GET /api/play/state. seek
+// takes its offset in the seconds query or form parameter.
+func (r *playRemote) registerHTTP(e *echo.Echo) {
+	e.GET("/api/play/state", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, r.status.get())
+	})
+	e.POST("/api/play/:action", r.handleHTTP)
+}
+
+func (r *playRemote) handleHTTP(c echo.Context) error {
+	cmd := remoteCommandMsg{action: remoteAction(c.PathParam("action"))}
+
+	switch cmd.action {
+	case remotePlay, remotePause, remoteFullscreen, remoteFrameStep:
+	case remoteSeek:
+		seconds, err := strconv.ParseFloat(c.Request().FormValue("seconds"), 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "seek needs a numeric seconds parameter"})
+		}
+
+		cmd.seconds = seconds
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "unknown action " + string(cmd.action)})
+	}
+
+	result := r.command(cmd)
+	switch {
+	case errors.Is(result.err, errRemoteTimeout):
+		return c.JSON(http.StatusGatewayTimeout, map[string]string{"error": result.err.Error()})
+	case result.err != nil:
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": result.err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, result.state)
+}
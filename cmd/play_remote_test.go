@@ -0,0 +1,303 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/labstack/echo/v5"
+)
+
+// recordingRemote returns a playRemote whose commands are captured on a
+// channel, and answered with state.
+func recordingRemote(state playerState) (*playRemote, chan tea.Msg) {
+	sent := make(chan tea.Msg, 8)
+	status := &playerStatus{}
+	status.set(state)
+
+	send := func(msg tea.Msg) {
+		cmd := msg.(remoteCommandMsg)
+		reply := cmd.reply
+		cmd.reply = nil
+		sent <- cmd
+		reply <- remoteReply{state: status.get()}
+	}
+
+	return &playRemote{send: send, status: status}, sent
+}
+
+func TestPlayRemote_OSCSeekReplies(t *testing.T) {
+	remote, sent := recordingRemote(playerState{Position: 12.5, Remaining: 30, Playing: true})
+
+	server := newOSCServer("127.0.0.1:0")
+	remote.registerOSC(server)
+	if err := server.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+	go func() { _ = server.Serve() }()
+
+	conn, err := net.DialUDP("udp", nil, server.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	data, _ := osc.NewMessage("/vbs/seek", float32(-10)).MarshalBinary()
+	if _, err := conn.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-sent:
+		want := remoteCommandMsg{action: remoteSeek, seconds: -10}
+		if msg != want {
+			t.Errorf("sent %#v, want %#v", msg, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("seek was not delivered to the player")
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("no state reply: %v", err)
+	}
+	packet, err := osc.ParsePacket(string(buf[:n]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := packet.(*osc.Message)
+	if reply.Address != "/vbs/state" || len(reply.Arguments) != 3 || reply.Arguments[2] != int32(1) {
+		t.Errorf("unexpected reply %s", reply)
+	}
+}
+
+func TestPlayRemote_HTTP(t *testing.T) {
+	remote, sent := recordingRemote(playerState{File: "a.mp4", Playing: false})
+	e := echo.New()
+	remote.registerHTTP(e)
+
+	cases := []struct {
+		name       string
+		method     string
+		url        string
+		wantStatus int
+		wantMsg    tea.Msg
+	}{
+		{"play", http.MethodPost, "/api/play/play", http.StatusOK, remoteCommandMsg{action: remotePlay}},
+		{"seek", http.MethodPost, "/api/play/seek?seconds=5.5", http.StatusOK, remoteCommandMsg{action: remoteSeek, seconds: 5.5}},
+		{"seek without seconds", http.MethodPost, "/api/play/seek", http.StatusBadRequest, nil},
+		{"unknown action", http.MethodPost, "/api/play/rewind", http.StatusBadRequest, nil},
+		{"state", http.MethodGet, "/api/play/state", http.StatusOK, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, httptest.NewRequest(tc.method, tc.url, nil))
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tc.wantStatus, w.Body)
+			}
+
+			var got tea.Msg
+			select {
+			case got = <-sent:
+			default:
+			}
+			if got != tc.wantMsg {
+				t.Errorf("sent %#v, want %#v", got, tc.wantMsg)
+			}
+
+			if w.Code == http.StatusOK {
+				var state playerState
+				if err := json.Unmarshal(w.Body.Bytes(), &state); err != nil || state.File != "a.mp4" {
+					t.Errorf("expected player state in response, got %s", w.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestModel_HandleRemoteIsIdempotent(t *testing.T) {
+	m := initialModel("a.mp4")
+	m.playing = true
+
+	next, cmd := m.handleRemote(remoteCommandMsg{action: remotePlay})
+	if cmd != nil || !next.(model).playing {
+		t.Error("play while playing should be a no-op")
+	}
+
+	next, cmd = m.handleRemote(remoteCommandMsg{action: remotePause})
+	if cmd == nil || next.(model).playing {
+		t.Error("pause while playing should pause")
+	}
+}
+
+// modelRemote returns a playRemote driving m, with mpv answering every
+// command with mpvError, or success when it is empty.
+func modelRemote(t *testing.T, m model, mpvError string) (*playRemote, func() [][]interface{}) {
+	t.Helper()
+
+	client, server, r := pipeMpv(t)
+	m.mpv = client
+
+	var mu sync.Mutex
+	var commands [][]interface{}
+	go func() {
+		for {
+			line, err := r.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			var cmd sentCommand
+			_ = json.Unmarshal(line, &cmd)
+			mu.Lock()
+			commands = append(commands, cmd.Command)
+			mu.Unlock()
+
+			answer := "success"
+			if mpvError != "" {
+				answer = mpvError
+			}
+			fmt.Fprintf(server, `{"request_id":%d,"error":%q}`+"\n", cmd.RequestID, answer)
+		}
+	}()
+
+	send := func(msg tea.Msg) {
+		next, cmd := m.handleRemote(msg.(remoteCommandMsg))
+		m = next.(model)
+		if cmd != nil {
+			go cmd()
+		}
+	}
+
+	sent := func() [][]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([][]interface{}(nil), commands...)
+	}
+
+	return &playRemote{send: send, status: &playerStatus{}}, sent
+}
+
+func TestPlayRemote_RepliesWithNewState(t *testing.T) {
+	m := initialModel("a.mp4")
+	m.currentItem, m.position, m.remainingTime = "a.mp4", 20, 40
+	remote, sent := modelRemote(t, m, "")
+	e := echo.New()
+	remote.registerHTTP(e)
+
+	cases := []struct {
+		url          string
+		wantPlaying  bool
+		wantPosition float64
+		wantCommand  []interface{}
+	}{
+		{"/api/play/play", true, 20, []interface{}{"set_property", "pause", false}},
+		{"/api/play/seek?seconds=-5", true, 15, []interface{}{"seek", float64(-5)}},
+		// a frame step while playing pauses first rather than being dropped
+		{"/api/play/frame-step", false, 20, []interface{}{"frame-step"}},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tc.url, nil))
+
+		var state playerState
+		if err := json.Unmarshal(w.Body.Bytes(), &state); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d, %s", tc.url, w.Code, w.Body)
+		}
+		if state.Playing != tc.wantPlaying || state.Position != tc.wantPosition {
+			t.Errorf("%s answered %+v", tc.url, state)
+		}
+		commands := sent()
+		if last := commands[len(commands)-1]; !reflect.DeepEqual(last, tc.wantCommand) {
+			t.Errorf("%s sent mpv %v", tc.url, last)
+		}
+	}
+	if commands := sent(); !reflect.DeepEqual(commands[len(commands)-2], []interface{}{"set_property", "pause", true}) {
+		t.Errorf("frame-step should pause first, sent %v", commands)
+	}
+}
+
+func TestPlayRemote_ReportsFailures(t *testing.T) {
+	remote, _ := modelRemote(t, initialModel("a.mp4"), "property unavailable")
+	e := echo.New()
+	remote.registerHTTP(e)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/play/fullscreen", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("a command mpv rejects answered %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestPlayRemote_OSCKeepsServingWhileWaiting(t *testing.T) {
+	release := make(chan struct{})
+	status := &playerStatus{}
+	status.set(playerState{Position: 3})
+	remote := &playRemote{status: status, send: func(msg tea.Msg) {
+		// the player is busy until the test lets it answer
+		go func() {
+			<-release
+			msg.(remoteCommandMsg).reply <- remoteReply{state: status.get()}
+		}()
+	}}
+	t.Cleanup(func() { close(release) })
+
+	server := newOSCServer("127.0.0.1:0")
+	remote.registerOSC(server)
+	if err := server.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+	go func() { _ = server.Serve() }()
+
+	conn, err := net.DialUDP("udp", nil, server.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	for _, msg := range []*osc.Message{osc.NewMessage("/vbs/play"), osc.NewMessage("/vbs/state")} {
+		data, _ := msg.MarshalBinary()
+		if _, err := conn.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("a waiting command held up /vbs/state: %v", err)
+	}
+	if packet, err := osc.ParsePacket(string(buf[:n])); err != nil || packet.(*osc.Message).Address != "/vbs/state" {
+		t.Errorf("unexpected reply %v, %v", packet, err)
+	}
+}
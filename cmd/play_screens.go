@@ -0,0 +1,155 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxScreens bounds how many displays probeDisplays tries.
+const maxScreens = 8
+
+// displaySettle is how long mpv gets to move its window before display-names
+// is read back.
+var displaySettle = 250 * time.Millisecond
+
+// probeDisplays asks mpv which displays are attached. mpv has no property that
+// lists every display, so the window is moved to each screen index in turn and
+// display-names reports where it landed. An index past the last display falls
+// back to one already seen, which ends the probe. The window is returned to
+// its original screen afterwards.
+func probeDisplays(c *mpvClient) ([]string, error) {
+	var original interface{}
+	if err := c.GetProperty("screen", &original); err != nil {
+		return nil, fmt.Errorf("could not read mpv screen: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var displays []string
+
+	for i := 0; i < maxScreens; i++ {
+		if err := c.SetProperty("screen", i); err != nil {
+			break
+		}
+		time.Sleep(displaySettle)
+
+		var names []string
+		if err := c.GetProperty("display-names", &names); err != nil {
+			return nil, fmt.Errorf("could not read mpv display-names: %w", err)
+		}
+
+		if len(names) == 0 || seen[names[0]] {
+			break
+		}
+
+		seen[names[0]] = true
+		displays = append(displays, names[0])
+	}
+
+	if err := c.SetProperty("screen", original); err != nil {
+		return nil, fmt.Errorf("could not restore mpv screen: %w", err)
+	}
+
+	if len(displays) == 0 {
+		return nil, fmt.Errorf("mpv did not report any displays")
+	}
+
+	return displays, nil
+}
+
+// screenChangedMsg reports that mpv moved to screen.
+type screenChangedMsg struct {
+	screen int
+}
+
+// cmdCycleScreenMpv moves the output to the next display, wrapping around.
+// It uses the displays found at startup rather than probing, since a probe
+// moves the live window across every screen.
+func cmdCycleScreenMpv(m model) tea.Cmd {
+	client := m.mpv
+	displays := m.displays
+	current := m.outputScreen
+
+	return func() tea.Msg {
+		if client == nil {
+			return mpvErrMsg{fmt.Errorf("mpv is not connected, could not change screen")}
+		}
+
+		if len(displays) == 0 {
+			return mpvErrMsg{fmt.Errorf("no displays were found at startup, could not change screen")}
+		}
+
+		next := (current + 1) % len(displays)
+		for _, prop := range []string{"fs-screen", "screen"} {
+			if err := client.SetProperty(prop, next); err != nil {
+				return mpvErrMsg{err}
+			}
+		}
+
+		return screenChangedMsg{screen: next}
+	}
+}
+
+// screenName describes the output screen for the view.
+func (m model) screenName() string {
+	if m.outputScreen < len(m.displays) {
+		return fmt.Sprintf("%d (%s)", m.outputScreen, m.displays[m.outputScreen])
+	}
+
+	return fmt.Sprint(m.outputScreen)
+}
+
+// findDisplays starts an idle mpv window, probes the displays it can use and
+// closes it again.
+func findDisplays() ([]string, error) {
+	ipcName := GetIPCName()
+	defer os.Remove(ipcName)
+
+	player := &mpvProcess{}
+	if err := player.Start(ipcName); err != nil {
+		return nil, err
+	}
+
+	client, err := dialMpv(ipcName, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = client.Command("quit")
+		_ = client.Close()
+		_ = player.Wait()
+	}()
+
+	return probeDisplays(client)
+}
+
+// listScreens writes the displays mpv can use.
+func listScreens(w io.Writer) error {
+	displays, err := findDisplays()
+	if err != nil {
+		return err
+	}
+
+	for i, name := range displays {
+		fmt.Fprintf(w, "%d: %s\n", i, name)
+	}
+
+	return nil
+}
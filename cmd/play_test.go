@@ -0,0 +1,517 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/viper"
+)
+
+// connectedModel starts a fake mpv the way play starts the real one and
+// returns a model connected to it.
+func connectedModel(t *testing.T, files ...string) (model, *fakeMpv) {
+	t.Helper()
+
+	if len(files) == 0 {
+		files = []string{"/shows/opening.mp4"}
+	}
+
+	fake := newFakeMpv(t)
+	m := initialModel(files...)
+	m.ipcName = fakeIPCName(t)
+	if err := fake.Start(m.ipcName, m.currentItem); err != nil {
+		t.Fatal(err)
+	}
+
+	m = update(t, m, runCmd(t, cmdInitializeControlSocket(m.ipcName)))
+	if m.mpv == nil {
+		t.Fatal("model did not connect to the fake mpv")
+	}
+	t.Cleanup(func() { _ = m.mpv.Close() })
+
+	return m, fake
+}
+
+// runCmd executes a bubbletea command and returns its message.
+func runCmd(t *testing.T, cmd tea.Cmd) tea.Msg {
+	t.Helper()
+	if cmd == nil {
+		return nil
+	}
+
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+
+	select {
+	case msg := <-done:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("command did not finish")
+		return nil
+	}
+}
+
+func update(t *testing.T, m model, msg tea.Msg) model {
+	t.Helper()
+	next, _ := m.Update(msg)
+
+	return next.(model)
+}
+
+// press sends a key to the model and runs the mpv command it produces.
+func press(t *testing.T, m model, k string) (model, tea.Msg) {
+	t.Helper()
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	msg := runCmd(t, cmd)
+	if errMsg, ok := msg.(mpvErrMsg); ok {
+		t.Fatalf("%q: %v", k, errMsg.err)
+	}
+
+	return next.(model), msg
+}
+
+// drainUntil feeds mpv events to the model until done reports true.
+func drainUntil(t *testing.T, m model, done func(model) bool) model {
+	t.Helper()
+
+	for !done(m) {
+		msg := runCmd(t, waitForMpvEvent(m.mpv))
+		if _, closed := msg.(mpvClosedMsg); closed {
+			t.Fatal("mpv connection closed while waiting for events")
+		}
+		m = update(t, m, msg)
+	}
+
+	return m
+}
+
+func TestPlay_PlayPause(t *testing.T) {
+	m, fake := connectedModel(t)
+
+	m, _ = press(t, m, " ")
+	if !m.playing || fake.Property("pause") != false {
+		t.Fatalf("space should start playback (playing %v, mpv pause %v)", m.playing, fake.Property("pause"))
+	}
+
+	m, _ = press(t, m, " ")
+	if m.playing || fake.Property("pause") != true {
+		t.Errorf("space should pause (playing %v, mpv pause %v)", m.playing, fake.Property("pause"))
+	}
+}
+
+func TestPlay_SeekUpdatesPosition(t *testing.T) {
+	m, fake := connectedModel(t)
+
+	m, _ = press(t, m, "k")
+	if got := fake.Property("time-pos"); got != 5.0 {
+		t.Fatalf("mpv time-pos = %v, want 5", got)
+	}
+
+	m = drainUntil(t, m, func(m model) bool { return m.position == 5 && m.remainingTime == 55 })
+
+	m, _ = press(t, m, "j")
+	m = drainUntil(t, m, func(m model) bool { return m.position == 0 })
+	if m.status.get().Position != 0 {
+		t.Errorf("remote status should follow the model, got %+v", m.status.get())
+	}
+}
+
+func TestPlay_FrameStepOnlyWhilePaused(t *testing.T) {
+	m, fake := connectedModel(t)
+
+	m, _ = press(t, m, "l")
+	fake.WaitForCommand("frame-step")
+	m = drainUntil(t, m, func(m model) bool { return m.position == fakeFrameDuration })
+
+	m, _ = press(t, m, " ")
+	before := len(fake.Commands())
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")}); cmd != nil {
+		t.Error("frame step during playback should be discarded")
+	}
+	if len(fake.Commands()) != before {
+		t.Errorf("unexpected commands %v", fake.Commands()[before:])
+	}
+}
+
+func TestPlay_Fullscreen(t *testing.T) {
+	m, fake := connectedModel(t)
+
+	m, _ = press(t, m, "f")
+	if !m.fullScreen || fake.Property("fullscreen") != true {
+		t.Errorf("f should enter fullscreen (model %v, mpv %v)", m.fullScreen, fake.Property("fullscreen"))
+	}
+}
+
+func TestPlay_PropertyEvents(t *testing.T) {
+	m, fake := connectedModel(t)
+
+	fake.SetProperty("percent-pos", 50.0)
+	m = drainUntil(t, m, func(m model) bool { return m.percent == 0.5 })
+
+	before := m.responses
+	fake.Emit(map[string]interface{}{"event": "idle"})
+	m = drainUntil(t, m, func(m model) bool { return m.responses > before })
+}
+
+func TestPlay_Quit(t *testing.T) {
+	m, fake := connectedModel(t)
+
+	m, msg := press(t, m, "q")
+	if _, ok := msg.(vbsQuit); !ok {
+		t.Fatalf("quit key produced %#v, want vbsQuit", msg)
+	}
+	if err := fake.Wait(); err != nil {
+		t.Errorf("mpv should exit cleanly on quit, got %v", err)
+	}
+
+	_, cmd := m.Update(msg)
+	if _, ok := runCmd(t, cmd).(tea.QuitMsg); !ok {
+		t.Error("model should quit once mpv has")
+	}
+}
+
+func TestPlay_MpvExitQuitsWithoutFatal(t *testing.T) {
+	m, fake := connectedModel(t)
+
+	fake.Crash()
+	next, cmd := m.Update(mpvExitedMsg{err: fake.Wait()})
+	if _, ok := runCmd(t, cmd).(tea.QuitMsg); !ok {
+		t.Error("model should quit when mpv exits")
+	}
+	if next.(model).exitErr == nil {
+		t.Error("an unexpected mpv exit should be reported")
+	}
+
+	// the reader notices the closed socket and ends the event stream
+	for {
+		msg := runCmd(t, waitForMpvEvent(m.mpv))
+		if _, closed := msg.(mpvClosedMsg); closed {
+			break
+		}
+	}
+	if _, err := m.mpv.Command("get_property", "pause"); !errors.Is(err, errMpvClosed) {
+		t.Errorf("commands after exit = %v, want errMpvClosed", err)
+	}
+}
+
+// pump feeds mpv events to the model, running the commands it returns, until
+// done reports true.
+func pump(t *testing.T, m model, done func(model) bool) model {
+	t.Helper()
+
+	for !done(m) {
+		msg := runCmd(t, waitForMpvEvent(m.mpv))
+		if _, closed := msg.(mpvClosedMsg); closed {
+			t.Fatal("mpv connection closed while waiting for events")
+		}
+		next, cmd := m.Update(msg)
+		m = next.(model)
+		if ev, ok := msg.(mpvEventMsg); ok && ev.Name == "path" {
+			// the batch holds the preload and the next wait; run the preload
+			for _, c := range runCmd(t, cmd).(tea.BatchMsg) {
+				if reply, ok := runCmd(t, c).(mpvErrMsg); ok {
+					t.Fatalf("preload: %v", reply.err)
+				}
+				break
+			}
+		}
+	}
+
+	return m
+}
+
+func TestPlay_QueuePreloadsNext(t *testing.T) {
+	m, fake := connectedModel(t, "/shows/a.mp4", "/shows/b.mp4", "/shows/c.mp4")
+
+	m = pump(t, m, func(m model) bool { return len(fake.Playlist()) == 2 })
+	if got := fake.Playlist(); got[1] != "/shows/b.mp4" {
+		t.Fatalf("playlist = %v, want b preloaded", got)
+	}
+
+	m, _ = press(t, m, "n")
+	m = pump(t, m, func(m model) bool { return m.queueIndex == 1 && len(fake.Playlist()) == 2 })
+	if m.currentItem != "/shows/b.mp4" {
+		t.Errorf("current = %s, want b", m.currentItem)
+	}
+	if got := fake.Playlist(); got[0] != "/shows/b.mp4" || got[1] != "/shows/c.mp4" {
+		t.Errorf("playlist after next = %v, want [b c]", got)
+	}
+
+	m, _ = press(t, m, "b")
+	m = pump(t, m, func(m model) bool { return m.queueIndex == 0 })
+	if fake.Property("path") != "/shows/a.mp4" {
+		t.Errorf("previous should reload a, mpv has %v", fake.Property("path"))
+	}
+}
+
+func TestPlay_QueueEnds(t *testing.T) {
+	m, _ := connectedModel(t, "/shows/a.mp4")
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}); cmd != nil {
+		t.Error("next on the last item should do nothing")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")}); cmd != nil {
+		t.Error("previous on the first item should do nothing")
+	}
+}
+
+func TestPlay_AutoAdvanceToggle(t *testing.T) {
+	m, fake := connectedModel(t, "/shows/a.mp4", "/shows/b.mp4")
+
+	m, _ = press(t, m, "a")
+	if !m.autoAdvance || fake.Property("keep-open") != "yes" {
+		t.Fatalf("a should enable auto-advance (model %v, mpv keep-open %v)", m.autoAdvance, fake.Property("keep-open"))
+	}
+
+	m, _ = press(t, m, "a")
+	if m.autoAdvance || fake.Property("keep-open") != "always" {
+		t.Errorf("a should hold on the last frame again (model %v, mpv keep-open %v)", m.autoAdvance, fake.Property("keep-open"))
+	}
+}
+
+// noDisplaySettle skips the wait for the window to move; the fake is instant.
+func noDisplaySettle(t *testing.T) {
+	t.Helper()
+	saved := displaySettle
+	displaySettle = 0
+	t.Cleanup(func() { displaySettle = saved })
+}
+
+func TestPlay_CycleScreens(t *testing.T) {
+	m, fake := connectedModel(t)
+
+	// without displays from startup, s must not probe the live window
+	before := len(fake.Commands())
+	if _, failed := cmdCycleScreenMpv(m)().(mpvErrMsg); !failed || len(fake.Commands()) != before {
+		t.Fatalf("s with no known displays should fail without moving mpv, sent %v", fake.Commands()[before:])
+	}
+
+	m.displays = []string{"eDP-1", "HDMI-1"}
+	m, msg := press(t, m, "s")
+	m = update(t, m, msg)
+	if m.outputScreen != 1 || fake.Property("fs-screen") != 1.0 || fake.Property("screen") != 1.0 {
+		t.Fatalf("s should move to screen 1 (model %d, mpv fs-screen %v screen %v)",
+			m.outputScreen, fake.Property("fs-screen"), fake.Property("screen"))
+	}
+	if got := m.screenName(); got != "1 (HDMI-1)" {
+		t.Errorf("screenName = %q", got)
+	}
+
+	m, msg = press(t, m, "s")
+	m = update(t, m, msg)
+	if m.outputScreen != 0 || fake.Property("fs-screen") != 0.0 {
+		t.Errorf("s should wrap to screen 0 (model %d, mpv %v)", m.outputScreen, fake.Property("fs-screen"))
+	}
+}
+
+func TestProbeDisplays(t *testing.T) {
+	noDisplaySettle(t)
+	m, fake := connectedModel(t)
+	fake.SetDisplays("eDP-1", "HDMI-1", "DP-2")
+	fake.SetProperty("screen", 2.0)
+
+	displays, err := probeDisplays(m.mpv)
+	if err != nil {
+		t.Fatalf("probeDisplays: %v", err)
+	}
+	if fmt.Sprint(displays) != "[eDP-1 HDMI-1 DP-2]" {
+		t.Errorf("displays = %v", displays)
+	}
+	if fake.Property("screen") != 2.0 {
+		t.Errorf("window should return to screen 2, is on %v", fake.Property("screen"))
+	}
+}
+
+func TestPlay_ChapterNavigation(t *testing.T) {
+	m, fake := connectedModel(t)
+	m.chapters = []playChapter{{"One", 0}, {"Two", 20}, {"Three", 40}}
+
+	m, _ = press(t, m, "]")
+	m = drainUntil(t, m, func(m model) bool { return m.position == 20 })
+	if m.currentChapter() != 1 {
+		t.Fatalf("] should land on chapter 2, at %v", m.position)
+	}
+
+	fake.SetProperty("time-pos", 25.0)
+	m = drainUntil(t, m, func(m model) bool { return m.position == 25 })
+	m, _ = press(t, m, "[")
+	m = drainUntil(t, m, func(m model) bool { return m.position == 20 })
+
+	m, _ = press(t, m, "[")
+	m = drainUntil(t, m, func(m model) bool { return m.position == 0 })
+
+	m, _ = press(t, m, "]")
+	m = drainUntil(t, m, func(m model) bool { return m.position == 20 })
+	m, _ = press(t, m, "]")
+	m = drainUntil(t, m, func(m model) bool { return m.position == 40 })
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")}); cmd != nil {
+		t.Error("] on the last chapter should do nothing")
+	}
+}
+
+func TestPlay_GoToTimecode(t *testing.T) {
+	m, fake := connectedModel(t)
+
+	m, _ = press(t, m, "g")
+	if !m.entering {
+		t.Fatal("g should open the timecode prompt")
+	}
+	for _, k := range []string{"0", ":", "0", "0", ":", "x", "4", "2", ".", "5"} {
+		m, _ = press(t, m, k)
+	}
+	if m.entry != "0:00:42.5" {
+		t.Fatalf("entry = %q", m.entry)
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	runCmd(t, cmd)
+	if m.entering || fake.Property("time-pos") != 42.5 {
+		t.Errorf("enter should seek to 42.5 (entering %v, mpv %v)", m.entering, fake.Property("time-pos"))
+	}
+
+	m, _ = press(t, m, "g")
+	m, _ = press(t, m, "q")
+	if m.quitting || m.entry != "" {
+		t.Error("keys in the prompt should not reach the player")
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if next.(model).entering {
+		t.Error("esc should close the prompt")
+	}
+}
+
+func TestPlay_AsRunLog(t *testing.T) {
+	m, fake := connectedModel(t, "/shows/a.mp4", "/shows/b.mp4")
+	tracker, _, base := testAsRun(t)
+	m.asRun = tracker
+
+	fake.SetProperty("path", "/shows/a.mp4") // replay the path now that we log
+	m = pump(t, m, func(m model) bool { return len(fake.Playlist()) == 2 })
+	m, _ = press(t, m, " ")
+	m = drainUntil(t, m, func(m model) bool { return len(readAsRun(t, base)) == 2 })
+	fake.SetProperty("time-pos", 1.0)
+	m = drainUntil(t, m, func(m model) bool { return m.position == 1 })
+	m, _ = press(t, m, "k")
+	m = drainUntil(t, m, func(m model) bool { return m.position == 6 })
+	m, _ = press(t, m, "n")
+	m = pump(t, m, func(m model) bool { return m.queueIndex == 1 })
+	_ = m.asRun.Close()
+
+	got := summarize(readAsRun(t, base))
+	want := []string{
+		"start a.mp4 0-0 ",
+		"resume a.mp4 0-0  ",
+		"seek a.mp4 1-6 k",
+		"stop a.mp4 0-6 n",
+		"start b.mp4 0-0 n",
+		"stop b.mp4 0-0 n",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("as-run:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestPlay_VolumeAndMute(t *testing.T) {
+	m, fake := connectedModel(t)
+
+	m, _ = press(t, m, "+")
+	m = drainUntil(t, m, func(m model) bool { return m.volume == 105 })
+	m, _ = press(t, m, "-")
+	m = drainUntil(t, m, func(m model) bool { return m.volume == 100 })
+	m, _ = press(t, m, "-")
+	m = drainUntil(t, m, func(m model) bool { return m.volume == 95 })
+
+	fake.SetProperty("volume", volumeMax)
+	m = drainUntil(t, m, func(m model) bool { return m.volume == volumeMax })
+	m, _ = press(t, m, "+")
+	if fake.Property("volume") != volumeMax {
+		t.Errorf("volume should stop at %v, mpv has %v", volumeMax, fake.Property("volume"))
+	}
+
+	m, _ = press(t, m, "m")
+	m = drainUntil(t, m, func(m model) bool { return m.muted })
+	if !strings.Contains(m.audioView(), "(muted)") {
+		t.Errorf("view should show mute:\n%s", m.audioView())
+	}
+}
+
+func TestPlay_AudioDevicePicker(t *testing.T) {
+	m, fake := connectedModel(t)
+
+	m, msg := press(t, m, "o")
+	m = update(t, m, msg)
+	if !m.picking || len(m.audioDevices) != 3 {
+		t.Fatalf("o should open the picker with mpv's devices, got %v", m.audioDevices)
+	}
+
+	// arrow keys move the selection instead of seeking
+	m, _ = press(t, m, "j")
+	m, _ = press(t, m, "j")
+	m, _ = press(t, m, "j")
+	if m.pickIndex != 2 || fake.Property("time-pos") != 0.0 {
+		t.Fatalf("pickIndex = %d, mpv time-pos %v", m.pickIndex, fake.Property("time-pos"))
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = update(t, next.(model), runCmd(t, cmd))
+	if m.picking || fake.Property("audio-device") != "coreaudio/Dante" {
+		t.Errorf("enter should switch mpv to Dante, mpv has %v", fake.Property("audio-device"))
+	}
+	if m.audioDevice != "coreaudio/Dante" {
+		t.Errorf("model audio device = %q", m.audioDevice)
+	}
+}
+
+func TestPlay_Meter(t *testing.T) {
+	m, _ := connectedModel(t)
+
+	m = update(t, m, runCmd(t, cmdReadMeterMpv(m)))
+	if m.level != -18.5 {
+		t.Errorf("level = %v, want -18.5", m.level)
+	}
+}
+
+func TestPlay_GrabStill(t *testing.T) {
+	m, fake := connectedModel(t)
+	dir := t.TempDir()
+	viper.Set("play.grab_dir", dir)
+	t.Cleanup(func() { viper.Set("play.grab_dir", "") })
+
+	fake.SetProperty("time-pos", 75.5)
+	m = drainUntil(t, m, func(m model) bool { return m.position == 75.5 })
+
+	m, msg := press(t, m, "c")
+	m = update(t, m, msg)
+
+	want := filepath.Join(dir, "opening_1-15.5.png")
+	got := fake.WaitForCommand("screenshot-to-file")
+	if got[1] != want || got[2] != "video" {
+		t.Errorf("mpv command = %v, want screenshot-to-file %s video", got, want)
+	}
+	if m.lastGrab != want {
+		t.Errorf("lastGrab = %q", m.lastGrab)
+	}
+}
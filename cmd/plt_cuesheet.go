@@ -237,14 +237,19 @@ func writePlaylistJSON(dir string, manifest buildManifest) error {
 	return nil
 }
 
-// formatTimecode renders seconds as m:ss.t (tenths).
+// formatTimecode renders seconds as m:ss.t (tenths), or h:mm:ss.t from an
+// hour.
 func formatTimecode(seconds float64) string {
 	if seconds < 0 {
 		seconds = 0
 	}
-	minutes := int(seconds) / 60
-	rem := seconds - float64(minutes*60)
-	return fmt.Sprintf("%d:%04.1f", minutes, rem)
+
+	tenths := int(seconds*10 + 0.5)
+	if tenths >= 36000 {
+		return fmt.Sprintf("%d:%02d:%02d.%d", tenths/36000, tenths/600%60, tenths/10%60, tenths%10)
+	}
+
+	return fmt.Sprintf("%d:%02d.%d", tenths/600, tenths/10%60, tenths%10)
 }
 
 // cueNumberColor is the single accent color for cue numbers (Typst expression).
@@ -150,6 +150,9 @@ func TestFormatTimecode(t *testing.T) {
 		54.32:   "0:54.3",
 		139.006: "2:19.0",
 		793.5:   "13:13.5",
+		59.96:   "1:00.0",
+		3723.54: "1:02:03.5",
+		-3:      "0:00.0",
 	}
 	for in, want := range cases {
 		if got := formatTimecode(in); got != want {
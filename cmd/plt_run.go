@@ -0,0 +1,534 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg" // thumbnails extracted from playlists are usually JPEG
+	_ "image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/coral"
+	"github.com/rs/zerolog/log"
+)
+
+var pltRunCmd = &coral.Command{
+	Use:   "run <workdir>",
+	Short: "Run a built working directory as a cue-list show in mpv.",
+	Long: `Load the playlist.json written by plt build and run it as a show: the
+terminal shows the current and next cue, and GO plays the standby cue in mpv.
+Each cue's after-cue action is honored (continue auto-advances, stop cuts to
+black, freeze holds the last frame), images are held for their duration, and
+cut clips skip their keyframe lead-in so playback starts on the marker.`,
+	Example: "  vbs plt run ./event-dec-2nd",
+	Run:     runPltRun,
+	Args:    coral.ExactArgs(1),
+}
+
+// After-cue action codes as stored in cue.EndActionRaw.
+const (
+	endActionContinue = 0
+	endActionStop     = 1
+	endActionFreeze   = 2
+)
+
+// thumbWidth is the width, in terminal cells, of rendered cue thumbnails.
+const thumbWidth = 24
+
+func runPltRun(cmd *coral.Command, args []string) {
+	if _, err := exec.LookPath("mpv"); err != nil {
+		log.Fatal().Err(err).Msg("Could not find mpv. Please install mpv player.")
+	}
+
+	dir, err := filepath.Abs(resolveInputPath(args[0]))
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Could not resolve path %s", args[0])
+	}
+
+	manifest, err := loadShowManifest(dir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not load show")
+	}
+
+	m := newShowModel(dir, manifest)
+	m.ipcName = GetIPCName()
+
+	defer os.Remove(m.ipcName)
+
+	m.asRun, err = openAsRunTracker(cmd)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not open as-run log")
+	}
+	defer m.asRun.Close()
+
+	player := &mpvProcess{}
+	if err := player.Start(m.ipcName); err != nil {
+		log.Fatal().Err(err).Msg("Could not start player")
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	watchPlayer(p, player)
+
+	final, err := p.Run()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not run show")
+	}
+
+	if fm, ok := final.(showModel); ok && fm.exitErr != nil {
+		log.Error().Err(fm.exitErr).Msg("Player stopped unexpectedly")
+	}
+}
+
+// loadShowManifest reads dir/playlist.json and checks it has something to play.
+func loadShowManifest(dir string) (buildManifest, error) {
+	var manifest buildManifest
+
+	path := filepath.Join(dir, "playlist.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return manifest, fmt.Errorf("could not read %s (is this a plt build directory?): %w", path, err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	if len(manifest.Cues) == 0 {
+		return manifest, fmt.Errorf("%s has no cues", path)
+	}
+	for _, c := range manifest.Cues {
+		if c.Clip == "" {
+			return manifest, fmt.Errorf("cue %d (%q) has no clip; run plt build rather than plt cuesheet", c.Index, c.Label)
+		}
+	}
+	return manifest, nil
+}
+
+// showKeyMap is the operator keyboard for the show runner.
+type showKeyMap struct {
+	Go    key.Binding
+	Prev  key.Binding
+	Next  key.Binding
+	Pause key.Binding
+	Stop  key.Binding
+	Help  key.Binding
+	Quit  key.Binding
+}
+
+func (k showKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Go, k.Help, k.Quit}
+}
+
+func (k showKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Go, k.Prev, k.Next},
+		{k.Pause, k.Stop},
+		{k.Help, k.Quit},
+	}
+}
+
+var showKeys = showKeyMap{
+	Go: key.NewBinding(
+		key.WithKeys(" ", "enter"),
+		key.WithHelp("space/enter", "GO standby cue"),
+	),
+	Prev: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "standby previous cue"),
+	),
+	Next: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "standby next cue"),
+	),
+	Pause: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "pause/resume"),
+	),
+	Stop: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "stop to black"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "ctrl+c"),
+		key.WithHelp("q", "quit"),
+	),
+}
+
+// showModel is the bubbletea model for plt run. current is the index into
+// manifest.Cues of the cue on air (-1 when nothing has been played) and
+// standby is the cue the next GO will play.
+type showModel struct {
+	dir         string
+	manifest    buildManifest
+	current     int
+	standby     int
+	playing     bool
+	held        bool
+	position    float64
+	remaining   float64
+	pendingSeek float64
+	status      string
+	thumbs      map[string]string
+	ipcName     string
+	mpv         *mpvClient
+	quitting    bool
+	exitErr     error
+	asRun       *asRunTracker // nil unless an as-run directory is configured
+	keys        showKeyMap
+	help        help.Model
+}
+
+func newShowModel(dir string, manifest buildManifest) showModel {
+	return showModel{
+		dir:      dir,
+		manifest: manifest,
+		current:  -1,
+		standby:  0,
+		status:   "Connecting to mpv",
+		thumbs:   map[string]string{},
+		keys:     showKeys,
+		help:     help.New(),
+	}
+}
+
+func (m showModel) Init() tea.Cmd {
+	return connectShowSocket(m.ipcName)
+}
+
+// showObservedProperties are the mpv properties the show runner follows.
+var showObservedProperties = []string{"time-pos", "time-remaining", "eof-reached", "path", "pause"}
+
+// connectShowSocket connects to mpv (waiting while it starts) and observes
+// the properties the show runner needs.
+func connectShowSocket(ipcName string) tea.Cmd {
+	return func() tea.Msg {
+		client, err := dialMpv(ipcName, 10*time.Second)
+		if err != nil {
+			return mpvErrMsg{err}
+		}
+
+		for _, name := range showObservedProperties {
+			if err := client.ObserveProperty(name); err != nil {
+				return mpvErrMsg{err}
+			}
+		}
+
+		return mpvConnectedMsg{client: client}
+	}
+}
+
+// mpvCommands returns a command that sends the given mpv commands in order.
+func (m showModel) mpvCommands(commands ...[]interface{}) tea.Cmd {
+	return mpvCommands(m.mpv, commands...)
+}
+
+// goCue puts manifest.Cues[i] on air: images are held for their duration,
+// videos play from the top, and a cut clip's lead-in is skipped once mpv
+// reports the file loaded.
+func (m showModel) goCue(i int) (showModel, tea.Cmd) {
+	if i < 0 || i >= len(m.manifest.Cues) {
+		return m, nil
+	}
+
+	c := m.manifest.Cues[i]
+	m.current = i
+	m.standby = i + 1
+	m.playing = true
+	m.held = false
+	m.position = 0
+	m.remaining = c.DurationSec
+	m.pendingSeek = 0
+	if c.Cut != nil {
+		m.pendingSeek = c.Cut.LeadIn
+	}
+	m.status = fmt.Sprintf("GO cue %d", c.Index)
+
+	imageDuration := "inf"
+	if c.Kind == "image" && c.DurationSec > 0 {
+		imageDuration = fmt.Sprintf("%g", c.DurationSec)
+	}
+
+	return m, m.mpvCommands(
+		[]interface{}{"set_property", "image-display-duration", imageDuration},
+		[]interface{}{"loadfile", filepath.Join(m.dir, filepath.FromSlash(c.Clip)), "replace"},
+		[]interface{}{"set_property", "pause", false},
+	)
+}
+
+// cueEnded applies the on-air cue's after-cue action once mpv reaches its end.
+func (m showModel) cueEnded() (showModel, tea.Cmd) {
+	if m.current < 0 || m.held {
+		return m, nil
+	}
+
+	c := m.manifest.Cues[m.current]
+	m.playing = false
+	m.held = true
+
+	switch c.EndActionRaw {
+	case endActionContinue:
+		if m.current+1 < len(m.manifest.Cues) {
+			return m.goCue(m.current + 1)
+		}
+		m.status = "End of show"
+
+		return m, m.mpvCommands([]interface{}{"stop"})
+	case endActionStop:
+		m.status = fmt.Sprintf("Cue %d ended; stopped to black", c.Index)
+
+		return m, m.mpvCommands([]interface{}{"stop"})
+	default:
+		// freeze, and any unknown code: keep-open already holds the last frame
+		m.status = fmt.Sprintf("Cue %d ended; holding last frame", c.Index)
+
+		return m, nil
+	}
+}
+
+// handleEvent folds one mpv event into the model.
+func (m showModel) handleEvent(ev mpvEvent) (showModel, tea.Cmd) {
+	m.asRun.Observe(ev)
+
+	switch ev.Event {
+	case "file-loaded":
+		if m.pendingSeek > 0 {
+			seek := m.pendingSeek
+			m.pendingSeek = 0
+
+			return m, m.mpvCommands([]interface{}{"seek", seek, "absolute"})
+		}
+	case "property-change":
+		switch ev.Name {
+		case "time-pos":
+			_ = json.Unmarshal(ev.Data, &m.position)
+		case "time-remaining":
+			_ = json.Unmarshal(ev.Data, &m.remaining)
+		case "eof-reached":
+			var eof bool
+			if json.Unmarshal(ev.Data, &eof) == nil && eof {
+				return m.cueEnded()
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m showModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.help.Width = msg.Width
+	case mpvConnectedMsg:
+		m.mpv = msg.client
+		m.status = "Ready"
+
+		return m, waitForMpvEvent(m.mpv)
+	case mpvErrMsg:
+		m.status = msg.err.Error()
+	case mpvClosedMsg:
+		m.status = "mpv has exited"
+	case vbsQuit:
+		return m, tea.Quit
+	case mpvExitedMsg:
+		if !m.quitting {
+			m.exitErr = msg.err
+		}
+
+		return m, tea.Quit
+	case mpvEventMsg:
+		var cmd tea.Cmd
+		m, cmd = m.handleEvent(mpvEvent(msg))
+
+		return m, tea.Batch(cmd, waitForMpvEvent(m.mpv))
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m showModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.asRun.Operator(msg.String())
+
+	switch {
+	case key.Matches(msg, m.keys.Go):
+		return m.goCue(m.standby)
+	case key.Matches(msg, m.keys.Prev):
+		if m.standby > 0 {
+			m.standby--
+		}
+	case key.Matches(msg, m.keys.Next):
+		if m.standby < len(m.manifest.Cues)-1 {
+			m.standby++
+		}
+	case key.Matches(msg, m.keys.Pause):
+		if m.current >= 0 && !m.held {
+			m.playing = !m.playing
+
+			return m, m.mpvCommands([]interface{}{"set_property", "pause", !m.playing})
+		}
+	case key.Matches(msg, m.keys.Stop):
+		m.playing = false
+		m.held = true
+		m.status = "Stopped to black"
+
+		return m, m.mpvCommands([]interface{}{"stop"})
+	case key.Matches(msg, m.keys.Help):
+		m.help.ShowAll = !m.help.ShowAll
+	case key.Matches(msg, m.keys.Quit):
+		m.quitting = true
+
+		return m, quitMpv(m.mpv)
+	}
+	return m, nil
+}
+
+var (
+	showLabelStyle   = lipgloss.NewStyle().Bold(true).Width(8)
+	showOnAirStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFDF5")).Background(lipgloss.Color("#C0392B")).Padding(0, 1)
+	showStandbyStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFDF5")).Background(lipgloss.Color("#25A065")).Padding(0, 1)
+	showMutedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+func (m showModel) View() string {
+	s := titleStyle().Render("VBS show: "+m.manifest.Name) + "\n\n"
+
+	s += m.cuePanel(showOnAirStyle.Render("NOW"), m.current, true) + "\n\n"
+	s += m.cuePanel(showStandbyStyle.Render("NEXT"), m.standby, false) + "\n\n"
+	s += m.cueList() + "\n"
+	s += showMutedStyle.Render(m.status) + "\n\n"
+	s += m.help.View(m.keys) + "\n"
+
+	return appStyle.Render(s)
+}
+
+// cuePanel renders one cue's thumbnail beside its label, timing, and action.
+func (m showModel) cuePanel(title string, i int, onAir bool) string {
+	if i < 0 || i >= len(m.manifest.Cues) {
+		return lipgloss.JoinHorizontal(lipgloss.Top, showLabelStyle.Render(title), showMutedStyle.Render("—"))
+	}
+
+	c := m.manifest.Cues[i]
+	info := fmt.Sprintf("#%d %s\n%s · %s\nafter: %s", c.Index, c.Label, c.Kind,
+		formatTimecode(c.DurationSec), endActionLabel(c.EndActionRaw))
+	if onAir {
+		state := "playing"
+		switch {
+		case m.held:
+			state = "ended"
+		case !m.playing:
+			state = "paused"
+		}
+		info += fmt.Sprintf("\n%s  %s elapsed  %s remaining", state,
+			formatTimecode(m.position), formatTimecode(m.remaining))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		showLabelStyle.Render(title),
+		m.thumbnail(c),
+		"  ",
+		info,
+	)
+}
+
+// cueList renders the running order with the on-air and standby cues marked.
+func (m showModel) cueList() string {
+	var b strings.Builder
+	for i, c := range m.manifest.Cues {
+		marker := "  "
+		switch i {
+		case m.current:
+			marker = "▶ "
+		case m.standby:
+			marker = "→ "
+		}
+		fmt.Fprintf(&b, "%s%2d  %-40.40s %6s  %s\n", marker, c.Index, c.Label,
+			formatTimecode(c.DurationSec), endActionLabel(c.EndActionRaw))
+	}
+	return b.String()
+}
+
+// thumbnail returns the cue's rendered thumbnail, memoized by path.
+func (m showModel) thumbnail(c cue) string {
+	if c.Thumbnail == "" {
+		return ""
+	}
+	if rendered, ok := m.thumbs[c.Thumbnail]; ok {
+		return rendered
+	}
+
+	rendered, err := renderThumbnail(filepath.Join(m.dir, filepath.FromSlash(c.Thumbnail)), thumbWidth)
+	if err != nil {
+		log.Debug().Err(err).Msgf("could not render thumbnail for cue %d", c.Index)
+	}
+	m.thumbs[c.Thumbnail] = rendered
+	return rendered
+}
+
+// renderThumbnail draws an image as width terminal cells of upper-half blocks,
+// two pixel rows per line, sampling the source with nearest-neighbor.
+func renderThumbnail(path string, width int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("could not open thumbnail: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return "", fmt.Errorf("could not decode thumbnail %s: %w", path, err)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return "", nil
+	}
+	height := width * bounds.Dy() / bounds.Dx()
+	height += height % 2
+
+	sample := func(x, y int) lipgloss.Color {
+		r, g, b, _ := img.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height).RGBA()
+		return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8))
+	}
+
+	var out strings.Builder
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x++ {
+			out.WriteString(lipgloss.NewStyle().
+				Foreground(sample(x, y)).
+				Background(sample(x, y+1)).
+				Render("▀"))
+		}
+		if y+2 < height {
+			out.WriteByte('\n')
+		}
+	}
+	return out.String(), nil
+}
+
+func init() {
+	addAsRunFlag(pltRunCmd)
+	pltCmd.AddCommand(pltRunCmd)
+}
@@ -0,0 +1,161 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLoadShowManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := writePlaylistJSON(dir, sampleManifest()); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := loadShowManifest(dir)
+	if err != nil {
+		t.Fatalf("loadShowManifest: %v", err)
+	}
+	if len(manifest.Cues) != 3 {
+		t.Errorf("cues = %d, want 3", len(manifest.Cues))
+	}
+}
+
+func TestLoadShowManifest_Errors(t *testing.T) {
+	if _, err := loadShowManifest(t.TempDir()); err == nil {
+		t.Error("expected error for a directory without playlist.json")
+	}
+
+	dir := t.TempDir()
+	noClip := sampleManifest()
+	noClip.Cues[0].Clip = ""
+	if err := writePlaylistJSON(dir, noClip); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadShowManifest(dir); err == nil || !strings.Contains(err.Error(), "plt cuesheet") {
+		t.Errorf("expected a cuesheet-only hint, got %v", err)
+	}
+}
+
+func TestShowModel_GoCue(t *testing.T) {
+	m := newShowModel(t.TempDir(), sampleManifest())
+
+	m, cmd := m.goCue(1)
+	if cmd == nil {
+		t.Fatal("GO should send mpv commands")
+	}
+	if m.current != 1 || m.standby != 2 {
+		t.Errorf("current/standby = %d/%d, want 1/2", m.current, m.standby)
+	}
+	if m.pendingSeek != 0.066 {
+		t.Errorf("pendingSeek = %v, want the cut lead-in 0.066", m.pendingSeek)
+	}
+
+	m, cmd = m.handleEvent(mpvEvent{Event: "file-loaded"})
+	if cmd == nil || m.pendingSeek != 0 {
+		t.Errorf("file-loaded should seek past the lead-in once (cmd %v, pending %v)", cmd, m.pendingSeek)
+	}
+}
+
+func TestShowModel_CueEnded(t *testing.T) {
+	cases := []struct {
+		name        string
+		endAction   int
+		wantCurrent int
+		wantCmd     bool
+	}{
+		{"continue advances", endActionContinue, 1, true},
+		{"stop cuts to black", endActionStop, 0, true},
+		{"freeze holds", endActionFreeze, 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifest := sampleManifest()
+			manifest.Cues[0].EndActionRaw = tc.endAction
+			m := newShowModel(t.TempDir(), manifest)
+			m, _ = m.goCue(0)
+
+			m, cmd := m.handleEvent(mpvEvent{Event: "property-change", Name: "eof-reached", Data: []byte("true")})
+			if m.current != tc.wantCurrent {
+				t.Errorf("current = %d, want %d", m.current, tc.wantCurrent)
+			}
+			if (cmd != nil) != tc.wantCmd {
+				t.Errorf("cmd = %v, want command %v", cmd, tc.wantCmd)
+			}
+		})
+	}
+}
+
+func TestRenderThumbnail(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 9))
+	for x := 0; x < 16; x++ {
+		for y := 0; y < 9; y++ {
+			img.Set(x, y, color.RGBA{R: 0x23, G: 0x5a, B: 0x68, A: 0xff})
+		}
+	}
+	path := filepath.Join(t.TempDir(), "thumb.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	out, err := renderThumbnail(path, 8)
+	if err != nil {
+		t.Fatalf("renderThumbnail: %v", err)
+	}
+	// 8 wide at 16:9 is 4 pixel rows, two per line.
+	if lines := strings.Count(out, "\n") + 1; lines != 2 {
+		t.Errorf("lines = %d, want 2", lines)
+	}
+	if strings.Count(out, "▀") != 16 {
+		t.Errorf("expected 16 half-block cells, got %q", out)
+	}
+}
+
+func TestShowModel_QuitDoesNotWaitOnMpv(t *testing.T) {
+	m := newShowModel(t.TempDir(), sampleManifest())
+	// mpv never answers on this pipe
+	m.mpv, _, _ = pipeMpv(t)
+
+	start := time.Now()
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if took := time.Since(start); took > time.Second {
+		t.Errorf("quit blocked the UI for %s", took)
+	}
+	if cmd == nil || !next.(showModel).quitting {
+		t.Fatal("quit should hand mpv's quit to a command")
+	}
+
+	if _, cmd = next.Update(vbsQuit(0)); cmd == nil {
+		t.Fatal("vbsQuit should exit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("vbsQuit should quit bubbletea")
+	}
+}
@@ -0,0 +1,423 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // required by the WebSocket handshake, not used for security
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// websocketGUID is the fixed key suffix from RFC 6455.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+)
+
+// Close codes from RFC 6455.
+const (
+	wsCloseProtocolError = 1002
+	wsCloseTooBig        = 1009
+)
+
+// wsMaxMessage bounds what a client may send; clients only send control
+// frames and short messages here.
+const wsMaxMessage = 1 << 20
+
+// wsCloseError is a client error that closes the connection with code.
+type wsCloseError struct {
+	code   uint16
+	reason string
+}
+
+func (e *wsCloseError) Error() string {
+	return fmt.Sprintf("websocket %d: %s", e.code, e.reason)
+}
+
+func wsProtocolError(format string, args ...interface{}) error {
+	return &wsCloseError{code: wsCloseProtocolError, reason: fmt.Sprintf(format, args...)}
+}
+
+// wsWriteTimeout drops a client that stops reading.
+const wsWriteTimeout = 2 * time.Second
+
+// wsSendQueue is how many frames a client may fall behind by before it is
+// dropped.
+const wsSendQueue = 64
+
+// wsHub is a minimal WebSocket server that broadcasts text messages to every
+// connected client. It only sends; anything clients send apart from close and
+// ping is ignored. That is all a metadata feed needs, and it avoids pulling a
+// WebSocket library into the build.
+//
+// Each client has its own queue and writer, so a stalled client never holds
+// up a broadcast.
+type wsHub struct {
+	mu      sync.Mutex
+	clients map[net.Conn]*wsClient
+	// welcome, if set, is sent to each client as it connects
+	welcome func() []byte
+}
+
+// wsClient is a connection and the frames waiting to be written to it.
+type wsClient struct {
+	conn net.Conn
+	send chan []byte
+	done chan struct{}
+}
+
+func newWSHub() *wsHub {
+	return &wsHub{clients: map[net.Conn]*wsClient{}}
+}
+
+// ServeHTTP upgrades the request to a WebSocket and registers the client.
+func (h *wsHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || r.Header.Get("Sec-WebSocket-Key") == "" {
+		http.Error(w, "expected a WebSocket upgrade", http.StatusBadRequest)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket upgrade is not supported here", http.StatusInternalServerError)
+		return
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		log.Error().Err(err).Msg("Could not take over the WebSocket connection")
+		return
+	}
+
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + websocketGUID)) //nolint:gosec // see import
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+	if err := rw.Flush(); err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	c := &wsClient{conn: conn, send: make(chan []byte, wsSendQueue), done: make(chan struct{})}
+	h.mu.Lock()
+	h.clients[conn] = c
+	h.mu.Unlock()
+
+	if h.welcome != nil {
+		h.write(conn, wsOpText, h.welcome())
+	}
+
+	go h.writeLoop(c)
+	go h.read(conn, rw.Reader)
+}
+
+// writeLoop writes a client's queued frames until it is dropped. A close
+// frame is the last one written.
+func (h *wsHub) writeLoop(c *wsClient) {
+	defer h.drop(c.conn)
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case frame := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if _, err := c.conn.Write(frame); err != nil || frame[0]&0x0F == wsOpClose {
+				return
+			}
+		}
+	}
+}
+
+// read consumes client messages until the client closes, answering pings.
+// A client that breaks the protocol is closed with the reason.
+func (h *wsHub) read(conn net.Conn, r *bufio.Reader) {
+
+	messages := &wsMessageReader{r: r}
+	for {
+		op, payload, err := messages.Next()
+
+		var closeErr *wsCloseError
+		if errors.As(err, &closeErr) {
+			log.Debug().Err(err).Msgf("Closing WebSocket client %s", conn.RemoteAddr())
+			h.write(conn, wsOpClose, wsClosePayload(closeErr.code, closeErr.reason))
+
+			return
+		}
+		if err != nil {
+			h.drop(conn)
+
+			return
+		}
+
+		switch op {
+		case wsOpClose:
+			h.write(conn, wsOpClose, nil)
+
+			return
+		case wsOpPing:
+			h.write(conn, wsOpPong, payload)
+		}
+	}
+}
+
+func (h *wsHub) drop(conn net.Conn) {
+	h.mu.Lock()
+	c, ok := h.clients[conn]
+	delete(h.clients, conn)
+	h.mu.Unlock()
+
+	if ok {
+		close(c.done)
+	}
+	_ = conn.Close()
+}
+
+// write queues a frame for a client without waiting for it to be sent. A
+// client whose queue is full has stopped reading, so it is dropped.
+func (h *wsHub) write(conn net.Conn, op byte, payload []byte) {
+	h.mu.Lock()
+	c, ok := h.clients[conn]
+	h.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	select {
+	case c.send <- wsFrame(op, payload):
+	default:
+		log.Debug().Msgf("Dropping WebSocket client %s, it fell behind", conn.RemoteAddr())
+		h.drop(conn)
+	}
+}
+
+// Broadcast queues message for every client; it never waits on a client.
+func (h *wsHub) Broadcast(message []byte) {
+	frame := wsFrame(wsOpText, message)
+
+	h.mu.Lock()
+	var behind []net.Conn
+	for conn, c := range h.clients {
+		select {
+		case c.send <- frame:
+		default:
+			behind = append(behind, conn)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, conn := range behind {
+		log.Debug().Msgf("Dropping WebSocket client %s, it fell behind", conn.RemoteAddr())
+		h.drop(conn)
+	}
+}
+
+// Clients reports how many clients are connected.
+func (h *wsHub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.clients)
+}
+
+// Close sends every client a close frame and disconnects it.
+func (h *wsHub) Close() {
+	h.mu.Lock()
+	conns := make([]net.Conn, 0, len(h.clients))
+	for c := range h.clients {
+		conns = append(conns, c)
+	}
+	h.mu.Unlock()
+
+	for _, conn := range conns {
+		h.write(conn, wsOpClose, nil)
+	}
+}
+
+// wsFrame encodes one unmasked server frame.
+func wsFrame(op byte, payload []byte) []byte {
+	header := []byte{0x80 | op}
+
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	return append(header, payload...)
+}
+
+// wsClosePayload is the body of a close frame: the code, then the reason.
+func wsClosePayload(code uint16, reason string) []byte {
+	payload := binary.BigEndian.AppendUint16(nil, code)
+	if len(reason) > 123 { //nolint:gomnd // control frames carry at most 125 bytes
+		reason = reason[:123]
+	}
+
+	return append(payload, reason...)
+}
+
+// wsFrameRead is one decoded frame.
+type wsFrameRead struct {
+	fin     bool
+	masked  bool
+	op      byte
+	payload []byte
+}
+
+// readWSRawFrame decodes one frame, unmasking client payloads. It rejects
+// frames no peer of ours may send: reserved bits or opcodes, fragmented or
+// long control frames, and anything over wsMaxMessage.
+func readWSRawFrame(r io.Reader) (wsFrameRead, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return wsFrameRead{}, err
+	}
+
+	f := wsFrameRead{fin: head[0]&0x80 != 0, masked: head[1]&0x80 != 0, op: head[0] & 0x0F}
+	length := uint64(head[1] & 0x7F)
+
+	// no extensions are negotiated, so the RSV bits must be clear
+	if head[0]&0x70 != 0 {
+		return wsFrameRead{}, wsProtocolError("reserved bits set")
+	}
+
+	switch f.op {
+	case wsOpContinuation, wsOpText, wsOpBinary:
+	case wsOpClose, wsOpPing, wsOpPong:
+		if !f.fin || length > 125 {
+			return wsFrameRead{}, wsProtocolError("control frames cannot be fragmented or longer than 125 bytes")
+		}
+	default:
+		return wsFrameRead{}, wsProtocolError("reserved opcode %x", f.op)
+	}
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return wsFrameRead{}, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return wsFrameRead{}, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if length > wsMaxMessage {
+		return wsFrameRead{}, &wsCloseError{code: wsCloseTooBig, reason: fmt.Sprintf("frame of %d bytes is too large", length)}
+	}
+
+	var mask [4]byte
+	if f.masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return wsFrameRead{}, err
+		}
+	}
+
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return wsFrameRead{}, err
+	}
+
+	if f.masked {
+		for i := range f.payload {
+			f.payload[i] ^= mask[i%4]
+		}
+	}
+
+	return f, nil
+}
+
+// readWSFrame decodes one frame and returns its opcode and payload.
+func readWSFrame(r io.Reader) (byte, []byte, error) {
+	f, err := readWSRawFrame(r)
+
+	return f.op, f.payload, err
+}
+
+// wsMessageReader reads a client's messages. Client frames must be masked,
+// fragments are joined, and control frames between fragments are returned
+// as they arrive.
+type wsMessageReader struct {
+	r       io.Reader
+	op      byte // of the fragmented message being joined, or 0
+	message []byte
+}
+
+// Next returns the next control frame or whole message.
+func (m *wsMessageReader) Next() (byte, []byte, error) {
+	for {
+		f, err := readWSRawFrame(m.r)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if !f.masked {
+			return 0, nil, wsProtocolError("client frames must be masked")
+		}
+
+		if f.op >= wsOpClose {
+			return f.op, f.payload, nil
+		}
+
+		switch {
+		case f.op == wsOpContinuation && m.op == 0:
+			return 0, nil, wsProtocolError("continuation frame without a message")
+		case f.op != wsOpContinuation && m.op != 0:
+			return 0, nil, wsProtocolError("new message before the last one finished")
+		case f.op != wsOpContinuation:
+			m.op, m.message = f.op, nil
+		}
+
+		if len(m.message)+len(f.payload) > wsMaxMessage {
+			return 0, nil, &wsCloseError{code: wsCloseTooBig, reason: "message is too large"}
+		}
+		m.message = append(m.message, f.payload...)
+
+		if f.fin {
+			op, message := m.op, m.message
+			m.op, m.message = 0, nil
+
+			return op, message, nil
+		}
+	}
+}
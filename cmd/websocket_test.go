@@ -0,0 +1,146 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clientFrame encodes a frame as a client sends it, masked unless mask is
+// false.
+func clientFrame(fin, mask bool, op byte, payload []byte) []byte {
+	frame := wsFrame(op, payload)
+	if !fin {
+		frame[0] &^= 0x80
+	}
+	if !mask {
+		return frame
+	}
+
+	header, body := frame[:len(frame)-len(payload)], append([]byte(nil), payload...)
+	key := [4]byte{7, 1, 9, 3}
+	for i := range body {
+		body[i] ^= key[i%4]
+	}
+	header[1] |= 0x80
+
+	return append(append(append([]byte(nil), header...), key[:]...), body...)
+}
+
+func TestWSMessageReader(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(clientFrame(false, true, wsOpText, []byte("hel")))
+	stream.Write(clientFrame(true, true, wsOpPing, []byte("p")))
+	stream.Write(clientFrame(false, true, wsOpContinuation, []byte("lo ")))
+	stream.Write(clientFrame(true, true, wsOpContinuation, []byte("there")))
+
+	messages := &wsMessageReader{r: &stream}
+	if op, payload, err := messages.Next(); err != nil || op != wsOpPing || string(payload) != "p" {
+		t.Errorf("a ping between fragments = %x %q %v", op, payload, err)
+	}
+	if op, payload, err := messages.Next(); err != nil || op != wsOpText || string(payload) != "hello there" {
+		t.Errorf("fragments joined as %x %q %v", op, payload, err)
+	}
+
+	var long [8]byte
+	binary.BigEndian.PutUint64(long[:], wsMaxMessage+1)
+	oversized := append([]byte{0x80 | wsOpText, 0x80 | 127}, long[:]...)
+
+	cases := []struct {
+		name     string
+		frames   [][]byte
+		wantCode uint16
+		wantErr  string
+	}{
+		{"unmasked", [][]byte{clientFrame(true, false, wsOpText, []byte("hi"))}, wsCloseProtocolError, "must be masked"},
+		{"reserved bits", [][]byte{func() []byte { f := clientFrame(true, true, wsOpText, nil); f[0] |= 0x40; return f }()}, wsCloseProtocolError, "reserved bits"},
+		{"reserved opcode", [][]byte{clientFrame(true, true, 0x3, nil)}, wsCloseProtocolError, "reserved opcode"},
+		{"fragmented ping", [][]byte{clientFrame(false, true, wsOpPing, nil)}, wsCloseProtocolError, "cannot be fragmented"},
+		{"stray continuation", [][]byte{clientFrame(true, true, wsOpContinuation, []byte("x"))}, wsCloseProtocolError, "without a message"},
+		{"interleaved messages", [][]byte{clientFrame(false, true, wsOpText, []byte("a")), clientFrame(true, true, wsOpText, []byte("b"))}, wsCloseProtocolError, "before the last one finished"},
+		{"oversized frame", [][]byte{oversized}, wsCloseTooBig, "too large"},
+		{"oversized message", [][]byte{
+			clientFrame(false, true, wsOpText, make([]byte, wsMaxMessage)),
+			clientFrame(true, true, wsOpContinuation, []byte("x")),
+		}, wsCloseTooBig, "too large"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			messages := &wsMessageReader{r: bytes.NewReader(bytes.Join(tc.frames, nil))}
+			_, _, err := messages.Next()
+
+			var closeErr *wsCloseError
+			if !errors.As(err, &closeErr) || closeErr.code != tc.wantCode || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("err = %v, want close %d %q", err, tc.wantCode, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestWSHub_ClosesProtocolErrors(t *testing.T) {
+	hub := newWSHub()
+	server := httptest.NewServer(hub)
+	t.Cleanup(server.Close)
+
+	conn, r := dialWS(t, server.Listener.Addr().String(), "/")
+	if _, err := conn.Write(clientFrame(true, false, wsOpText, []byte("hi"))); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	op, payload, err := readWSFrame(r)
+	if err != nil || op != wsOpClose || len(payload) < 2 || binary.BigEndian.Uint16(payload) != wsCloseProtocolError {
+		t.Fatalf("an unmasked frame was answered with %x %q %v", op, payload, err)
+	}
+	if _, _, err := readWSFrame(r); err == nil {
+		t.Error("the connection should be closed after a protocol error")
+	}
+}
+
+func TestWSHub_SlowClientDoesNotBlock(t *testing.T) {
+	hub := newWSHub()
+	server := httptest.NewServer(hub)
+	t.Cleanup(server.Close)
+
+	// connect, but never read
+	dialWS(t, server.Listener.Addr().String(), "/")
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.Clients() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	message := bytes.Repeat([]byte{'x'}, 64<<10)
+	start := time.Now()
+	for i := 0; i < 4*wsSendQueue; i++ {
+		hub.Broadcast(message)
+	}
+	if took := time.Since(start); took > wsWriteTimeout/2 {
+		t.Errorf("broadcasting to a stalled client took %s", took)
+	}
+
+	deadline = time.Now().Add(2 * wsWriteTimeout)
+	for hub.Clients() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := hub.Clients(); n != 0 {
+		t.Errorf("a client that fell behind should be dropped, %d connected", n)
+	}
+}
@@ -1,57 +1,30 @@
 import Head from 'next/head'
+import { useEffect, useState } from 'react'
 
+// Buttons come from GET /api/buttons, laid out by the companion_layout config
+// key, so adding a button does not need a rebuild.
 export default function Home() {
-  const sendRequest = async (api) => {
-    const req = await fetch(api, { method: 'POST' });
-    const data = await req.json();
-
-    return { data: data.results };
-  };
-  const sendOn = (event) => {
-    event.preventDefault();
-
-    sendRequest("/api/lighting/keylighton");
-  };
-  const sendGreen = (event) => {
-    event.preventDefault();
-
-    sendRequest("/api/light/green");
-  };
-  const sendBlue = (event) => {
-    event.preventDefault();
-
-    sendRequest("/api/light/blue");
-  };
-  const sendRed = (event) => {
-    event.preventDefault();
-
-    sendRequest("/api/light/red");
-  };
-  const sendYellow = (event) => {
+  const [groups, setGroups] = useState([]);
+  const [error, setError] = useState(null);
+
+  useEffect(() => {
+    fetch("/api/buttons")
+      .then((req) => req.json())
+      .then((data) => {
+        if (data.error) {
+          throw new Error(data.error);
+        }
+        setGroups(data.groups);
+      })
+      .catch((err) => setError(err.message));
+  }, []);
+
+  const sendRequest = (action) => (event) => {
     event.preventDefault();
 
-    sendRequest("/api/light/yellow");
+    fetch(action, { method: 'POST' });
   };
-  const sendBackgroundOff = (event) => {
-    event.preventDefault();
 
-    sendRequest("/api/light/off");
-  };
-  const sendBRB = (event) => {
-    event.preventDefault();
-
-    sendRequest("/api/switcher/brb");
-  };
-  const sendFTB = (event) => {
-    event.preventDefault();
-
-    sendRequest("/api/switcher/ftb");
-  };
-  const sendDSK = (event) => {
-    event.preventDefault();
-
-    sendRequest("/api/switcher/dsk");
-  };
   return (
     <div className="container">
       <Head>
@@ -64,37 +37,21 @@ export default function Home() {
           <a href="https://github.com/kindlyops/vbs">VBS</a> Lighting bridge
         </h1>
 
-        <div className="grid">
-          <a href="#on" className="card" onClick={sendGreen} style={{ backgroundColor: "hsl(130, 100%, 50%)" }}>
-            <h3>On &#128161;</h3>
-            <p>Green light.</p>
-          </a>
-          <a href="#on" className="card" onClick={sendBlue} style={{ backgroundColor: "hsl(210, 100%, 50%)" }}>
-            <h3>On &#128161;</h3>
-            <p>Blue light.</p>
-          </a>
-          <a href="#on" className="card" onClick={sendYellow} style={{ backgroundColor: "hsl(60, 100%, 50%)" }}>
-            <h3>On &#128161;</h3>
-            <p>Yellow light.</p>
-          </a>
-          <a href="#on" className="card" onClick={sendRed} style={{ backgroundColor: "hsl(10, 100%, 50%)" }}>
-            <h3>On &#128161;</h3>
-            <p>Red light.</p>
-          </a>
-
-          <a href="#off" className="card" onClick={sendBackgroundOff}>
-            <h3>Off &#128268;</h3>
-            <p>light off.</p>
-          </a>
-          <a href="#ftb" className="card" onClick={sendFTB}>
-            <h3>FTB &#10084;</h3>
-            <p>fade to black</p>
-          </a>
-          <a href="#off" className="card" onClick={sendDSK}>
-            <h3>DSK &#129668;</h3>
-            <p>toggle DSK.</p>
-          </a>
-        </div>
+        {error && <p className="description">Could not load buttons: {error}</p>}
+        {groups.map((group) => (
+          <section key={group.name}>
+            <h2>{group.name}</h2>
+            <div className="grid">
+              {group.buttons.map((button) => (
+                <a key={button.button} href={"#" + button.button} className="card" onClick={sendRequest(button.action)}
+                  style={button.color ? { backgroundColor: button.color } : undefined}>
+                  <h3>{button.label}</h3>
+                  {button.description && <p>{button.description}</p>}
+                </a>
+              ))}
+            </div>
+          </section>
+        ))}
         <p className="description">
           Send <code>OSC</code> commands to Companion.app
         </p>
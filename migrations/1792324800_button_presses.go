@@ -0,0 +1,76 @@
+// Copyright © 2026 Kindly Ops, LLC <support@kindlyops.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migrations holds the PocketBase migrations for vbs serve. They are
+// registered by importing the package and run when the server starts.
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// ButtonPresses is the collection recording every lighting and switcher
+// button press. It has no API rules, so only admins and vbs itself can read
+// or change it.
+const ButtonPresses = "button_presses"
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		text := func(name string, required bool) *schema.SchemaField {
+			return &schema.SchemaField{Name: name, Type: schema.FieldTypeText, Required: required, Options: &schema.TextOptions{}}
+		}
+
+		collection := &models.Collection{
+			Name: ButtonPresses,
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				text("button", true),
+				text("user", false),
+				text("device", false),
+				text("address", false),
+				text("route", false),
+				text("osc", false),
+				&schema.SchemaField{
+					Name:     "result",
+					Type:     schema.FieldTypeSelect,
+					Required: true,
+					Options: &schema.SelectOptions{
+						MaxSelect: 1,
+						Values:    []string{"sent", "failed", "unknown", "invalid"},
+					},
+				},
+				text("error", false),
+			),
+			Indexes: types.JsonArray[string]{
+				"CREATE INDEX `idx_button_presses_created` ON `button_presses` (`created`)",
+			},
+		}
+
+		return daos.New(db).SaveCollection(collection)
+	}, func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		collection, err := dao.FindCollectionByNameOrId(ButtonPresses)
+		if err != nil {
+			return err
+		}
+
+		return dao.DeleteCollection(collection)
+	})
+}